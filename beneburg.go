@@ -2,23 +2,31 @@ package main
 
 import (
 	"beneburg/pkg/database"
+	"beneburg/pkg/database/model"
 	"beneburg/pkg/middleware"
 	"beneburg/pkg/telegram"
 	"beneburg/pkg/views"
 	"context"
+	"crypto/sha256"
+	"errors"
+	"expvar"
 	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 )
 
+const shutdownTimeout = 15 * time.Second
+
 func main() {
 	logger, _ := zap.NewDevelopment()
 	defer func(logger *zap.Logger) {
@@ -36,6 +44,9 @@ func run(logger *zap.Logger) error {
 	defer cancel()
 
 	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
 	logger.Info("config loaded", zap.Any("config", config))
 
 	// Creating database connection
@@ -58,12 +69,14 @@ func run(logger *zap.Logger) error {
 
 	// Configuring bot
 	var SendFunc telegram.TelegramBotSendFunc
+	var bot telegram.Bot
+	callbacks := telegram.NewCallbackCodec(config.Telegram.CallbackSecret)
 	if token := config.Telegram.Token; token != "" {
 		botAPI, err := tgbotapi.NewBotAPI(token)
 		if err != nil {
 			return err
 		}
-		bot := telegram.NewBot(ctx, botAPI, db, config.Telegram.AdminID, config.Telegram.GroupID, config.Telegram.InviteLink, config.domain)
+		bot = telegram.NewBot(ctx, botAPI, db, config.Telegram.AdminID, config.Telegram.GroupID, config.domain)
 		SendFunc = bot.GetSendFunc()
 		logger = logger.WithOptions(zap.Hooks(func(entry zapcore.Entry) error {
 			if entry.Level < zapcore.WarnLevel {
@@ -75,6 +88,13 @@ func run(logger *zap.Logger) error {
 		}))
 
 		bot.SetLogger(logger.Named("telegram"))
+		if config.Telegram.UpdatesMode == telegram.UpdatesModeWebhook {
+			bot.SetWebhook(config.domain+telegram.WebhookPath, config.Telegram.WebhookSecret)
+		}
+		bot.SetPollRules(config.Telegram.PollRules)
+		bot.SetReminderRules(config.Telegram.ReminderRules)
+		bot.SetDigestRules(config.Telegram.DigestRules)
+		bot.SetCallbackCodec(callbacks)
 		bot.Start()
 	}
 
@@ -98,6 +118,11 @@ func run(logger *zap.Logger) error {
 	router.LoadHTMLGlob("templates/*")
 	router.Use(cors.Default())
 
+	// Telegram webhook
+	if bot != nil {
+		bot.RegisterWebhook(router)
+	}
+
 	// Configuring API
 	var tokenAuthMiddleware middleware.TokenAuth
 	if config.noAuth {
@@ -109,47 +134,76 @@ func run(logger *zap.Logger) error {
 	// Configuring groups
 	loginGroup := router.Group("/login")
 	profileGroup := router.Group("/profile")
+	adminGroup := router.Group("/admin")
+	banGroup := router.Group("/ban")
 	mainGroup := router.Group("/")
 
 	// TokenAuthMiddleware
 	mainGroup.Use(tokenAuthMiddleware.Auth)
 	profileGroup.Use(tokenAuthMiddleware.Auth)
+	adminGroup.Use(tokenAuthMiddleware.Auth)
+
+	// PermissionMiddleware
+	adminGroup.Use(middleware.RequirePermission(db, logger.Named("PermissionMiddleware"), model.PermissionReviewForms))
 
 	// ProfileRedirectMiddleware
 	mainGroup.Use(middleware.ProfileRedirectMiddleware())
 
 	// Views
-	viewsModule := views.NewViews(db, logger.Named("views"), SendFunc, config.Telegram.AdminID, config.Telegram.GroupID, config.domain)
+	viewsModule := views.NewViews(db, logger.Named("views"), SendFunc, callbacks, config.Telegram.GroupID, config.domain)
 	viewsModule.RegisterRoutes(mainGroup)
 	viewsModule.RegisterLogin(loginGroup)
 	viewsModule.RegisterProfile(profileGroup)
+	viewsModule.RegisterAdmin(adminGroup)
+	viewsModule.RegisterBan(banGroup)
+
+	// Metrics
+	adminGroup.GET("/vars", gin.WrapH(expvar.Handler()))
 
 	// Starting server
+	server := &http.Server{
+		Addr:    config.address,
+		Handler: router,
+	}
+	serverErrors := make(chan error, 1)
 	go func() {
-		err := router.Run()
-		if err != nil {
-			logger.Error("ListenAndServe", zap.Error(err))
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErrors <- err
 		}
 	}()
 
-	logger.Info("Server started")
+	logger.Info("Server started", zap.String("address", config.address))
 	logger.Info("All ready")
 
 	// Waiting for signal
 	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs, os.Interrupt)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	var serverErr error
 	select {
 	case sig := <-sigs:
 		logger.Info("Received signal", zap.String("signal", sig.String()))
-		cancel()
-		select {
-		// TODO: wait for all goroutines to finish
-		case <-time.After(time.Second * 4):
-			return fmt.Errorf("timeout while waiting for context to be done")
-		}
+	case serverErr = <-serverErrors:
+		logger.Error("ListenAndServe", zap.Error(serverErr))
 	case <-ctx.Done():
 		return ctx.Err()
 	}
+
+	// Stopping the server first, so no new updates come from the webhook
+	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, shutdownTimeout)
+	defer shutdownCancel()
+	err = server.Shutdown(shutdownCtx)
+	if err != nil {
+		return fmt.Errorf("server shutdown: %w", err)
+	}
+	logger.Info("Server stopped")
+	if bot != nil {
+		err = bot.Stop(shutdownCtx)
+		if err != nil {
+			return fmt.Errorf("bot stop: %w", err)
+		}
+	}
+	return serverErr
 }
 
 type Config struct {
@@ -164,14 +218,21 @@ type Config struct {
 		OnlyMakeMigrations bool
 	}
 	Telegram struct {
-		Token      string
-		AdminID    int64
-		GroupID    int64
-		InviteLink string
+		Token         string
+		AdminID       int64
+		GroupID       int64
+		UpdatesMode   string
+		WebhookSecret string
+		PollRules     telegram.PollRules
+		ReminderRules telegram.ReminderRules
+		DigestRules   telegram.DigestRules
+		// CallbackSecret signs the admin buttons of the bot.
+		CallbackSecret []byte
 	}
 	trustedProxy string
 	noAuth       bool
 	domain       string
+	address      string
 }
 
 func loadConfig() (*Config, error) {
@@ -195,6 +256,10 @@ func loadConfig() (*Config, error) {
 	noAuth := os.Getenv("NO_AUTH") == "true"
 	trustedProxy := os.Getenv("TRUSTED_PROXY")
 	domain := os.Getenv("DOMAIN")
+	address := ":8080"
+	if port := os.Getenv("PORT"); port != "" {
+		address = ":" + port
+	}
 
 	adminID, err := strconv.ParseInt(os.Getenv("ADMIN_ID"), 10, 64)
 	if err != nil {
@@ -204,7 +269,26 @@ func loadConfig() (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
-	inviteLink := os.Getenv("INVITE_LINK")
+	updatesMode := os.Getenv("BOT_UPDATES_MODE")
+	webhookSecret := os.Getenv("WEBHOOK_SECRET")
+	pollRules, err := loadPollRules()
+	if err != nil {
+		return nil, err
+	}
+	reminderRules, err := loadReminderRules()
+	if err != nil {
+		return nil, err
+	}
+	digestRules, err := loadDigestRules()
+	if err != nil {
+		return nil, err
+	}
+	callbackSecret := []byte(os.Getenv("CALLBACK_SECRET"))
+	if len(callbackSecret) == 0 {
+		// Buttons must stay valid across restarts, so the default is derived from the token
+		sum := sha256.Sum256([]byte("callback:" + botToken))
+		callbackSecret = sum[:]
+	}
 
 	if dbHost == "" {
 		dbHost = "localhost"
@@ -212,6 +296,17 @@ func loadConfig() (*Config, error) {
 	if dbPort == "" {
 		dbPort = "3306"
 	}
+	switch updatesMode {
+	case "":
+		updatesMode = telegram.UpdatesModePolling
+	case telegram.UpdatesModePolling:
+	case telegram.UpdatesModeWebhook:
+		if webhookSecret == "" {
+			return nil, fmt.Errorf("WEBHOOK_SECRET is required in %s mode", telegram.UpdatesModeWebhook)
+		}
+	default:
+		return nil, fmt.Errorf("unknown BOT_UPDATES_MODE %q", updatesMode)
+	}
 
 	dataSourceName := dbUser + ":" + dbPassword + "@tcp(" + dbHost + ":" + dbPort + ")/" + dbName + "?parseTime=true" + "&" + "multiStatements=true"
 	return &Config{
@@ -233,18 +328,123 @@ func loadConfig() (*Config, error) {
 			OnlyMakeMigrations: onlyMakeMigrations,
 		},
 		Telegram: struct {
-			Token      string
-			AdminID    int64
-			GroupID    int64
-			InviteLink string
+			Token          string
+			AdminID        int64
+			GroupID        int64
+			UpdatesMode    string
+			WebhookSecret  string
+			PollRules      telegram.PollRules
+			ReminderRules  telegram.ReminderRules
+			DigestRules    telegram.DigestRules
+			CallbackSecret []byte
 		}{
-			Token:      botToken,
-			AdminID:    adminID,
-			GroupID:    groupID,
-			InviteLink: inviteLink,
+			Token:          botToken,
+			AdminID:        adminID,
+			GroupID:        groupID,
+			UpdatesMode:    updatesMode,
+			WebhookSecret:  webhookSecret,
+			PollRules:      pollRules,
+			ReminderRules:  reminderRules,
+			DigestRules:    digestRules,
+			CallbackSecret: callbackSecret,
 		},
 		trustedProxy: trustedProxy,
 		noAuth:       noAuth,
 		domain:       domain,
+		address:      address,
 	}, nil
 }
+
+// loadPollRules reads the membership poll rules, unset variables keep the defaults.
+func loadPollRules() (telegram.PollRules, error) {
+	rules := telegram.DefaultPollRules
+	if duration := os.Getenv("POLL_DURATION"); duration != "" {
+		parsed, err := time.ParseDuration(duration)
+		if err != nil {
+			return rules, fmt.Errorf("POLL_DURATION: %w", err)
+		}
+		rules.Duration = parsed
+	}
+	if quorum := os.Getenv("POLL_QUORUM"); quorum != "" {
+		parsed, err := strconv.Atoi(quorum)
+		if err != nil {
+			return rules, fmt.Errorf("POLL_QUORUM: %w", err)
+		}
+		rules.Quorum = parsed
+	}
+	if threshold := os.Getenv("POLL_APPROVAL_THRESHOLD"); threshold != "" {
+		parsed, err := strconv.ParseFloat(threshold, 64)
+		if err != nil {
+			return rules, fmt.Errorf("POLL_APPROVAL_THRESHOLD: %w", err)
+		}
+		if parsed <= 0 || parsed > 1 {
+			return rules, fmt.Errorf("POLL_APPROVAL_THRESHOLD must be in (0, 1], got %v", parsed)
+		}
+		rules.ApprovalThreshold = parsed
+	}
+	return rules, nil
+}
+
+// loadReminderRules reads the reminder rules, unset variables keep the defaults.
+func loadReminderRules() (telegram.ReminderRules, error) {
+	rules := telegram.DefaultReminderRules
+	durations := []struct {
+		name  string
+		value *time.Duration
+	}{
+		{name: "REVIEW_REMINDER_AFTER", value: &rules.ReviewAfter},
+		{name: "REVIEW_DIGEST_INTERVAL", value: &rules.ReviewDigestInterval},
+		{name: "APPLY_REMINDER_AFTER", value: &rules.ApplyAfter},
+	}
+	for _, duration := range durations {
+		value := os.Getenv(duration.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return rules, fmt.Errorf("%s: %w", duration.name, err)
+		}
+		if parsed <= 0 {
+			return rules, fmt.Errorf("%s must be positive, got %v", duration.name, parsed)
+		}
+		*duration.value = parsed
+	}
+	if maxReminders := os.Getenv("APPLY_REMINDER_MAX"); maxReminders != "" {
+		parsed, err := strconv.Atoi(maxReminders)
+		if err != nil {
+			return rules, fmt.Errorf("APPLY_REMINDER_MAX: %w", err)
+		}
+		if parsed < 0 {
+			return rules, fmt.Errorf("APPLY_REMINDER_MAX must not be negative, got %d", parsed)
+		}
+		rules.ApplyMaxReminders = parsed
+	}
+	return rules, nil
+}
+
+// loadDigestRules reads the community digest rules, unset variables keep the defaults.
+func loadDigestRules() (telegram.DigestRules, error) {
+	rules := telegram.DefaultDigestRules
+	if interval := os.Getenv("DIGEST_INTERVAL"); interval != "" {
+		parsed, err := time.ParseDuration(interval)
+		if err != nil {
+			return rules, fmt.Errorf("DIGEST_INTERVAL: %w", err)
+		}
+		if parsed < 0 {
+			return rules, fmt.Errorf("DIGEST_INTERVAL must not be negative, got %v", parsed)
+		}
+		rules.Interval = parsed
+	}
+	if hour := os.Getenv("DIGEST_HOUR"); hour != "" {
+		parsed, err := strconv.Atoi(hour)
+		if err != nil {
+			return rules, fmt.Errorf("DIGEST_HOUR: %w", err)
+		}
+		if parsed < 0 || parsed > 23 {
+			return rules, fmt.Errorf("DIGEST_HOUR must be in [0, 23], got %d", parsed)
+		}
+		rules.Hour = parsed
+	}
+	return rules, nil
+}
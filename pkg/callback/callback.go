@@ -0,0 +1,289 @@
+// Package callback encodes the data of inline buttons. Every payload names a registered
+// action with typed arguments and carries the format version, so buttons sent before
+// a deploy keep working. Payloads of admin actions are signed with HMAC.
+package callback
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is the current payload format: "<version>:<action>[:<arg>...][:<signature>]".
+const Version = "1"
+
+// MaxLength is Telegram's limit of callback data in bytes.
+const MaxLength = 64
+
+const (
+	separator = ":"
+	// signatureLength is the length of the truncated HMAC in bytes.
+	signatureLength = 8
+)
+
+var (
+	ErrTooLong            = errors.New("callback data is too long")
+	ErrMalformed          = errors.New("callback data is malformed")
+	ErrUnknownAction      = errors.New("unknown callback action")
+	ErrUnsupportedVersion = errors.New("unsupported callback data version")
+	ErrBadSignature       = errors.New("callback data signature is invalid")
+)
+
+type Kind int
+
+const (
+	Uint Kind = iota
+	Int64
+	Int
+	String
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Uint:
+		return "uint"
+	case Int64:
+		return "int64"
+	case Int:
+		return "int"
+	case String:
+		return "string"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Action is a kind of button. Name is sent with every button, so it should be short
+// and must not change once buttons with it are sent.
+type Action struct {
+	Name string
+	Args []Kind
+	// Admin actions are signed, so data made up by a modified client is rejected.
+	Admin bool
+}
+
+// Payload is decoded callback data. The arguments are checked against the action's
+// kinds while decoding, so the getters don't fail.
+type Payload struct {
+	Action *Action
+	// Legacy is true for data sent before the versioned format, it's never signed.
+	Legacy bool
+	args   []string
+}
+
+func (p *Payload) Uint(i int) uint {
+	value, _ := strconv.ParseUint(p.args[i], 10, 64)
+	return uint(value)
+}
+
+func (p *Payload) Int64(i int) int64 {
+	value, _ := strconv.ParseInt(p.args[i], 10, 64)
+	return value
+}
+
+func (p *Payload) Int(i int) int {
+	value, _ := strconv.Atoi(p.args[i])
+	return value
+}
+
+func (p *Payload) String(i int) string {
+	return p.args[i]
+}
+
+type legacyFormat struct {
+	prefix string
+	action *Action
+}
+
+// Codec encodes and decodes the payloads of the registered actions.
+type Codec struct {
+	secret  []byte
+	actions map[string]*Action
+	legacy  []legacyFormat
+}
+
+func NewCodec(secret []byte) *Codec {
+	return &Codec{
+		secret:  secret,
+		actions: map[string]*Action{},
+	}
+}
+
+func (c *Codec) Register(actions ...*Action) {
+	for _, action := range actions {
+		if action.Name == "" || strings.Contains(action.Name, separator) {
+			panic(fmt.Sprintf("invalid callback action name %q", action.Name))
+		}
+		if _, ok := c.actions[action.Name]; ok {
+			panic(fmt.Sprintf("callback action %q is already registered", action.Name))
+		}
+		c.actions[action.Name] = action
+	}
+}
+
+// RegisterLegacy decodes the data starting with prefix, which was sent before the versioned
+// format, as the action. The rest of the data are the arguments separated by ":".
+func (c *Codec) RegisterLegacy(prefix string, action *Action) {
+	c.legacy = append(c.legacy, legacyFormat{prefix: prefix, action: action})
+}
+
+func (c *Codec) Encode(action *Action, args ...any) (string, error) {
+	if c.actions[action.Name] != action {
+		return "", fmt.Errorf("%w %q", ErrUnknownAction, action.Name)
+	}
+	if len(args) != len(action.Args) {
+		return "", fmt.Errorf("callback action %q takes %d arguments, got %d", action.Name, len(action.Args), len(args))
+	}
+	fields := []string{Version, action.Name}
+	for i, arg := range args {
+		field, err := encodeArg(action.Args[i], arg)
+		if err != nil {
+			return "", fmt.Errorf("callback action %q argument %d: %w", action.Name, i, err)
+		}
+		fields = append(fields, field)
+	}
+	data := strings.Join(fields, separator)
+	if action.Admin {
+		data += separator + c.sign(data)
+	}
+	if len(data) > MaxLength {
+		return "", fmt.Errorf("%w: %q is %d bytes", ErrTooLong, data, len(data))
+	}
+	return data, nil
+}
+
+// MustEncode is Encode for buttons whose arguments can't make the data invalid.
+func (c *Codec) MustEncode(action *Action, args ...any) string {
+	data, err := c.Encode(action, args...)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func (c *Codec) Decode(data string) (*Payload, error) {
+	if len(data) > MaxLength {
+		return nil, ErrTooLong
+	}
+	version, rest, ok := strings.Cut(data, separator)
+	if !ok {
+		return nil, ErrMalformed
+	}
+	if _, err := strconv.Atoi(version); err != nil {
+		return c.decodeLegacy(data)
+	}
+	switch version {
+	case Version:
+		return c.decodeV1(data, rest)
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedVersion, version)
+	}
+}
+
+func (c *Codec) decodeV1(data string, rest string) (*Payload, error) {
+	fields := strings.Split(rest, separator)
+	action, ok := c.actions[fields[0]]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownAction, fields[0])
+	}
+	args := fields[1:]
+	if action.Admin {
+		if len(args) == 0 {
+			return nil, ErrBadSignature
+		}
+		signature := args[len(args)-1]
+		signed := strings.TrimSuffix(data, separator+signature)
+		if !hmac.Equal([]byte(signature), []byte(c.sign(signed))) {
+			return nil, ErrBadSignature
+		}
+		args = args[:len(args)-1]
+	}
+	return newPayload(action, args, false)
+}
+
+func (c *Codec) decodeLegacy(data string) (*Payload, error) {
+	for _, legacy := range c.legacy {
+		if strings.HasPrefix(data, legacy.prefix) {
+			rest := strings.TrimPrefix(data, legacy.prefix)
+			var args []string
+			if rest != "" {
+				args = strings.Split(rest, separator)
+			}
+			return newPayload(legacy.action, args, true)
+		}
+	}
+	return nil, ErrMalformed
+}
+
+func newPayload(action *Action, args []string, legacy bool) (*Payload, error) {
+	if len(args) != len(action.Args) {
+		return nil, fmt.Errorf("%w: callback action %q takes %d arguments, got %d", ErrMalformed, action.Name, len(action.Args), len(args))
+	}
+	for i, arg := range args {
+		err := checkArg(action.Args[i], arg)
+		if err != nil {
+			return nil, fmt.Errorf("%w: callback action %q argument %d: %v", ErrMalformed, action.Name, i, err)
+		}
+	}
+	return &Payload{Action: action, Legacy: legacy, args: args}, nil
+}
+
+func (c *Codec) sign(data string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:signatureLength])
+}
+
+func encodeArg(kind Kind, arg any) (string, error) {
+	var field string
+	switch value := arg.(type) {
+	case uint:
+		field = strconv.FormatUint(uint64(value), 10)
+	case int64:
+		field = strconv.FormatInt(value, 10)
+	case int:
+		field = strconv.Itoa(value)
+	case string:
+		field = value
+	default:
+		return "", fmt.Errorf("unsupported type %T", arg)
+	}
+	if argKind := kindOf(arg); argKind != kind {
+		return "", fmt.Errorf("got %s, want %s", argKind, kind)
+	}
+	return field, checkArg(kind, field)
+}
+
+func kindOf(arg any) Kind {
+	switch arg.(type) {
+	case uint:
+		return Uint
+	case int64:
+		return Int64
+	case int:
+		return Int
+	default:
+		return String
+	}
+}
+
+func checkArg(kind Kind, field string) error {
+	var err error
+	switch kind {
+	case Uint:
+		_, err = strconv.ParseUint(field, 10, 64)
+	case Int64:
+		_, err = strconv.ParseInt(field, 10, 64)
+	case Int:
+		_, err = strconv.Atoi(field)
+	case String:
+		if strings.Contains(field, separator) {
+			err = fmt.Errorf("%q contains %q", field, separator)
+		}
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package callback
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+var (
+	testAccept = &Action{Name: "form.accept", Args: []Kind{Uint}, Admin: true}
+	testChoice = &Action{Name: "apply.choice", Args: []Kind{String, String}}
+)
+
+func newTestCodec(secret string) *Codec {
+	codec := NewCodec([]byte(secret))
+	codec.Register(testAccept, testChoice)
+	codec.RegisterLegacy("admin:form:accept:", testAccept)
+	codec.RegisterLegacy("apply:choice:", testChoice)
+	return codec
+}
+
+func TestCodec(t *testing.T) {
+	codec := newTestCodec("secret")
+
+	t.Run("Payload round trip", func(t *testing.T) {
+		data, err := codec.Encode(testAccept, uint(42))
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(data, "1:form.accept:42:"))
+		payload, err := codec.Decode(data)
+		assert.NoError(t, err)
+		assert.Equal(t, testAccept, payload.Action)
+		assert.False(t, payload.Legacy)
+		assert.Equal(t, uint(42), payload.Uint(0))
+
+		data, err = codec.Encode(testChoice, "gender", "f")
+		assert.NoError(t, err)
+		assert.Equal(t, "1:apply.choice:gender:f", data)
+		payload, err = codec.Decode(data)
+		assert.NoError(t, err)
+		assert.Equal(t, "f", payload.String(1))
+	})
+
+	t.Run("Arguments are checked", func(t *testing.T) {
+		_, err := codec.Encode(testAccept, int64(42))
+		assert.Error(t, err)
+		_, err = codec.Encode(testAccept)
+		assert.Error(t, err)
+		_, err = codec.Encode(testChoice, "gender", "a:b")
+		assert.Error(t, err)
+		_, err = codec.Decode("1:apply.choice:gender")
+		assert.ErrorIs(t, err, ErrMalformed)
+	})
+
+	t.Run("Data fits the limit", func(t *testing.T) {
+		_, err := codec.Encode(testChoice, "gender", strings.Repeat("x", MaxLength))
+		assert.ErrorIs(t, err, ErrTooLong)
+		_, err = codec.Decode(strings.Repeat("x", MaxLength+1))
+		assert.ErrorIs(t, err, ErrTooLong)
+	})
+
+	t.Run("Admin payloads are signed", func(t *testing.T) {
+		data := codec.MustEncode(testAccept, uint(42))
+		_, err := codec.Decode(strings.Replace(data, ":42:", ":43:", 1))
+		assert.ErrorIs(t, err, ErrBadSignature)
+		_, err = codec.Decode("1:form.accept:42")
+		assert.ErrorIs(t, err, ErrBadSignature)
+		_, err = newTestCodec("another secret").Decode(data)
+		assert.ErrorIs(t, err, ErrBadSignature)
+	})
+
+	t.Run("Unknown payloads are rejected", func(t *testing.T) {
+		_, err := codec.Decode("1:form.delete:42")
+		assert.ErrorIs(t, err, ErrUnknownAction)
+		_, err = codec.Decode("2:form.accept:42")
+		assert.ErrorIs(t, err, ErrUnsupportedVersion)
+		_, err = codec.Decode("admin:user:accept:42")
+		assert.ErrorIs(t, err, ErrMalformed)
+	})
+
+	t.Run("Legacy payloads are decoded", func(t *testing.T) {
+		payload, err := codec.Decode("admin:form:accept:42")
+		assert.NoError(t, err)
+		assert.Equal(t, testAccept, payload.Action)
+		assert.True(t, payload.Legacy)
+		assert.Equal(t, uint(42), payload.Uint(0))
+
+		payload, err = codec.Decode("apply:choice:gender:f")
+		assert.NoError(t, err)
+		assert.Equal(t, "gender", payload.String(0))
+		assert.Equal(t, "f", payload.String(1))
+	})
+}
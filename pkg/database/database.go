@@ -4,6 +4,7 @@ import (
 	"beneburg/pkg/database/model"
 	"beneburg/pkg/database/query"
 	"context"
+	"errors"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/driver/mysql"
@@ -11,6 +12,7 @@ import (
 	"gorm.io/gen/field"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"strings"
 	"time"
 )
 
@@ -27,23 +29,118 @@ type Database interface {
 	GetAllUsers(ctx context.Context) ([]*model.User, error)
 	GetUserByID(ctx context.Context, id uint) (*model.User, error)
 	GetUserByTelegramID(ctx context.Context, telegramID int64) (*model.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
+	// SearchUsers finds users by a fragment of their name, username or the name in their forms.
+	SearchUsers(ctx context.Context, fragment string, limit int) ([]*model.User, error)
 	UpdateUserByID(ctx context.Context, id uint, user *model.User) (*model.User, error)
+	// AcceptUser and RejectUser don't change a banned user, RowsAffected is 0 then.
 	AcceptUser(ctx context.Context, id uint) (*gen.ResultInfo, error)
 	RejectUser(ctx context.Context, id uint) (*gen.ResultInfo, error)
 	SetUserStatus(ctx context.Context, id uint, status string) (*gen.ResultInfo, error)
+	// ReplaceUserStatus changes the status only if it's still from, RowsAffected is 0 otherwise.
+	ReplaceUserStatus(ctx context.Context, id uint, from string, to string) (*gen.ResultInfo, error)
+	// BanUser sets the banned status, records the ban, deletes the user's tokens and rejects
+	// the user's undecided membership poll. RowsAffected is 0 if the user is already banned.
+	BanUser(ctx context.Context, telegramID int64, bannedBy int64, reason *string) (*gen.ResultInfo, error)
+	// UnbanUser restores the status the user had before the ban and closes the ban record.
+	// RowsAffected is 0 if the user is not banned.
+	UnbanUser(ctx context.Context, telegramID int64, unbannedBy int64) (*gen.ResultInfo, error)
+	GetActiveBan(ctx context.Context, telegramID int64) (*model.Ban, error)
+	SetBirthdayOptOut(ctx context.Context, telegramID int64, optOut bool) (*gen.ResultInfo, error)
+	// GetUsersToRemind returns new users without forms created between createdAfter and
+	// createdBefore, who got fewer than maxReminders reminders and none of them after remindedBefore.
+	GetUsersToRemind(ctx context.Context, createdAfter time.Time, createdBefore time.Time, remindedBefore time.Time, maxReminders int) ([]*model.User, error)
+	// MarkUserReminded counts the reminder only if the user got reminders reminders before,
+	// RowsAffected is 0 otherwise.
+	MarkUserReminded(ctx context.Context, telegramID int64, reminders int32) (*gen.ResultInfo, error)
+	// GetUsersJoinedBetween returns the group members who joined in [from, to), the earliest first.
+	GetUsersJoinedBetween(ctx context.Context, from time.Time, to time.Time) ([]*model.User, error)
 
 	CreateForm(ctx context.Context, form *model.Form) (*model.Form, error)
 	GetFormByID(ctx context.Context, id uint) (*model.Form, error)
-	AcceptForm(ctx context.Context, id uint) (*gen.ResultInfo, error)
-	RejectForm(ctx context.Context, id uint) (*gen.ResultInfo, error)
+	// AcceptForm and RejectForm change only new forms, RowsAffected is 0 if the form is already reviewed.
+	AcceptForm(ctx context.Context, id uint, decidedBy int64) (*gen.ResultInfo, error)
+	RejectForm(ctx context.Context, id uint, reason string, decidedBy int64) (*gen.ResultInfo, error)
 	GetActualForm(ctx context.Context, telegramID int64) (*model.Form, error)
 	GetLastForm(ctx context.Context, telegramID int64) (*model.Form, error)
 	GetAllUserForms(ctx context.Context, telegramID int64) ([]*model.Form, error)
 	GetAllForms(ctx context.Context) ([]*model.Form, error)
 	GetAllAcceptedFormsWithUser(ctx context.Context) ([]*model.Form, error)
+	// SearchAcceptedForms finds the forms of GetAllAcceptedFormsWithUser by a fragment of
+	// the name or hobbies in the form or the user's name.
+	SearchAcceptedForms(ctx context.Context, fragment string, offset int, limit int) ([]*model.Form, error)
+	// GetBirthdayForms returns the forms of GetAllAcceptedFormsWithUser with the birthday on
+	// one of the days of the month, except the users who opted out of greetings.
+	GetBirthdayForms(ctx context.Context, month int, days ...int) ([]*model.Form, error)
+	GetFormsByStatus(ctx context.Context, status string) ([]*model.Form, error)
+	// GetPendingForms returns new forms created before createdBefore, the oldest first.
+	GetPendingForms(ctx context.Context, createdBefore time.Time) ([]*model.Form, error)
+	// GetFormsDecidedBetween returns the forms accepted or rejected in [from, to).
+	GetFormsDecidedBetween(ctx context.Context, from time.Time, to time.Time) ([]*model.Form, error)
+	CountFormsCreatedBetween(ctx context.Context, from time.Time, to time.Time) (int64, error)
+
+	GetFormDraft(ctx context.Context, telegramID int64) (*model.FormDraft, error)
+	SaveFormDraft(ctx context.Context, draft *model.FormDraft) error
+	DeleteFormDraft(ctx context.Context, telegramID int64) error
+
+	// GetUserRole returns model.RoleMember for users without a granted role.
+	GetUserRole(ctx context.Context, telegramID int64) (string, error)
+	// SetUserRole grants the role to the user, model.RoleMember revokes any granted role.
+	SetUserRole(ctx context.Context, telegramID int64, role string, grantedBy int64) error
+	GetRoles(ctx context.Context) ([]*model.Role, error)
+	GetUserIDsWithPermission(ctx context.Context, permission string) ([]int64, error)
+	// EnsureOwner makes the user an owner if there are no owners yet and reports whether it did.
+	EnsureOwner(ctx context.Context, telegramID int64) (bool, error)
+
+	CreateOutboxMessage(ctx context.Context, message *model.OutboxMessage) (*model.OutboxMessage, error)
+	// ClaimDueOutboxMessages returns pending messages whose next attempt is due and postpones
+	// their next attempt to leaseUntil, so they are not claimed again while being sent.
+	ClaimDueOutboxMessages(ctx context.Context, limit int, leaseUntil time.Time) ([]*model.OutboxMessage, error)
+	MarkOutboxMessageSent(ctx context.Context, id uint) error
+	RescheduleOutboxMessage(ctx context.Context, id uint, attempts int, nextAttemptAt time.Time, lastError string) error
+	// DelayOutboxMessage postpones the message without counting an attempt.
+	DelayOutboxMessage(ctx context.Context, id uint, nextAttemptAt time.Time, reason string) error
+	MarkOutboxMessageDead(ctx context.Context, id uint, attempts int, lastError string) error
+	DeleteSentOutboxMessages(ctx context.Context, before time.Time) (*gen.ResultInfo, error)
+
+	GetBotState(ctx context.Context, key string) (*model.BotState, error)
+	SetBotState(ctx context.Context, key string, value string) error
+	DeleteBotState(ctx context.Context, key string) error
+	IsUpdateProcessed(ctx context.Context, updateID int) (bool, error)
+	MarkUpdateProcessed(ctx context.Context, updateID int) error
+	DeleteProcessedUpdates(ctx context.Context, before time.Time) (*gen.ResultInfo, error)
+
+	CreateMembershipPoll(ctx context.Context, poll *model.MembershipPoll) (*model.MembershipPoll, error)
+	GetMembershipPollByPollID(ctx context.Context, pollID string) (*model.MembershipPoll, error)
+	GetMembershipPollByMessage(ctx context.Context, chatID int64, messageID int) (*model.MembershipPoll, error)
+	GetOpenMembershipPolls(ctx context.Context) ([]*model.MembershipPoll, error)
+	GetOpenMembershipPollByUser(ctx context.Context, telegramID int64) (*model.MembershipPoll, error)
+	GetMembershipPollsByUser(ctx context.Context, telegramID int64) ([]*model.MembershipPoll, error)
+	// GetDueMembershipPolls returns open polls created before the deadline.
+	GetDueMembershipPolls(ctx context.Context, createdBefore time.Time) ([]*model.MembershipPoll, error)
+	// EscalateMembershipPoll changes only an open poll, RowsAffected is 0 otherwise.
+	EscalateMembershipPoll(ctx context.Context, id uint) (*gen.ResultInfo, error)
+	UpdateMembershipPollTally(ctx context.Context, pollID string, acceptVotes int, rejectVotes int) error
+	// CloseMembershipPoll closes only an open or escalated poll and in the same transaction accepts
+	// or rejects the user by the decision. RowsAffected is 0 if the poll is already closed or
+	// the user is banned, the decision must not be announced then.
+	CloseMembershipPoll(ctx context.Context, id uint, userID uint, decision string, decidedBy *int64) (*gen.ResultInfo, error)
+	SavePollVote(ctx context.Context, vote *model.PollVote) error
+
+	CreateInviteLink(ctx context.Context, link *model.InviteLink) (*model.InviteLink, error)
+	GetInviteLinkByLink(ctx context.Context, link string) (*model.InviteLink, error)
+	// GetActiveInviteLink returns the user's newest active link which doesn't expire before expireAfter.
+	GetActiveInviteLink(ctx context.Context, telegramID int64, expireAfter time.Time) (*model.InviteLink, error)
+	GetActiveInviteLinksByUser(ctx context.Context, telegramID int64) ([]*model.InviteLink, error)
+	// GetExpiredInviteLinks returns active links which expired before the time.
+	GetExpiredInviteLinks(ctx context.Context, expiredBefore time.Time) ([]*model.InviteLink, error)
+	// MarkInviteLinkUsed and RevokeInviteLink change only an active link, RowsAffected is 0 otherwise.
+	MarkInviteLinkUsed(ctx context.Context, id uint) (*gen.ResultInfo, error)
+	RevokeInviteLink(ctx context.Context, id uint) (*gen.ResultInfo, error)
+	DeletePollVote(ctx context.Context, membershipPollID uint, voterTelegramID int64) error
 }
 
-var Models = []interface{}{model.User{}, model.Token{}, model.Form{}}
+var Models = []interface{}{model.User{}, model.Token{}, model.Form{}, model.Role{}, model.OutboxMessage{}, model.BotState{}, model.ProcessedUpdate{}, model.MembershipPoll{}, model.PollVote{}, model.FormDraft{}, model.Ban{}, model.InviteLink{}}
 
 type database struct {
 	db     *gorm.DB
@@ -84,13 +181,33 @@ func (d database) UpdateOrCreateUser(ctx context.Context, user *model.User) (*mo
 	if user.Username != nil {
 		doUpdates = append(doUpdates, "username")
 	}
+	if user.Status == model.UserStatusActive {
+		if user.JoinedAt == nil {
+			joinedAt := time.Now()
+			user.JoinedAt = &joinedAt
+		}
+		// MySQL assigns the columns in order, so joined_at goes before status to see the old one
+		doUpdates = append(doUpdates, "joined_at")
+	}
 	if user.Status == model.UserStatusActive || user.Status == model.UserStatusNotActive {
 		doUpdates = append(doUpdates, "status")
 	}
-	err := u.WithContext(ctx).Clauses(clause.OnConflict{
+	assignments := clause.AssignmentColumns(doUpdates)
+	for i, assignment := range assignments {
+		switch assignment.Column.Name {
+		// Joining or leaving the group doesn't lift the ban, only UnbanUser does
+		case "status":
+			assignments[i].Value = gorm.Expr("IF(`status` = ?, `status`, VALUES(`status`))", model.UserStatusBanned)
+		// Messages of members keep the time they joined
+		case "joined_at":
+			assignments[i].Value = gorm.Expr("IF(`status` IN (?, ?), `joined_at`, VALUES(`joined_at`))", model.UserStatusActive, model.UserStatusBanned)
+		}
+	}
+	// gen refuses expressions in OnConflict, so the conditional assignments go through gorm
+	err := u.WithContext(ctx).UnderlyingDB().Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "telegram_id"}},
-		DoUpdates: clause.AssignmentColumns(doUpdates),
-	}).Create(user)
+		DoUpdates: assignments,
+	}).Create(user).Error
 	if err != nil {
 		return nil, err
 	}
@@ -107,12 +224,14 @@ func (d database) CreateOrProlongToken(ctx context.Context, telegramID int64) (*
 		UserTelegramId: telegramID,
 		ExpireAt:       time.Now().Add(time.Hour * 24),
 	}
-	err := t.WithContext(ctx).Clauses(
+	// gen creates a slice of one token, gorm orders the columns with default values of a slice
+	// randomly, so the token is created as a struct to keep the statement stable
+	err := t.WithContext(ctx).UnderlyingDB().Clauses(
 		clause.OnConflict{
 			Columns:   []clause.Column{{Name: "user_telegram_id"}},
 			DoUpdates: clause.AssignmentColumns([]string{"uuid", "expire_at"}),
 		},
-	).Create(token)
+	).Create(token).Error
 	if err != nil {
 		return nil, err
 	}
@@ -184,6 +303,42 @@ func (d database) GetUserByTelegramID(ctx context.Context, telegramID int64) (*m
 	return first, nil
 }
 
+func (d database) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
+	u := query.Use(d.db).User
+	first, err := u.WithContext(ctx).Where(u.Username.Eq(username)).First()
+	if err != nil {
+		return nil, err
+	}
+
+	return first, nil
+}
+
+func (d database) SearchUsers(ctx context.Context, fragment string, limit int) ([]*model.User, error) {
+	q := query.Use(d.db)
+	u, f := q.User, q.Form
+	pattern := "%" + likeEscaper.Replace(fragment) + "%"
+	var formUserIDs []int64
+	err := f.WithContext(ctx).Distinct().Where(f.Name.Like(pattern)).Limit(limit).Pluck(f.UserTelegramId, &formUserIDs)
+	if err != nil {
+		return nil, err
+	}
+	users, err := u.WithContext(ctx).
+		Where(u.FirstName.Like(pattern)).
+		Or(u.LastName.Like(pattern)).
+		Or(u.Username.Like(pattern)).
+		Or(u.TelegramID.In(formUserIDs...)).
+		Order(u.UpdatedAt.Desc()).
+		Limit(limit).
+		Find()
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// likeEscaper escapes the wildcards of LIKE in user input.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 func (d database) UpdateUserByID(ctx context.Context, id uint, user *model.User) (*model.User, error) {
 	u := query.Use(d.db).User
 	userDo := u.WithContext(ctx)
@@ -197,7 +352,7 @@ func (d database) UpdateUserByID(ctx context.Context, id uint, user *model.User)
 
 func (d database) AcceptUser(ctx context.Context, id uint) (*gen.ResultInfo, error) {
 	u := query.Use(d.db).User
-	result, err := u.WithContext(ctx).Where(u.ID.Eq(id)).Update(u.Status, model.UserStatusAccepted)
+	result, err := u.WithContext(ctx).Where(u.ID.Eq(id), u.Status.Neq(model.UserStatusBanned)).Update(u.Status, model.UserStatusAccepted)
 	if err != nil {
 		return nil, err
 	}
@@ -205,7 +360,7 @@ func (d database) AcceptUser(ctx context.Context, id uint) (*gen.ResultInfo, err
 }
 func (d database) RejectUser(ctx context.Context, id uint) (*gen.ResultInfo, error) {
 	u := query.Use(d.db).User
-	result, err := u.WithContext(ctx).Where(u.ID.Eq(id)).Update(u.Status, model.UserStatusRejected)
+	result, err := u.WithContext(ctx).Where(u.ID.Eq(id), u.Status.Neq(model.UserStatusBanned)).Update(u.Status, model.UserStatusRejected)
 	if err != nil {
 		return nil, err
 	}
@@ -220,6 +375,150 @@ func (d database) SetUserStatus(ctx context.Context, id uint, status string) (*g
 	return &result, nil
 }
 
+func (d database) ReplaceUserStatus(ctx context.Context, id uint, from string, to string) (*gen.ResultInfo, error) {
+	u := query.Use(d.db).User
+	result, err := u.WithContext(ctx).Where(u.ID.Eq(id), u.Status.Eq(from)).Update(u.Status, to)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (d database) BanUser(ctx context.Context, telegramID int64, bannedBy int64, reason *string) (*gen.ResultInfo, error) {
+	var result gen.ResultInfo
+	q := query.Use(d.db)
+	err := q.Transaction(func(tx *query.Query) error {
+		u := tx.User
+		user, err := u.WithContext(ctx).Where(u.TelegramID.Eq(telegramID), u.Status.Neq(model.UserStatusBanned)).First()
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		result, err = u.WithContext(ctx).Where(u.TelegramID.Eq(telegramID), u.Status.Neq(model.UserStatusBanned)).Update(u.Status, model.UserStatusBanned)
+		if err != nil {
+			return err
+		}
+		err = tx.Ban.WithContext(ctx).Create(&model.Ban{
+			UserTelegramId: telegramID,
+			Reason:         reason,
+			BannedBy:       bannedBy,
+			PreviousStatus: user.Status,
+		})
+		if err != nil {
+			return err
+		}
+		// A poll decided later must not lift the ban
+		p := tx.MembershipPoll
+		_, err = p.WithContext(ctx).Where(p.UserTelegramId.Eq(telegramID), p.Status.In(model.MembershipPollStatusOpen, model.MembershipPollStatusEscalated)).Updates(map[string]interface{}{
+			"status":     model.MembershipPollStatusClosed,
+			"decision":   model.PollDecisionRejected,
+			"decided_by": bannedBy,
+			"closed_at":  time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tx.Token.WithContext(ctx).Where(tx.Token.UserTelegramId.Eq(telegramID)).Delete()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (d database) UnbanUser(ctx context.Context, telegramID int64, unbannedBy int64) (*gen.ResultInfo, error) {
+	var result gen.ResultInfo
+	q := query.Use(d.db)
+	err := q.Transaction(func(tx *query.Query) error {
+		u, b := tx.User, tx.Ban
+		bans, err := b.WithContext(ctx).Where(b.UserTelegramId.Eq(telegramID), b.UnbannedAt.IsNull()).Order(b.CreatedAt.Desc()).Limit(1).Find()
+		if err != nil {
+			return err
+		}
+		// The ban removed the user from the group, and users banned before bans were
+		// recorded have no previous status
+		status := model.UserStatusNotActive
+		if len(bans) > 0 && bans[0].PreviousStatus != "" && bans[0].PreviousStatus != model.UserStatusActive {
+			status = bans[0].PreviousStatus
+		}
+		result, err = u.WithContext(ctx).Where(u.TelegramID.Eq(telegramID), u.Status.Eq(model.UserStatusBanned)).Update(u.Status, status)
+		if err != nil || result.RowsAffected == 0 {
+			return err
+		}
+		_, err = b.WithContext(ctx).Where(b.UserTelegramId.Eq(telegramID), b.UnbannedAt.IsNull()).Updates(map[string]interface{}{
+			"unbanned_by": unbannedBy,
+			"unbanned_at": time.Now(),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (d database) GetActiveBan(ctx context.Context, telegramID int64) (*model.Ban, error) {
+	b := query.Use(d.db).Ban
+	ban, err := b.WithContext(ctx).Where(b.UserTelegramId.Eq(telegramID), b.UnbannedAt.IsNull()).Order(b.CreatedAt.Desc()).First()
+	if err != nil {
+		return nil, err
+	}
+	return ban, nil
+}
+
+func (d database) SetBirthdayOptOut(ctx context.Context, telegramID int64, optOut bool) (*gen.ResultInfo, error) {
+	u := query.Use(d.db).User
+	result, err := u.WithContext(ctx).Where(u.TelegramID.Eq(telegramID)).Update(u.BirthdayOptOut, optOut)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (d database) GetUsersToRemind(ctx context.Context, createdAfter time.Time, createdBefore time.Time, remindedBefore time.Time, maxReminders int) ([]*model.User, error) {
+	q := query.Use(d.db)
+	u, f := q.User, q.Form
+	users, err := u.WithContext(ctx).
+		Where(
+			u.Status.Eq(model.UserStatusNew),
+			u.CreatedAt.Gt(createdAfter),
+			u.CreatedAt.Lt(createdBefore),
+			u.ApplyReminders.Lt(int32(maxReminders)),
+			u.WithContext(ctx).Columns(u.TelegramID).NotIn(f.WithContext(ctx).Select(f.UserTelegramId)),
+		).
+		Where(u.WithContext(ctx).Where(u.ApplyRemindedAt.IsNull()).Or(u.ApplyRemindedAt.Lt(remindedBefore))).
+		Order(u.CreatedAt).
+		Find()
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (d database) MarkUserReminded(ctx context.Context, telegramID int64, reminders int32) (*gen.ResultInfo, error) {
+	u := query.Use(d.db).User
+	result, err := u.WithContext(ctx).Where(u.TelegramID.Eq(telegramID), u.ApplyReminders.Eq(reminders)).Updates(map[string]interface{}{
+		"apply_reminders":   reminders + 1,
+		"apply_reminded_at": time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (d database) GetUsersJoinedBetween(ctx context.Context, from time.Time, to time.Time) ([]*model.User, error) {
+	u := query.Use(d.db).User
+	users, err := u.WithContext(ctx).Where(u.Status.Eq(model.UserStatusActive), u.JoinedAt.Gte(from), u.JoinedAt.Lt(to)).Order(u.JoinedAt).Find()
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (d database) CreateForm(ctx context.Context, form *model.Form) (*model.Form, error) {
 	f := query.Use(d.db).Form
 	err := f.WithContext(ctx).Create(form)
@@ -239,18 +538,31 @@ func (d database) GetFormByID(ctx context.Context, id uint) (*model.Form, error)
 	return first, nil
 }
 
-func (d database) AcceptForm(ctx context.Context, id uint) (*gen.ResultInfo, error) {
+func (d database) AcceptForm(ctx context.Context, id uint, decidedBy int64) (*gen.ResultInfo, error) {
 	f := query.Use(d.db).Form
-	result, err := f.WithContext(ctx).Where(f.ID.Eq(id)).Update(f.Status, model.FormStatusAccepted)
+	result, err := f.WithContext(ctx).Where(f.ID.Eq(id), f.Status.Eq(model.FormStatusNew)).Updates(map[string]interface{}{
+		"status":     model.FormStatusAccepted,
+		"decided_by": decidedBy,
+		"decided_at": time.Now(),
+	})
 	if err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
-func (d database) RejectForm(ctx context.Context, id uint) (*gen.ResultInfo, error) {
+func (d database) RejectForm(ctx context.Context, id uint, reason string, decidedBy int64) (*gen.ResultInfo, error) {
 	f := query.Use(d.db).Form
-	result, err := f.WithContext(ctx).Where(f.ID.Eq(id)).Update(f.Status, model.FormStatusRejected)
+	var rejectionReason *string
+	if reason != "" {
+		rejectionReason = &reason
+	}
+	result, err := f.WithContext(ctx).Where(f.ID.Eq(id), f.Status.Eq(model.FormStatusNew)).Updates(map[string]interface{}{
+		"status":           model.FormStatusRejected,
+		"rejection_reason": rejectionReason,
+		"decided_by":       decidedBy,
+		"decided_at":       time.Now(),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -277,7 +589,7 @@ func (d database) GetLastForm(ctx context.Context, telegramID int64) (*model.For
 
 func (d database) GetAllUserForms(ctx context.Context, telegramID int64) ([]*model.Form, error) {
 	f := query.Use(d.db).Form
-	all, err := f.WithContext(ctx).Preload(f.User).Where(f.UserTelegramId.Eq(telegramID)).Find()
+	all, err := f.WithContext(ctx).Preload(f.User).Where(f.UserTelegramId.Eq(telegramID)).Order(f.CreatedAt.Desc()).Find()
 	if err != nil {
 		return nil, err
 	}
@@ -295,6 +607,71 @@ func (d database) GetAllForms(ctx context.Context) ([]*model.Form, error) {
 
 func (d database) GetAllAcceptedFormsWithUser(ctx context.Context) ([]*model.Form, error) {
 	q := query.Use(d.db)
+	f := q.Form
+	latest, on, isLatest := latestAcceptedForms(ctx, q)
+	forms, err := f.WithContext(ctx).Preload(f.User).
+		LeftJoin(latest, on).
+		Where(isLatest).
+		Find()
+	if err != nil {
+		return nil, err
+	}
+
+	return forms, nil
+}
+
+func (d database) SearchAcceptedForms(ctx context.Context, fragment string, offset int, limit int) ([]*model.Form, error) {
+	q := query.Use(d.db)
+	u, f := q.User, q.Form
+	pattern := "%" + likeEscaper.Replace(fragment) + "%"
+	latest, on, isLatest := latestAcceptedForms(ctx, q)
+	matches := f.WithContext(ctx).
+		Where(f.Name.Like(pattern)).
+		Or(f.Hobbies.Like(pattern)).
+		Or(u.FirstName.Like(pattern)).
+		Or(u.LastName.Like(pattern)).
+		Or(u.Username.Like(pattern))
+	forms, err := f.WithContext(ctx).Preload(f.User).
+		LeftJoin(latest, on).
+		Join(u, u.TelegramID.EqCol(f.UserTelegramId)).
+		Where(isLatest).
+		Where(matches).
+		Order(f.Name).
+		Offset(offset).
+		Limit(limit).
+		Find()
+	if err != nil {
+		return nil, err
+	}
+	return forms, nil
+}
+
+func (d database) GetBirthdayForms(ctx context.Context, month int, days ...int) ([]*model.Form, error) {
+	q := query.Use(d.db)
+	u, f := q.User, q.Form
+	birthDays := make([]int32, 0, len(days))
+	for _, day := range days {
+		birthDays = append(birthDays, int32(day))
+	}
+	latest, on, isLatest := latestAcceptedForms(ctx, q)
+	forms, err := f.WithContext(ctx).Preload(f.User).
+		LeftJoin(latest, on).
+		Join(u, u.TelegramID.EqCol(f.UserTelegramId)).
+		Where(isLatest).
+		Where(f.BirthMonth.Eq(int32(month))).
+		Where(f.BirthDay.In(birthDays...)).
+		Where(u.BirthdayOptOut.Is(false)).
+		Order(f.Name).
+		Find()
+	if err != nil {
+		return nil, err
+	}
+	return forms, nil
+}
+
+// latestAcceptedForms returns the subquery of the last accepted form of every active user,
+// the condition to join it to forms and the condition that keeps only those forms.
+func latestAcceptedForms(ctx context.Context, q *query.Query) (gen.Dao, field.Expr, field.Expr) {
 	u := q.User
 	f := q.Form
 	f2 := f.As("f2")
@@ -307,17 +684,406 @@ func (d database) GetAllAcceptedFormsWithUser(ctx context.Context) ([]*model.For
 		Select(f.UserTelegramId, f.CreatedAt.Max().As("created_at_max")).
 		As("f2").
 		Attrs(createdAtMax)
-	forms, err := f.WithContext(ctx).Preload(f.User).
-		LeftJoin(subQuery, f2.UserTelegramId.EqCol(f.UserTelegramId)).
-		Where(createdAtMax.EqCol(f.CreatedAt)).
-		Find()
+	return subQuery, f2.UserTelegramId.EqCol(f.UserTelegramId), createdAtMax.EqCol(f.CreatedAt)
+}
+
+func (d database) GetFormsByStatus(ctx context.Context, status string) ([]*model.Form, error) {
+	f := query.Use(d.db).Form
+	forms, err := f.WithContext(ctx).Preload(f.User).Where(f.Status.Eq(status)).Order(f.CreatedAt).Find()
+	if err != nil {
+		return nil, err
+	}
+	return forms, nil
+}
+
+func (d database) GetPendingForms(ctx context.Context, createdBefore time.Time) ([]*model.Form, error) {
+	f := query.Use(d.db).Form
+	forms, err := f.WithContext(ctx).Preload(f.User).Where(f.Status.Eq(model.FormStatusNew), f.CreatedAt.Lt(createdBefore)).Order(f.CreatedAt).Find()
 	if err != nil {
 		return nil, err
 	}
+	return forms, nil
+}
 
+func (d database) GetFormsDecidedBetween(ctx context.Context, from time.Time, to time.Time) ([]*model.Form, error) {
+	f := query.Use(d.db).Form
+	forms, err := f.WithContext(ctx).Preload(f.User).Where(f.DecidedAt.Gte(from), f.DecidedAt.Lt(to)).Order(f.DecidedAt).Find()
+	if err != nil {
+		return nil, err
+	}
 	return forms, nil
 }
 
+func (d database) CountFormsCreatedBetween(ctx context.Context, from time.Time, to time.Time) (int64, error) {
+	f := query.Use(d.db).Form
+	return f.WithContext(ctx).Where(f.CreatedAt.Gte(from), f.CreatedAt.Lt(to)).Count()
+}
+
+func (d database) GetFormDraft(ctx context.Context, telegramID int64) (*model.FormDraft, error) {
+	f := query.Use(d.db).FormDraft
+	draft, err := f.WithContext(ctx).Where(f.UserTelegramId.Eq(telegramID)).First()
+	if err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+func (d database) SaveFormDraft(ctx context.Context, draft *model.FormDraft) error {
+	f := query.Use(d.db).FormDraft
+	return f.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_telegram_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"step", "form", "updated_at"}),
+	}).Create(draft)
+}
+
+func (d database) DeleteFormDraft(ctx context.Context, telegramID int64) error {
+	f := query.Use(d.db).FormDraft
+	_, err := f.WithContext(ctx).Where(f.UserTelegramId.Eq(telegramID)).Delete()
+	return err
+}
+
+func (d database) GetUserRole(ctx context.Context, telegramID int64) (string, error) {
+	r := query.Use(d.db).Role
+	roles, err := r.WithContext(ctx).Where(r.UserTelegramId.Eq(telegramID)).Limit(1).Find()
+	if err != nil {
+		return "", err
+	}
+	if len(roles) == 0 {
+		return model.RoleMember, nil
+	}
+	return roles[0].Role, nil
+}
+
+func (d database) SetUserRole(ctx context.Context, telegramID int64, role string, grantedBy int64) error {
+	r := query.Use(d.db).Role
+	if role == model.RoleMember {
+		_, err := r.WithContext(ctx).Where(r.UserTelegramId.Eq(telegramID)).Delete()
+		return err
+	}
+	return r.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_telegram_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "granted_by", "updated_at"}),
+	}).Create(&model.Role{
+		UserTelegramId: telegramID,
+		Role:           role,
+		GrantedBy:      &grantedBy,
+	})
+}
+
+func (d database) GetRoles(ctx context.Context) ([]*model.Role, error) {
+	r := query.Use(d.db).Role
+	roles, err := r.WithContext(ctx).Preload(r.User).Order(r.CreatedAt).Find()
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (d database) GetUserIDsWithPermission(ctx context.Context, permission string) ([]int64, error) {
+	r := query.Use(d.db).Role
+	var ids []int64
+	err := r.WithContext(ctx).Where(r.Role.In(model.RolesWithPermission(permission)...)).Pluck(r.UserTelegramId, &ids)
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (d database) EnsureOwner(ctx context.Context, telegramID int64) (bool, error) {
+	created := false
+	q := query.Use(d.db)
+	err := q.Transaction(func(tx *query.Query) error {
+		r := tx.Role
+		owners, err := r.WithContext(ctx).Where(r.Role.Eq(model.RoleOwner)).Count()
+		if err != nil {
+			return err
+		}
+		if owners > 0 {
+			return nil
+		}
+		// The owner may have never written to the bot, so the user is created for the foreign key
+		_, err = tx.User.WithContext(ctx).Where(tx.User.TelegramID.Eq(telegramID)).FirstOrCreate()
+		if err != nil {
+			return err
+		}
+		err = r.WithContext(ctx).Create(&model.Role{
+			UserTelegramId: telegramID,
+			Role:           model.RoleOwner,
+		})
+		if err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+func (d database) CreateOutboxMessage(ctx context.Context, message *model.OutboxMessage) (*model.OutboxMessage, error) {
+	o := query.Use(d.db).OutboxMessage
+	err := o.WithContext(ctx).Create(message)
+	if err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+func (d database) ClaimDueOutboxMessages(ctx context.Context, limit int, leaseUntil time.Time) ([]*model.OutboxMessage, error) {
+	var messages []*model.OutboxMessage
+	q := query.Use(d.db)
+	err := q.Transaction(func(tx *query.Query) error {
+		o := tx.OutboxMessage
+		var err error
+		messages, err = o.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(o.Status.Eq(model.OutboxStatusPending)).
+			Where(o.NextAttemptAt.Lte(time.Now())).
+			Order(o.ID).
+			Limit(limit).
+			Find()
+		if err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+		ids := make([]uint, 0, len(messages))
+		for _, message := range messages {
+			ids = append(ids, message.ID)
+		}
+		_, err = o.WithContext(ctx).Where(o.ID.In(ids...)).Update(o.NextAttemptAt, leaseUntil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (d database) MarkOutboxMessageSent(ctx context.Context, id uint) error {
+	o := query.Use(d.db).OutboxMessage
+	_, err := o.WithContext(ctx).Where(o.ID.Eq(id)).Updates(map[string]interface{}{
+		"status":       model.OutboxStatusSent,
+		"attempts":     gorm.Expr("attempts + 1"),
+		"delay_reason": nil,
+	})
+	return err
+}
+
+func (d database) RescheduleOutboxMessage(ctx context.Context, id uint, attempts int, nextAttemptAt time.Time, lastError string) error {
+	o := query.Use(d.db).OutboxMessage
+	_, err := o.WithContext(ctx).Where(o.ID.Eq(id)).Updates(map[string]interface{}{
+		"attempts":        attempts,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastError,
+	})
+	return err
+}
+
+func (d database) DelayOutboxMessage(ctx context.Context, id uint, nextAttemptAt time.Time, reason string) error {
+	o := query.Use(d.db).OutboxMessage
+	_, err := o.WithContext(ctx).Where(o.ID.Eq(id)).Updates(map[string]interface{}{
+		"next_attempt_at": nextAttemptAt,
+		"delay_reason":    reason,
+	})
+	return err
+}
+
+func (d database) MarkOutboxMessageDead(ctx context.Context, id uint, attempts int, lastError string) error {
+	o := query.Use(d.db).OutboxMessage
+	_, err := o.WithContext(ctx).Where(o.ID.Eq(id)).Updates(map[string]interface{}{
+		"status":     model.OutboxStatusDead,
+		"attempts":   attempts,
+		"last_error": lastError,
+	})
+	return err
+}
+
+func (d database) DeleteSentOutboxMessages(ctx context.Context, before time.Time) (*gen.ResultInfo, error) {
+	o := query.Use(d.db).OutboxMessage
+	result, err := o.WithContext(ctx).Unscoped().Where(o.Status.Eq(model.OutboxStatusSent)).Where(o.UpdatedAt.Lt(before)).Delete()
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (d database) GetBotState(ctx context.Context, key string) (*model.BotState, error) {
+	s := query.Use(d.db).BotState
+	state, err := s.WithContext(ctx).Where(s.Key.Eq(key)).First()
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (d database) SetBotState(ctx context.Context, key string, value string) error {
+	s := query.Use(d.db).BotState
+	return s.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&model.BotState{
+		Key:   key,
+		Value: value,
+	})
+}
+
+func (d database) DeleteBotState(ctx context.Context, key string) error {
+	s := query.Use(d.db).BotState
+	_, err := s.WithContext(ctx).Where(s.Key.Eq(key)).Delete()
+	return err
+}
+
+func (d database) IsUpdateProcessed(ctx context.Context, updateID int) (bool, error) {
+	p := query.Use(d.db).ProcessedUpdate
+	count, err := p.WithContext(ctx).Where(p.UpdateID.Eq(updateID)).Count()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (d database) MarkUpdateProcessed(ctx context.Context, updateID int) error {
+	p := query.Use(d.db).ProcessedUpdate
+	return p.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&model.ProcessedUpdate{
+		UpdateID: updateID,
+	})
+}
+
+func (d database) DeleteProcessedUpdates(ctx context.Context, before time.Time) (*gen.ResultInfo, error) {
+	p := query.Use(d.db).ProcessedUpdate
+	result, err := p.WithContext(ctx).Where(p.CreatedAt.Lt(before)).Delete()
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (d database) CreateMembershipPoll(ctx context.Context, poll *model.MembershipPoll) (*model.MembershipPoll, error) {
+	p := query.Use(d.db).MembershipPoll
+	err := p.WithContext(ctx).Create(poll)
+	if err != nil {
+		return nil, err
+	}
+	return poll, nil
+}
+
+func (d database) GetMembershipPollByPollID(ctx context.Context, pollID string) (*model.MembershipPoll, error) {
+	p := query.Use(d.db).MembershipPoll
+	poll, err := p.WithContext(ctx).Where(p.PollID.Eq(pollID)).First()
+	if err != nil {
+		return nil, err
+	}
+	return poll, nil
+}
+
+func (d database) GetMembershipPollByMessage(ctx context.Context, chatID int64, messageID int) (*model.MembershipPoll, error) {
+	p := query.Use(d.db).MembershipPoll
+	poll, err := p.WithContext(ctx).Where(p.ChatID.Eq(chatID), p.MessageID.Eq(messageID)).First()
+	if err != nil {
+		return nil, err
+	}
+	return poll, nil
+}
+
+func (d database) GetOpenMembershipPolls(ctx context.Context) ([]*model.MembershipPoll, error) {
+	p := query.Use(d.db).MembershipPoll
+	polls, err := p.WithContext(ctx).Preload(p.User).Where(p.Status.Eq(model.MembershipPollStatusOpen)).Order(p.CreatedAt).Find()
+	if err != nil {
+		return nil, err
+	}
+	return polls, nil
+}
+
+func (d database) GetOpenMembershipPollByUser(ctx context.Context, telegramID int64) (*model.MembershipPoll, error) {
+	p := query.Use(d.db).MembershipPoll
+	poll, err := p.WithContext(ctx).Where(p.UserTelegramId.Eq(telegramID), p.Status.Eq(model.MembershipPollStatusOpen)).Order(p.CreatedAt.Desc()).First()
+	if err != nil {
+		return nil, err
+	}
+	return poll, nil
+}
+
+func (d database) GetMembershipPollsByUser(ctx context.Context, telegramID int64) ([]*model.MembershipPoll, error) {
+	p := query.Use(d.db).MembershipPoll
+	polls, err := p.WithContext(ctx).Where(p.UserTelegramId.Eq(telegramID)).Order(p.CreatedAt.Desc()).Find()
+	if err != nil {
+		return nil, err
+	}
+	return polls, nil
+}
+
+func (d database) GetDueMembershipPolls(ctx context.Context, createdBefore time.Time) ([]*model.MembershipPoll, error) {
+	p := query.Use(d.db).MembershipPoll
+	polls, err := p.WithContext(ctx).Preload(p.User).Where(p.Status.Eq(model.MembershipPollStatusOpen), p.CreatedAt.Lt(createdBefore)).Order(p.CreatedAt).Find()
+	if err != nil {
+		return nil, err
+	}
+	return polls, nil
+}
+
+func (d database) EscalateMembershipPoll(ctx context.Context, id uint) (*gen.ResultInfo, error) {
+	p := query.Use(d.db).MembershipPoll
+	result, err := p.WithContext(ctx).Where(p.ID.Eq(id), p.Status.Eq(model.MembershipPollStatusOpen)).Update(p.Status, model.MembershipPollStatusEscalated)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (d database) UpdateMembershipPollTally(ctx context.Context, pollID string, acceptVotes int, rejectVotes int) error {
+	p := query.Use(d.db).MembershipPoll
+	_, err := p.WithContext(ctx).Where(p.PollID.Eq(pollID)).Updates(map[string]interface{}{
+		"accept_votes": acceptVotes,
+		"reject_votes": rejectVotes,
+	})
+	return err
+}
+
+func (d database) CloseMembershipPoll(ctx context.Context, id uint, userID uint, decision string, decidedBy *int64) (*gen.ResultInfo, error) {
+	status := model.UserStatusRejected
+	if decision == model.PollDecisionAccepted {
+		status = model.UserStatusAccepted
+	}
+	var result gen.ResultInfo
+	q := query.Use(d.db)
+	err := q.Transaction(func(tx *query.Query) error {
+		p, u := tx.MembershipPoll, tx.User
+		var err error
+		result, err = p.WithContext(ctx).Where(p.ID.Eq(id), p.Status.In(model.MembershipPollStatusOpen, model.MembershipPollStatusEscalated)).Updates(map[string]interface{}{
+			"status":     model.MembershipPollStatusClosed,
+			"decision":   decision,
+			"decided_by": decidedBy,
+			"closed_at":  time.Now(),
+		})
+		if err != nil || result.RowsAffected == 0 {
+			return err
+		}
+		result, err = u.WithContext(ctx).Where(u.ID.Eq(userID), u.Status.Neq(model.UserStatusBanned)).Update(u.Status, status)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (d database) SavePollVote(ctx context.Context, vote *model.PollVote) error {
+	v := query.Use(d.db).PollVote
+	return v.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "membership_poll_id"}, {Name: "voter_telegram_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"option", "updated_at"}),
+	}).Create(vote)
+}
+
+func (d database) DeletePollVote(ctx context.Context, membershipPollID uint, voterTelegramID int64) error {
+	v := query.Use(d.db).PollVote
+	_, err := v.WithContext(ctx).Where(v.MembershipPollID.Eq(membershipPollID), v.VoterTelegramId.Eq(voterTelegramID)).Delete()
+	return err
+}
+
 func NewDatabase(dsn string, logger *zap.Logger) (Database, error) {
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
 	if err != nil {
@@ -330,3 +1096,75 @@ func NewDatabase(dsn string, logger *zap.Logger) (Database, error) {
 func NewDatabaseWithDb(db *gorm.DB, logger *zap.Logger) Database {
 	return &database{db, logger, uuid.New}
 }
+
+func (d database) CreateInviteLink(ctx context.Context, link *model.InviteLink) (*model.InviteLink, error) {
+	i := query.Use(d.db).InviteLink
+	err := i.WithContext(ctx).Create(link)
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (d database) GetInviteLinkByLink(ctx context.Context, link string) (*model.InviteLink, error) {
+	i := query.Use(d.db).InviteLink
+	inviteLink, err := i.WithContext(ctx).Where(i.Link.Eq(link)).First()
+	if err != nil {
+		return nil, err
+	}
+	return inviteLink, nil
+}
+
+func (d database) GetActiveInviteLink(ctx context.Context, telegramID int64, expireAfter time.Time) (*model.InviteLink, error) {
+	i := query.Use(d.db).InviteLink
+	inviteLink, err := i.WithContext(ctx).
+		Where(i.UserTelegramId.Eq(telegramID), i.Status.Eq(model.InviteLinkStatusActive), i.ExpireAt.Gt(expireAfter)).
+		Order(i.ExpireAt.Desc()).
+		First()
+	if err != nil {
+		return nil, err
+	}
+	return inviteLink, nil
+}
+
+func (d database) GetActiveInviteLinksByUser(ctx context.Context, telegramID int64) ([]*model.InviteLink, error) {
+	i := query.Use(d.db).InviteLink
+	links, err := i.WithContext(ctx).Where(i.UserTelegramId.Eq(telegramID), i.Status.Eq(model.InviteLinkStatusActive)).Find()
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (d database) GetExpiredInviteLinks(ctx context.Context, expiredBefore time.Time) ([]*model.InviteLink, error) {
+	i := query.Use(d.db).InviteLink
+	links, err := i.WithContext(ctx).Where(i.Status.Eq(model.InviteLinkStatusActive), i.ExpireAt.Lt(expiredBefore)).Find()
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (d database) MarkInviteLinkUsed(ctx context.Context, id uint) (*gen.ResultInfo, error) {
+	i := query.Use(d.db).InviteLink
+	result, err := i.WithContext(ctx).Where(i.ID.Eq(id), i.Status.Eq(model.InviteLinkStatusActive)).Updates(map[string]interface{}{
+		"status":  model.InviteLinkStatusUsed,
+		"used_at": time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (d database) RevokeInviteLink(ctx context.Context, id uint) (*gen.ResultInfo, error) {
+	i := query.Use(d.db).InviteLink
+	result, err := i.WithContext(ctx).Where(i.ID.Eq(id), i.Status.Eq(model.InviteLinkStatusActive)).Updates(map[string]interface{}{
+		"status":     model.InviteLinkStatusRevoked,
+		"revoked_at": time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
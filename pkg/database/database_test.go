@@ -56,10 +56,10 @@ func Test_database(t *testing.T) {
 	})
 	t.Run("CreateOrProlongToken", func(t *testing.T) {
 		mock.ExpectBegin()
-		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `tokens` (`user_telegram_id`,`expire_at`,`uuid`) VALUES (?,?,?)")).WithArgs(
-			10,
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `tokens` (`expire_at`,`uuid`,`user_telegram_id`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `uuid`=VALUES(`uuid`),`expire_at`=VALUES(`expire_at`)")).WithArgs(
 			sqlmock.AnyArg(),
 			testUUID.String(),
+			10,
 		).WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 		token, err := db.CreateOrProlongToken(ctx, 10)
@@ -69,14 +69,20 @@ func Test_database(t *testing.T) {
 	})
 	t.Run("CreateUser", func(t *testing.T) {
 		mock.ExpectBegin()
-		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `users` (`created_at`,`updated_at`,`deleted_at`,`telegram_id`,`username`,`status`) VALUES (?,?,?,?,?,?)")).
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `users` (`created_at`,`updated_at`,`deleted_at`,`telegram_id`,`username`,`first_name`,`last_name`,`status`,`birthday_opt_out`,`apply_reminders`,`apply_reminded_at`,`joined_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?)")).
 			WithArgs(
 				sqlmock.AnyArg(),
 				sqlmock.AnyArg(),
 				sqlmock.AnyArg(),
 				10,
 				"test",
+				"",
+				nil,
 				model.UserStatusNew,
+				false,
+				0,
+				nil,
+				nil,
 			).WillReturnResult(sqlmock.NewResult(10, 1))
 		mock.ExpectCommit()
 
@@ -87,5 +93,81 @@ func Test_database(t *testing.T) {
 		})
 		assert.NoError(t, err)
 	})
+	t.Run("UpdateOrCreateUser keeps the ban and the join time", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("ON DUPLICATE KEY UPDATE `first_name`=VALUES(`first_name`),`joined_at`=IF(`status` IN (?, ?), `joined_at`, VALUES(`joined_at`)),`status`=IF(`status` = ?, `status`, VALUES(`status`))")).
+			WillReturnResult(sqlmock.NewResult(10, 1))
+		mock.ExpectCommit()
+
+		_, err := db.UpdateOrCreateUser(ctx, &model.User{
+			TelegramID: 10,
+			FirstName:  "test",
+			Status:     model.UserStatusActive,
+		})
+		assert.NoError(t, err)
+	})
+	t.Run("Ban during an open poll", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE `users`.`telegram_id` = ? AND `users`.`status` <> ?")).
+			WithArgs(10, model.UserStatusBanned).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "telegram_id", "status"}).AddRow(5, 10, model.UserStatusNew))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `status`=?")).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `bans`")).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `membership_polls` SET `closed_at`=?,`decided_by`=?,`decision`=?,`status`=?,`updated_at`=? WHERE `membership_polls`.`user_telegram_id` = ? AND `membership_polls`.`status` IN (?,?)")).
+			WithArgs(sqlmock.AnyArg(), 1, model.PollDecisionRejected, model.MembershipPollStatusClosed, sqlmock.AnyArg(), 10, model.MembershipPollStatusOpen, model.MembershipPollStatusEscalated).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("`tokens`")).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		result, err := db.BanUser(ctx, 10, 1, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.RowsAffected)
+
+		// The poll is decided after the ban
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `membership_polls` SET")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		result, err = db.CloseMembershipPoll(ctx, 3, 5, model.PollDecisionAccepted, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), result.RowsAffected)
 
+		// Even a poll the ban missed doesn't change a banned user
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `membership_polls` SET")).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `status`=?,`updated_at`=? WHERE `users`.`id` = ? AND `users`.`status` <> ?")).
+			WithArgs(model.UserStatusAccepted, sqlmock.AnyArg(), 5, model.UserStatusBanned).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		result, err = db.CloseMembershipPoll(ctx, 3, 5, model.PollDecisionAccepted, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), result.RowsAffected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Unban restores the status before the ban", func(t *testing.T) {
+		tests := []struct {
+			previous string
+			restored string
+		}{
+			{previous: model.UserStatusAccepted, restored: model.UserStatusAccepted},
+			{previous: model.UserStatusRejected, restored: model.UserStatusRejected},
+			// The ban removed the user from the group
+			{previous: model.UserStatusActive, restored: model.UserStatusNotActive},
+			// Banned before the previous status was recorded
+			{previous: "", restored: model.UserStatusNotActive},
+		}
+		for _, tt := range tests {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `bans` WHERE `bans`.`user_telegram_id` = ? AND `bans`.`unbanned_at` IS NULL")).
+				WithArgs(10).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_telegram_id", "previous_status"}).AddRow(1, 10, tt.previous))
+			mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `status`=?,`updated_at`=? WHERE `users`.`telegram_id` = ? AND `users`.`status` = ?")).
+				WithArgs(tt.restored, sqlmock.AnyArg(), 10, model.UserStatusBanned).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta("UPDATE `bans` SET")).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			result, err := db.UnbanUser(ctx, 10, 1)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), result.RowsAffected)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
 }
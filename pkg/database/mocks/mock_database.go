@@ -7,35 +7,67 @@ package mock_database
 import (
 	model "beneburg/pkg/database/model"
 	context "context"
+	reflect "reflect"
+	time "time"
+
 	gomock "github.com/golang/mock/gomock"
 	gen "gorm.io/gen"
-	reflect "reflect"
 )
 
-// MockDatabase is a mock of Database interface
+// MockDatabase is a mock of Database interface.
 type MockDatabase struct {
 	ctrl     *gomock.Controller
 	recorder *MockDatabaseMockRecorder
 }
 
-// MockDatabaseMockRecorder is the mock recorder for MockDatabase
+// MockDatabaseMockRecorder is the mock recorder for MockDatabase.
 type MockDatabaseMockRecorder struct {
 	mock *MockDatabase
 }
 
-// NewMockDatabase creates a new mock instance
+// NewMockDatabase creates a new mock instance.
 func NewMockDatabase(ctrl *gomock.Controller) *MockDatabase {
 	mock := &MockDatabase{ctrl: ctrl}
 	mock.recorder = &MockDatabaseMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockDatabase) EXPECT() *MockDatabaseMockRecorder {
 	return m.recorder
 }
 
-// AutoMigrate mocks base method
+// AcceptForm mocks base method.
+func (m *MockDatabase) AcceptForm(ctx context.Context, id uint, decidedBy int64) (*gen.ResultInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptForm", ctx, id, decidedBy)
+	ret0, _ := ret[0].(*gen.ResultInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptForm indicates an expected call of AcceptForm.
+func (mr *MockDatabaseMockRecorder) AcceptForm(ctx, id, decidedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptForm", reflect.TypeOf((*MockDatabase)(nil).AcceptForm), ctx, id, decidedBy)
+}
+
+// AcceptUser mocks base method.
+func (m *MockDatabase) AcceptUser(ctx context.Context, id uint) (*gen.ResultInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptUser", ctx, id)
+	ret0, _ := ret[0].(*gen.ResultInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptUser indicates an expected call of AcceptUser.
+func (mr *MockDatabaseMockRecorder) AcceptUser(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptUser", reflect.TypeOf((*MockDatabase)(nil).AcceptUser), ctx, id)
+}
+
+// AutoMigrate mocks base method.
 func (m *MockDatabase) AutoMigrate(models ...interface{}) error {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{}
@@ -47,238 +79,324 @@ func (m *MockDatabase) AutoMigrate(models ...interface{}) error {
 	return ret0
 }
 
-// AutoMigrate indicates an expected call of AutoMigrate
+// AutoMigrate indicates an expected call of AutoMigrate.
 func (mr *MockDatabaseMockRecorder) AutoMigrate(models ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AutoMigrate", reflect.TypeOf((*MockDatabase)(nil).AutoMigrate), models...)
 }
 
-// CreateUser mocks base method
-func (m *MockDatabase) CreateUser(ctx context.Context, user *model.User) (*model.User, error) {
+// BanUser mocks base method.
+func (m *MockDatabase) BanUser(ctx context.Context, telegramID, bannedBy int64, reason *string) (*gen.ResultInfo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", ctx, user)
-	ret0, _ := ret[0].(*model.User)
+	ret := m.ctrl.Call(m, "BanUser", ctx, telegramID, bannedBy, reason)
+	ret0, _ := ret[0].(*gen.ResultInfo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUser indicates an expected call of CreateUser
-func (mr *MockDatabaseMockRecorder) CreateUser(ctx, user interface{}) *gomock.Call {
+// BanUser indicates an expected call of BanUser.
+func (mr *MockDatabaseMockRecorder) BanUser(ctx, telegramID, bannedBy, reason interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockDatabase)(nil).CreateUser), ctx, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanUser", reflect.TypeOf((*MockDatabase)(nil).BanUser), ctx, telegramID, bannedBy, reason)
 }
 
-// UpdateOrCreateUser mocks base method
-func (m *MockDatabase) UpdateOrCreateUser(ctx context.Context, user *model.User) (*model.User, error) {
+// ClaimDueOutboxMessages mocks base method.
+func (m *MockDatabase) ClaimDueOutboxMessages(ctx context.Context, limit int, leaseUntil time.Time) ([]*model.OutboxMessage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateOrCreateUser", ctx, user)
-	ret0, _ := ret[0].(*model.User)
+	ret := m.ctrl.Call(m, "ClaimDueOutboxMessages", ctx, limit, leaseUntil)
+	ret0, _ := ret[0].([]*model.OutboxMessage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateOrCreateUser indicates an expected call of UpdateOrCreateUser
-func (mr *MockDatabaseMockRecorder) UpdateOrCreateUser(ctx, user interface{}) *gomock.Call {
+// ClaimDueOutboxMessages indicates an expected call of ClaimDueOutboxMessages.
+func (mr *MockDatabaseMockRecorder) ClaimDueOutboxMessages(ctx, limit, leaseUntil interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrCreateUser", reflect.TypeOf((*MockDatabase)(nil).UpdateOrCreateUser), ctx, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueOutboxMessages", reflect.TypeOf((*MockDatabase)(nil).ClaimDueOutboxMessages), ctx, limit, leaseUntil)
 }
 
-// CreateOrProlongToken mocks base method
-func (m *MockDatabase) CreateOrProlongToken(ctx context.Context, telegramID int64) (*model.Token, error) {
+// CloseMembershipPoll mocks base method.
+func (m *MockDatabase) CloseMembershipPoll(ctx context.Context, id, userID uint, decision string, decidedBy *int64) (*gen.ResultInfo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateOrProlongToken", ctx, telegramID)
-	ret0, _ := ret[0].(*model.Token)
+	ret := m.ctrl.Call(m, "CloseMembershipPoll", ctx, id, userID, decision, decidedBy)
+	ret0, _ := ret[0].(*gen.ResultInfo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateOrProlongToken indicates an expected call of CreateOrProlongToken
-func (mr *MockDatabaseMockRecorder) CreateOrProlongToken(ctx, telegramID interface{}) *gomock.Call {
+// CloseMembershipPoll indicates an expected call of CloseMembershipPoll.
+func (mr *MockDatabaseMockRecorder) CloseMembershipPoll(ctx, id, userID, decision, decidedBy interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrProlongToken", reflect.TypeOf((*MockDatabase)(nil).CreateOrProlongToken), ctx, telegramID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseMembershipPoll", reflect.TypeOf((*MockDatabase)(nil).CloseMembershipPoll), ctx, id, userID, decision, decidedBy)
 }
 
-// GetUserByToken mocks base method
-func (m *MockDatabase) GetUserByToken(ctx context.Context, token string) (*model.User, error) {
+// CountFormsCreatedBetween mocks base method.
+func (m *MockDatabase) CountFormsCreatedBetween(ctx context.Context, from, to time.Time) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByToken", ctx, token)
-	ret0, _ := ret[0].(*model.User)
+	ret := m.ctrl.Call(m, "CountFormsCreatedBetween", ctx, from, to)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByToken indicates an expected call of GetUserByToken
-func (mr *MockDatabaseMockRecorder) GetUserByToken(ctx, token interface{}) *gomock.Call {
+// CountFormsCreatedBetween indicates an expected call of CountFormsCreatedBetween.
+func (mr *MockDatabaseMockRecorder) CountFormsCreatedBetween(ctx, from, to interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByToken", reflect.TypeOf((*MockDatabase)(nil).GetUserByToken), ctx, token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountFormsCreatedBetween", reflect.TypeOf((*MockDatabase)(nil).CountFormsCreatedBetween), ctx, from, to)
 }
 
-// GetAllUsers mocks base method
-func (m *MockDatabase) GetAllUsers(ctx context.Context) ([]*model.User, error) {
+// CreateForm mocks base method.
+func (m *MockDatabase) CreateForm(ctx context.Context, form *model.Form) (*model.Form, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllUsers", ctx)
-	ret0, _ := ret[0].([]*model.User)
+	ret := m.ctrl.Call(m, "CreateForm", ctx, form)
+	ret0, _ := ret[0].(*model.Form)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllUsers indicates an expected call of GetAllUsers
-func (mr *MockDatabaseMockRecorder) GetAllUsers(ctx interface{}) *gomock.Call {
+// CreateForm indicates an expected call of CreateForm.
+func (mr *MockDatabaseMockRecorder) CreateForm(ctx, form interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUsers", reflect.TypeOf((*MockDatabase)(nil).GetAllUsers), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateForm", reflect.TypeOf((*MockDatabase)(nil).CreateForm), ctx, form)
 }
 
-// GetUserByID mocks base method
-func (m *MockDatabase) GetUserByID(ctx context.Context, id uint) (*model.User, error) {
+// CreateInviteLink mocks base method.
+func (m *MockDatabase) CreateInviteLink(ctx context.Context, link *model.InviteLink) (*model.InviteLink, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByID", ctx, id)
-	ret0, _ := ret[0].(*model.User)
+	ret := m.ctrl.Call(m, "CreateInviteLink", ctx, link)
+	ret0, _ := ret[0].(*model.InviteLink)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByID indicates an expected call of GetUserByID
-func (mr *MockDatabaseMockRecorder) GetUserByID(ctx, id interface{}) *gomock.Call {
+// CreateInviteLink indicates an expected call of CreateInviteLink.
+func (mr *MockDatabaseMockRecorder) CreateInviteLink(ctx, link interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockDatabase)(nil).GetUserByID), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInviteLink", reflect.TypeOf((*MockDatabase)(nil).CreateInviteLink), ctx, link)
 }
 
-// GetUserByTelegramID mocks base method
-func (m *MockDatabase) GetUserByTelegramID(ctx context.Context, telegramID int64) (*model.User, error) {
+// CreateMembershipPoll mocks base method.
+func (m *MockDatabase) CreateMembershipPoll(ctx context.Context, poll *model.MembershipPoll) (*model.MembershipPoll, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByTelegramID", ctx, telegramID)
-	ret0, _ := ret[0].(*model.User)
+	ret := m.ctrl.Call(m, "CreateMembershipPoll", ctx, poll)
+	ret0, _ := ret[0].(*model.MembershipPoll)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByTelegramID indicates an expected call of GetUserByTelegramID
-func (mr *MockDatabaseMockRecorder) GetUserByTelegramID(ctx, telegramID interface{}) *gomock.Call {
+// CreateMembershipPoll indicates an expected call of CreateMembershipPoll.
+func (mr *MockDatabaseMockRecorder) CreateMembershipPoll(ctx, poll interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByTelegramID", reflect.TypeOf((*MockDatabase)(nil).GetUserByTelegramID), ctx, telegramID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMembershipPoll", reflect.TypeOf((*MockDatabase)(nil).CreateMembershipPoll), ctx, poll)
 }
 
-// UpdateUserByID mocks base method
-func (m *MockDatabase) UpdateUserByID(ctx context.Context, id uint, user *model.User) (*model.User, error) {
+// CreateOrProlongToken mocks base method.
+func (m *MockDatabase) CreateOrProlongToken(ctx context.Context, telegramID int64) (*model.Token, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUserByID", ctx, id, user)
+	ret := m.ctrl.Call(m, "CreateOrProlongToken", ctx, telegramID)
+	ret0, _ := ret[0].(*model.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrProlongToken indicates an expected call of CreateOrProlongToken.
+func (mr *MockDatabaseMockRecorder) CreateOrProlongToken(ctx, telegramID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrProlongToken", reflect.TypeOf((*MockDatabase)(nil).CreateOrProlongToken), ctx, telegramID)
+}
+
+// CreateOutboxMessage mocks base method.
+func (m *MockDatabase) CreateOutboxMessage(ctx context.Context, message *model.OutboxMessage) (*model.OutboxMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOutboxMessage", ctx, message)
+	ret0, _ := ret[0].(*model.OutboxMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOutboxMessage indicates an expected call of CreateOutboxMessage.
+func (mr *MockDatabaseMockRecorder) CreateOutboxMessage(ctx, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboxMessage", reflect.TypeOf((*MockDatabase)(nil).CreateOutboxMessage), ctx, message)
+}
+
+// CreateUser mocks base method.
+func (m *MockDatabase) CreateUser(ctx context.Context, user *model.User) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, user)
 	ret0, _ := ret[0].(*model.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateUserByID indicates an expected call of UpdateUserByID
-func (mr *MockDatabaseMockRecorder) UpdateUserByID(ctx, id, user interface{}) *gomock.Call {
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockDatabaseMockRecorder) CreateUser(ctx, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserByID", reflect.TypeOf((*MockDatabase)(nil).UpdateUserByID), ctx, id, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockDatabase)(nil).CreateUser), ctx, user)
 }
 
-// AcceptUser mocks base method
-func (m *MockDatabase) AcceptUser(ctx context.Context, id uint) (*gen.ResultInfo, error) {
+// DelayOutboxMessage mocks base method.
+func (m *MockDatabase) DelayOutboxMessage(ctx context.Context, id uint, nextAttemptAt time.Time, reason string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AcceptUser", ctx, id)
+	ret := m.ctrl.Call(m, "DelayOutboxMessage", ctx, id, nextAttemptAt, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelayOutboxMessage indicates an expected call of DelayOutboxMessage.
+func (mr *MockDatabaseMockRecorder) DelayOutboxMessage(ctx, id, nextAttemptAt, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelayOutboxMessage", reflect.TypeOf((*MockDatabase)(nil).DelayOutboxMessage), ctx, id, nextAttemptAt, reason)
+}
+
+// DeleteBotState mocks base method.
+func (m *MockDatabase) DeleteBotState(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBotState", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBotState indicates an expected call of DeleteBotState.
+func (mr *MockDatabaseMockRecorder) DeleteBotState(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBotState", reflect.TypeOf((*MockDatabase)(nil).DeleteBotState), ctx, key)
+}
+
+// DeleteFormDraft mocks base method.
+func (m *MockDatabase) DeleteFormDraft(ctx context.Context, telegramID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFormDraft", ctx, telegramID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFormDraft indicates an expected call of DeleteFormDraft.
+func (mr *MockDatabaseMockRecorder) DeleteFormDraft(ctx, telegramID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFormDraft", reflect.TypeOf((*MockDatabase)(nil).DeleteFormDraft), ctx, telegramID)
+}
+
+// DeletePollVote mocks base method.
+func (m *MockDatabase) DeletePollVote(ctx context.Context, membershipPollID uint, voterTelegramID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePollVote", ctx, membershipPollID, voterTelegramID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePollVote indicates an expected call of DeletePollVote.
+func (mr *MockDatabaseMockRecorder) DeletePollVote(ctx, membershipPollID, voterTelegramID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePollVote", reflect.TypeOf((*MockDatabase)(nil).DeletePollVote), ctx, membershipPollID, voterTelegramID)
+}
+
+// DeleteProcessedUpdates mocks base method.
+func (m *MockDatabase) DeleteProcessedUpdates(ctx context.Context, before time.Time) (*gen.ResultInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProcessedUpdates", ctx, before)
 	ret0, _ := ret[0].(*gen.ResultInfo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// AcceptUser indicates an expected call of AcceptUser
-func (mr *MockDatabaseMockRecorder) AcceptUser(ctx, id interface{}) *gomock.Call {
+// DeleteProcessedUpdates indicates an expected call of DeleteProcessedUpdates.
+func (mr *MockDatabaseMockRecorder) DeleteProcessedUpdates(ctx, before interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptUser", reflect.TypeOf((*MockDatabase)(nil).AcceptUser), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProcessedUpdates", reflect.TypeOf((*MockDatabase)(nil).DeleteProcessedUpdates), ctx, before)
 }
 
-// RejectUser mocks base method
-func (m *MockDatabase) RejectUser(ctx context.Context, id uint) (*gen.ResultInfo, error) {
+// DeleteSentOutboxMessages mocks base method.
+func (m *MockDatabase) DeleteSentOutboxMessages(ctx context.Context, before time.Time) (*gen.ResultInfo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RejectUser", ctx, id)
+	ret := m.ctrl.Call(m, "DeleteSentOutboxMessages", ctx, before)
 	ret0, _ := ret[0].(*gen.ResultInfo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// RejectUser indicates an expected call of RejectUser
-func (mr *MockDatabaseMockRecorder) RejectUser(ctx, id interface{}) *gomock.Call {
+// DeleteSentOutboxMessages indicates an expected call of DeleteSentOutboxMessages.
+func (mr *MockDatabaseMockRecorder) DeleteSentOutboxMessages(ctx, before interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectUser", reflect.TypeOf((*MockDatabase)(nil).RejectUser), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSentOutboxMessages", reflect.TypeOf((*MockDatabase)(nil).DeleteSentOutboxMessages), ctx, before)
 }
 
-// SetUserStatus mocks base method
-func (m *MockDatabase) SetUserStatus(ctx context.Context, id uint, status string) (*gen.ResultInfo, error) {
+// EnsureOwner mocks base method.
+func (m *MockDatabase) EnsureOwner(ctx context.Context, telegramID int64) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetUserStatus", ctx, id, status)
-	ret0, _ := ret[0].(*gen.ResultInfo)
+	ret := m.ctrl.Call(m, "EnsureOwner", ctx, telegramID)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SetUserStatus indicates an expected call of SetUserStatus
-func (mr *MockDatabaseMockRecorder) SetUserStatus(ctx, id, status interface{}) *gomock.Call {
+// EnsureOwner indicates an expected call of EnsureOwner.
+func (mr *MockDatabaseMockRecorder) EnsureOwner(ctx, telegramID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserStatus", reflect.TypeOf((*MockDatabase)(nil).SetUserStatus), ctx, id, status)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureOwner", reflect.TypeOf((*MockDatabase)(nil).EnsureOwner), ctx, telegramID)
 }
 
-// CreateForm mocks base method
-func (m *MockDatabase) CreateForm(ctx context.Context, form *model.Form) (*model.Form, error) {
+// EscalateMembershipPoll mocks base method.
+func (m *MockDatabase) EscalateMembershipPoll(ctx context.Context, id uint) (*gen.ResultInfo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateForm", ctx, form)
-	ret0, _ := ret[0].(*model.Form)
+	ret := m.ctrl.Call(m, "EscalateMembershipPoll", ctx, id)
+	ret0, _ := ret[0].(*gen.ResultInfo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateForm indicates an expected call of CreateForm
-func (mr *MockDatabaseMockRecorder) CreateForm(ctx, form interface{}) *gomock.Call {
+// EscalateMembershipPoll indicates an expected call of EscalateMembershipPoll.
+func (mr *MockDatabaseMockRecorder) EscalateMembershipPoll(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateForm", reflect.TypeOf((*MockDatabase)(nil).CreateForm), ctx, form)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EscalateMembershipPoll", reflect.TypeOf((*MockDatabase)(nil).EscalateMembershipPoll), ctx, id)
 }
 
-// GetFormByID mocks base method
-func (m *MockDatabase) GetFormByID(ctx context.Context, id uint) (*model.Form, error) {
+// GetActiveBan mocks base method.
+func (m *MockDatabase) GetActiveBan(ctx context.Context, telegramID int64) (*model.Ban, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFormByID", ctx, id)
-	ret0, _ := ret[0].(*model.Form)
+	ret := m.ctrl.Call(m, "GetActiveBan", ctx, telegramID)
+	ret0, _ := ret[0].(*model.Ban)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetFormByID indicates an expected call of GetFormByID
-func (mr *MockDatabaseMockRecorder) GetFormByID(ctx, id interface{}) *gomock.Call {
+// GetActiveBan indicates an expected call of GetActiveBan.
+func (mr *MockDatabaseMockRecorder) GetActiveBan(ctx, telegramID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFormByID", reflect.TypeOf((*MockDatabase)(nil).GetFormByID), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveBan", reflect.TypeOf((*MockDatabase)(nil).GetActiveBan), ctx, telegramID)
 }
 
-// AcceptForm mocks base method
-func (m *MockDatabase) AcceptForm(ctx context.Context, id uint) (*gen.ResultInfo, error) {
+// GetActiveInviteLink mocks base method.
+func (m *MockDatabase) GetActiveInviteLink(ctx context.Context, telegramID int64, expireAfter time.Time) (*model.InviteLink, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AcceptForm", ctx, id)
-	ret0, _ := ret[0].(*gen.ResultInfo)
+	ret := m.ctrl.Call(m, "GetActiveInviteLink", ctx, telegramID, expireAfter)
+	ret0, _ := ret[0].(*model.InviteLink)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// AcceptForm indicates an expected call of AcceptForm
-func (mr *MockDatabaseMockRecorder) AcceptForm(ctx, id interface{}) *gomock.Call {
+// GetActiveInviteLink indicates an expected call of GetActiveInviteLink.
+func (mr *MockDatabaseMockRecorder) GetActiveInviteLink(ctx, telegramID, expireAfter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptForm", reflect.TypeOf((*MockDatabase)(nil).AcceptForm), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveInviteLink", reflect.TypeOf((*MockDatabase)(nil).GetActiveInviteLink), ctx, telegramID, expireAfter)
 }
 
-// RejectForm mocks base method
-func (m *MockDatabase) RejectForm(ctx context.Context, id uint) (*gen.ResultInfo, error) {
+// GetActiveInviteLinksByUser mocks base method.
+func (m *MockDatabase) GetActiveInviteLinksByUser(ctx context.Context, telegramID int64) ([]*model.InviteLink, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RejectForm", ctx, id)
-	ret0, _ := ret[0].(*gen.ResultInfo)
+	ret := m.ctrl.Call(m, "GetActiveInviteLinksByUser", ctx, telegramID)
+	ret0, _ := ret[0].([]*model.InviteLink)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// RejectForm indicates an expected call of RejectForm
-func (mr *MockDatabaseMockRecorder) RejectForm(ctx, id interface{}) *gomock.Call {
+// GetActiveInviteLinksByUser indicates an expected call of GetActiveInviteLinksByUser.
+func (mr *MockDatabaseMockRecorder) GetActiveInviteLinksByUser(ctx, telegramID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectForm", reflect.TypeOf((*MockDatabase)(nil).RejectForm), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveInviteLinksByUser", reflect.TypeOf((*MockDatabase)(nil).GetActiveInviteLinksByUser), ctx, telegramID)
 }
 
-// GetActualForm mocks base method
+// GetActualForm mocks base method.
 func (m *MockDatabase) GetActualForm(ctx context.Context, telegramID int64) (*model.Form, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetActualForm", ctx, telegramID)
@@ -287,28 +405,43 @@ func (m *MockDatabase) GetActualForm(ctx context.Context, telegramID int64) (*mo
 	return ret0, ret1
 }
 
-// GetActualForm indicates an expected call of GetActualForm
+// GetActualForm indicates an expected call of GetActualForm.
 func (mr *MockDatabaseMockRecorder) GetActualForm(ctx, telegramID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActualForm", reflect.TypeOf((*MockDatabase)(nil).GetActualForm), ctx, telegramID)
 }
 
-// GetLastForm mocks base method
-func (m *MockDatabase) GetLastForm(ctx context.Context, telegramID int64) (*model.Form, error) {
+// GetAllAcceptedFormsWithUser mocks base method.
+func (m *MockDatabase) GetAllAcceptedFormsWithUser(ctx context.Context) ([]*model.Form, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLastForm", ctx, telegramID)
-	ret0, _ := ret[0].(*model.Form)
+	ret := m.ctrl.Call(m, "GetAllAcceptedFormsWithUser", ctx)
+	ret0, _ := ret[0].([]*model.Form)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetLastForm indicates an expected call of GetLastForm
-func (mr *MockDatabaseMockRecorder) GetLastForm(ctx, telegramID interface{}) *gomock.Call {
+// GetAllAcceptedFormsWithUser indicates an expected call of GetAllAcceptedFormsWithUser.
+func (mr *MockDatabaseMockRecorder) GetAllAcceptedFormsWithUser(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastForm", reflect.TypeOf((*MockDatabase)(nil).GetLastForm), ctx, telegramID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllAcceptedFormsWithUser", reflect.TypeOf((*MockDatabase)(nil).GetAllAcceptedFormsWithUser), ctx)
+}
+
+// GetAllForms mocks base method.
+func (m *MockDatabase) GetAllForms(ctx context.Context) ([]*model.Form, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllForms", ctx)
+	ret0, _ := ret[0].([]*model.Form)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllForms indicates an expected call of GetAllForms.
+func (mr *MockDatabaseMockRecorder) GetAllForms(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllForms", reflect.TypeOf((*MockDatabase)(nil).GetAllForms), ctx)
 }
 
-// GetAllUserForms mocks base method
+// GetAllUserForms mocks base method.
 func (m *MockDatabase) GetAllUserForms(ctx context.Context, telegramID int64) ([]*model.Form, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAllUserForms", ctx, telegramID)
@@ -317,38 +450,739 @@ func (m *MockDatabase) GetAllUserForms(ctx context.Context, telegramID int64) ([
 	return ret0, ret1
 }
 
-// GetAllUserForms indicates an expected call of GetAllUserForms
+// GetAllUserForms indicates an expected call of GetAllUserForms.
 func (mr *MockDatabaseMockRecorder) GetAllUserForms(ctx, telegramID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUserForms", reflect.TypeOf((*MockDatabase)(nil).GetAllUserForms), ctx, telegramID)
 }
 
-// GetAllForms mocks base method
-func (m *MockDatabase) GetAllForms(ctx context.Context) ([]*model.Form, error) {
+// GetAllUsers mocks base method.
+func (m *MockDatabase) GetAllUsers(ctx context.Context) ([]*model.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllForms", ctx)
+	ret := m.ctrl.Call(m, "GetAllUsers", ctx)
+	ret0, _ := ret[0].([]*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllUsers indicates an expected call of GetAllUsers.
+func (mr *MockDatabaseMockRecorder) GetAllUsers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUsers", reflect.TypeOf((*MockDatabase)(nil).GetAllUsers), ctx)
+}
+
+// GetBirthdayForms mocks base method.
+func (m *MockDatabase) GetBirthdayForms(ctx context.Context, month int, days ...int) ([]*model.Form, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, month}
+	for _, a := range days {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetBirthdayForms", varargs...)
 	ret0, _ := ret[0].([]*model.Form)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllForms indicates an expected call of GetAllForms
-func (mr *MockDatabaseMockRecorder) GetAllForms(ctx interface{}) *gomock.Call {
+// GetBirthdayForms indicates an expected call of GetBirthdayForms.
+func (mr *MockDatabaseMockRecorder) GetBirthdayForms(ctx, month interface{}, days ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllForms", reflect.TypeOf((*MockDatabase)(nil).GetAllForms), ctx)
+	varargs := append([]interface{}{ctx, month}, days...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBirthdayForms", reflect.TypeOf((*MockDatabase)(nil).GetBirthdayForms), varargs...)
 }
 
-// GetAllAcceptedFormsWithUser mocks base method
-func (m *MockDatabase) GetAllAcceptedFormsWithUser(ctx context.Context) ([]*model.Form, error) {
+// GetBotState mocks base method.
+func (m *MockDatabase) GetBotState(ctx context.Context, key string) (*model.BotState, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllAcceptedFormsWithUser", ctx)
+	ret := m.ctrl.Call(m, "GetBotState", ctx, key)
+	ret0, _ := ret[0].(*model.BotState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBotState indicates an expected call of GetBotState.
+func (mr *MockDatabaseMockRecorder) GetBotState(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBotState", reflect.TypeOf((*MockDatabase)(nil).GetBotState), ctx, key)
+}
+
+// GetDueMembershipPolls mocks base method.
+func (m *MockDatabase) GetDueMembershipPolls(ctx context.Context, createdBefore time.Time) ([]*model.MembershipPoll, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueMembershipPolls", ctx, createdBefore)
+	ret0, _ := ret[0].([]*model.MembershipPoll)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueMembershipPolls indicates an expected call of GetDueMembershipPolls.
+func (mr *MockDatabaseMockRecorder) GetDueMembershipPolls(ctx, createdBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueMembershipPolls", reflect.TypeOf((*MockDatabase)(nil).GetDueMembershipPolls), ctx, createdBefore)
+}
+
+// GetExpiredInviteLinks mocks base method.
+func (m *MockDatabase) GetExpiredInviteLinks(ctx context.Context, expiredBefore time.Time) ([]*model.InviteLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredInviteLinks", ctx, expiredBefore)
+	ret0, _ := ret[0].([]*model.InviteLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredInviteLinks indicates an expected call of GetExpiredInviteLinks.
+func (mr *MockDatabaseMockRecorder) GetExpiredInviteLinks(ctx, expiredBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredInviteLinks", reflect.TypeOf((*MockDatabase)(nil).GetExpiredInviteLinks), ctx, expiredBefore)
+}
+
+// GetFormByID mocks base method.
+func (m *MockDatabase) GetFormByID(ctx context.Context, id uint) (*model.Form, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFormByID", ctx, id)
+	ret0, _ := ret[0].(*model.Form)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFormByID indicates an expected call of GetFormByID.
+func (mr *MockDatabaseMockRecorder) GetFormByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFormByID", reflect.TypeOf((*MockDatabase)(nil).GetFormByID), ctx, id)
+}
+
+// GetFormDraft mocks base method.
+func (m *MockDatabase) GetFormDraft(ctx context.Context, telegramID int64) (*model.FormDraft, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFormDraft", ctx, telegramID)
+	ret0, _ := ret[0].(*model.FormDraft)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFormDraft indicates an expected call of GetFormDraft.
+func (mr *MockDatabaseMockRecorder) GetFormDraft(ctx, telegramID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFormDraft", reflect.TypeOf((*MockDatabase)(nil).GetFormDraft), ctx, telegramID)
+}
+
+// GetFormsByStatus mocks base method.
+func (m *MockDatabase) GetFormsByStatus(ctx context.Context, status string) ([]*model.Form, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFormsByStatus", ctx, status)
 	ret0, _ := ret[0].([]*model.Form)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllAcceptedFormsWithUser indicates an expected call of GetAllAcceptedFormsWithUser
-func (mr *MockDatabaseMockRecorder) GetAllAcceptedFormsWithUser(ctx interface{}) *gomock.Call {
+// GetFormsByStatus indicates an expected call of GetFormsByStatus.
+func (mr *MockDatabaseMockRecorder) GetFormsByStatus(ctx, status interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllAcceptedFormsWithUser", reflect.TypeOf((*MockDatabase)(nil).GetAllAcceptedFormsWithUser), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFormsByStatus", reflect.TypeOf((*MockDatabase)(nil).GetFormsByStatus), ctx, status)
+}
+
+// GetFormsDecidedBetween mocks base method.
+func (m *MockDatabase) GetFormsDecidedBetween(ctx context.Context, from, to time.Time) ([]*model.Form, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFormsDecidedBetween", ctx, from, to)
+	ret0, _ := ret[0].([]*model.Form)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFormsDecidedBetween indicates an expected call of GetFormsDecidedBetween.
+func (mr *MockDatabaseMockRecorder) GetFormsDecidedBetween(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFormsDecidedBetween", reflect.TypeOf((*MockDatabase)(nil).GetFormsDecidedBetween), ctx, from, to)
+}
+
+// GetInviteLinkByLink mocks base method.
+func (m *MockDatabase) GetInviteLinkByLink(ctx context.Context, link string) (*model.InviteLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInviteLinkByLink", ctx, link)
+	ret0, _ := ret[0].(*model.InviteLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInviteLinkByLink indicates an expected call of GetInviteLinkByLink.
+func (mr *MockDatabaseMockRecorder) GetInviteLinkByLink(ctx, link interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInviteLinkByLink", reflect.TypeOf((*MockDatabase)(nil).GetInviteLinkByLink), ctx, link)
+}
+
+// GetLastForm mocks base method.
+func (m *MockDatabase) GetLastForm(ctx context.Context, telegramID int64) (*model.Form, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastForm", ctx, telegramID)
+	ret0, _ := ret[0].(*model.Form)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastForm indicates an expected call of GetLastForm.
+func (mr *MockDatabaseMockRecorder) GetLastForm(ctx, telegramID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastForm", reflect.TypeOf((*MockDatabase)(nil).GetLastForm), ctx, telegramID)
+}
+
+// GetMembershipPollByMessage mocks base method.
+func (m *MockDatabase) GetMembershipPollByMessage(ctx context.Context, chatID int64, messageID int) (*model.MembershipPoll, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembershipPollByMessage", ctx, chatID, messageID)
+	ret0, _ := ret[0].(*model.MembershipPoll)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembershipPollByMessage indicates an expected call of GetMembershipPollByMessage.
+func (mr *MockDatabaseMockRecorder) GetMembershipPollByMessage(ctx, chatID, messageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembershipPollByMessage", reflect.TypeOf((*MockDatabase)(nil).GetMembershipPollByMessage), ctx, chatID, messageID)
+}
+
+// GetMembershipPollByPollID mocks base method.
+func (m *MockDatabase) GetMembershipPollByPollID(ctx context.Context, pollID string) (*model.MembershipPoll, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembershipPollByPollID", ctx, pollID)
+	ret0, _ := ret[0].(*model.MembershipPoll)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembershipPollByPollID indicates an expected call of GetMembershipPollByPollID.
+func (mr *MockDatabaseMockRecorder) GetMembershipPollByPollID(ctx, pollID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembershipPollByPollID", reflect.TypeOf((*MockDatabase)(nil).GetMembershipPollByPollID), ctx, pollID)
+}
+
+// GetMembershipPollsByUser mocks base method.
+func (m *MockDatabase) GetMembershipPollsByUser(ctx context.Context, telegramID int64) ([]*model.MembershipPoll, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembershipPollsByUser", ctx, telegramID)
+	ret0, _ := ret[0].([]*model.MembershipPoll)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembershipPollsByUser indicates an expected call of GetMembershipPollsByUser.
+func (mr *MockDatabaseMockRecorder) GetMembershipPollsByUser(ctx, telegramID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembershipPollsByUser", reflect.TypeOf((*MockDatabase)(nil).GetMembershipPollsByUser), ctx, telegramID)
+}
+
+// GetOpenMembershipPollByUser mocks base method.
+func (m *MockDatabase) GetOpenMembershipPollByUser(ctx context.Context, telegramID int64) (*model.MembershipPoll, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenMembershipPollByUser", ctx, telegramID)
+	ret0, _ := ret[0].(*model.MembershipPoll)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOpenMembershipPollByUser indicates an expected call of GetOpenMembershipPollByUser.
+func (mr *MockDatabaseMockRecorder) GetOpenMembershipPollByUser(ctx, telegramID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenMembershipPollByUser", reflect.TypeOf((*MockDatabase)(nil).GetOpenMembershipPollByUser), ctx, telegramID)
+}
+
+// GetOpenMembershipPolls mocks base method.
+func (m *MockDatabase) GetOpenMembershipPolls(ctx context.Context) ([]*model.MembershipPoll, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenMembershipPolls", ctx)
+	ret0, _ := ret[0].([]*model.MembershipPoll)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOpenMembershipPolls indicates an expected call of GetOpenMembershipPolls.
+func (mr *MockDatabaseMockRecorder) GetOpenMembershipPolls(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenMembershipPolls", reflect.TypeOf((*MockDatabase)(nil).GetOpenMembershipPolls), ctx)
+}
+
+// GetPendingForms mocks base method.
+func (m *MockDatabase) GetPendingForms(ctx context.Context, createdBefore time.Time) ([]*model.Form, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingForms", ctx, createdBefore)
+	ret0, _ := ret[0].([]*model.Form)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingForms indicates an expected call of GetPendingForms.
+func (mr *MockDatabaseMockRecorder) GetPendingForms(ctx, createdBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingForms", reflect.TypeOf((*MockDatabase)(nil).GetPendingForms), ctx, createdBefore)
+}
+
+// GetRoles mocks base method.
+func (m *MockDatabase) GetRoles(ctx context.Context) ([]*model.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoles", ctx)
+	ret0, _ := ret[0].([]*model.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoles indicates an expected call of GetRoles.
+func (mr *MockDatabaseMockRecorder) GetRoles(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoles", reflect.TypeOf((*MockDatabase)(nil).GetRoles), ctx)
+}
+
+// GetUserByID mocks base method.
+func (m *MockDatabase) GetUserByID(ctx context.Context, id uint) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, id)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockDatabaseMockRecorder) GetUserByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockDatabase)(nil).GetUserByID), ctx, id)
+}
+
+// GetUserByTelegramID mocks base method.
+func (m *MockDatabase) GetUserByTelegramID(ctx context.Context, telegramID int64) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByTelegramID", ctx, telegramID)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByTelegramID indicates an expected call of GetUserByTelegramID.
+func (mr *MockDatabaseMockRecorder) GetUserByTelegramID(ctx, telegramID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByTelegramID", reflect.TypeOf((*MockDatabase)(nil).GetUserByTelegramID), ctx, telegramID)
+}
+
+// GetUserByToken mocks base method.
+func (m *MockDatabase) GetUserByToken(ctx context.Context, token string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByToken", ctx, token)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByToken indicates an expected call of GetUserByToken.
+func (mr *MockDatabaseMockRecorder) GetUserByToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByToken", reflect.TypeOf((*MockDatabase)(nil).GetUserByToken), ctx, token)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockDatabase) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", ctx, username)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockDatabaseMockRecorder) GetUserByUsername(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockDatabase)(nil).GetUserByUsername), ctx, username)
+}
+
+// GetUserIDsWithPermission mocks base method.
+func (m *MockDatabase) GetUserIDsWithPermission(ctx context.Context, permission string) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIDsWithPermission", ctx, permission)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIDsWithPermission indicates an expected call of GetUserIDsWithPermission.
+func (mr *MockDatabaseMockRecorder) GetUserIDsWithPermission(ctx, permission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIDsWithPermission", reflect.TypeOf((*MockDatabase)(nil).GetUserIDsWithPermission), ctx, permission)
+}
+
+// GetUserRole mocks base method.
+func (m *MockDatabase) GetUserRole(ctx context.Context, telegramID int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserRole", ctx, telegramID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserRole indicates an expected call of GetUserRole.
+func (mr *MockDatabaseMockRecorder) GetUserRole(ctx, telegramID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRole", reflect.TypeOf((*MockDatabase)(nil).GetUserRole), ctx, telegramID)
+}
+
+// GetUsersJoinedBetween mocks base method.
+func (m *MockDatabase) GetUsersJoinedBetween(ctx context.Context, from, to time.Time) ([]*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersJoinedBetween", ctx, from, to)
+	ret0, _ := ret[0].([]*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersJoinedBetween indicates an expected call of GetUsersJoinedBetween.
+func (mr *MockDatabaseMockRecorder) GetUsersJoinedBetween(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersJoinedBetween", reflect.TypeOf((*MockDatabase)(nil).GetUsersJoinedBetween), ctx, from, to)
+}
+
+// GetUsersToRemind mocks base method.
+func (m *MockDatabase) GetUsersToRemind(ctx context.Context, createdAfter, createdBefore, remindedBefore time.Time, maxReminders int) ([]*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersToRemind", ctx, createdAfter, createdBefore, remindedBefore, maxReminders)
+	ret0, _ := ret[0].([]*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersToRemind indicates an expected call of GetUsersToRemind.
+func (mr *MockDatabaseMockRecorder) GetUsersToRemind(ctx, createdAfter, createdBefore, remindedBefore, maxReminders interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersToRemind", reflect.TypeOf((*MockDatabase)(nil).GetUsersToRemind), ctx, createdAfter, createdBefore, remindedBefore, maxReminders)
+}
+
+// IsUpdateProcessed mocks base method.
+func (m *MockDatabase) IsUpdateProcessed(ctx context.Context, updateID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsUpdateProcessed", ctx, updateID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsUpdateProcessed indicates an expected call of IsUpdateProcessed.
+func (mr *MockDatabaseMockRecorder) IsUpdateProcessed(ctx, updateID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsUpdateProcessed", reflect.TypeOf((*MockDatabase)(nil).IsUpdateProcessed), ctx, updateID)
+}
+
+// MarkInviteLinkUsed mocks base method.
+func (m *MockDatabase) MarkInviteLinkUsed(ctx context.Context, id uint) (*gen.ResultInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkInviteLinkUsed", ctx, id)
+	ret0, _ := ret[0].(*gen.ResultInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkInviteLinkUsed indicates an expected call of MarkInviteLinkUsed.
+func (mr *MockDatabaseMockRecorder) MarkInviteLinkUsed(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkInviteLinkUsed", reflect.TypeOf((*MockDatabase)(nil).MarkInviteLinkUsed), ctx, id)
+}
+
+// MarkOutboxMessageDead mocks base method.
+func (m *MockDatabase) MarkOutboxMessageDead(ctx context.Context, id uint, attempts int, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxMessageDead", ctx, id, attempts, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxMessageDead indicates an expected call of MarkOutboxMessageDead.
+func (mr *MockDatabaseMockRecorder) MarkOutboxMessageDead(ctx, id, attempts, lastError interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxMessageDead", reflect.TypeOf((*MockDatabase)(nil).MarkOutboxMessageDead), ctx, id, attempts, lastError)
+}
+
+// MarkOutboxMessageSent mocks base method.
+func (m *MockDatabase) MarkOutboxMessageSent(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxMessageSent", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxMessageSent indicates an expected call of MarkOutboxMessageSent.
+func (mr *MockDatabaseMockRecorder) MarkOutboxMessageSent(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxMessageSent", reflect.TypeOf((*MockDatabase)(nil).MarkOutboxMessageSent), ctx, id)
+}
+
+// MarkUpdateProcessed mocks base method.
+func (m *MockDatabase) MarkUpdateProcessed(ctx context.Context, updateID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUpdateProcessed", ctx, updateID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkUpdateProcessed indicates an expected call of MarkUpdateProcessed.
+func (mr *MockDatabaseMockRecorder) MarkUpdateProcessed(ctx, updateID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUpdateProcessed", reflect.TypeOf((*MockDatabase)(nil).MarkUpdateProcessed), ctx, updateID)
+}
+
+// MarkUserReminded mocks base method.
+func (m *MockDatabase) MarkUserReminded(ctx context.Context, telegramID int64, reminders int32) (*gen.ResultInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUserReminded", ctx, telegramID, reminders)
+	ret0, _ := ret[0].(*gen.ResultInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkUserReminded indicates an expected call of MarkUserReminded.
+func (mr *MockDatabaseMockRecorder) MarkUserReminded(ctx, telegramID, reminders interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUserReminded", reflect.TypeOf((*MockDatabase)(nil).MarkUserReminded), ctx, telegramID, reminders)
+}
+
+// RejectForm mocks base method.
+func (m *MockDatabase) RejectForm(ctx context.Context, id uint, reason string, decidedBy int64) (*gen.ResultInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectForm", ctx, id, reason, decidedBy)
+	ret0, _ := ret[0].(*gen.ResultInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectForm indicates an expected call of RejectForm.
+func (mr *MockDatabaseMockRecorder) RejectForm(ctx, id, reason, decidedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectForm", reflect.TypeOf((*MockDatabase)(nil).RejectForm), ctx, id, reason, decidedBy)
+}
+
+// RejectUser mocks base method.
+func (m *MockDatabase) RejectUser(ctx context.Context, id uint) (*gen.ResultInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectUser", ctx, id)
+	ret0, _ := ret[0].(*gen.ResultInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectUser indicates an expected call of RejectUser.
+func (mr *MockDatabaseMockRecorder) RejectUser(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectUser", reflect.TypeOf((*MockDatabase)(nil).RejectUser), ctx, id)
+}
+
+// ReplaceUserStatus mocks base method.
+func (m *MockDatabase) ReplaceUserStatus(ctx context.Context, id uint, from, to string) (*gen.ResultInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceUserStatus", ctx, id, from, to)
+	ret0, _ := ret[0].(*gen.ResultInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceUserStatus indicates an expected call of ReplaceUserStatus.
+func (mr *MockDatabaseMockRecorder) ReplaceUserStatus(ctx, id, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceUserStatus", reflect.TypeOf((*MockDatabase)(nil).ReplaceUserStatus), ctx, id, from, to)
+}
+
+// RescheduleOutboxMessage mocks base method.
+func (m *MockDatabase) RescheduleOutboxMessage(ctx context.Context, id uint, attempts int, nextAttemptAt time.Time, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RescheduleOutboxMessage", ctx, id, attempts, nextAttemptAt, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RescheduleOutboxMessage indicates an expected call of RescheduleOutboxMessage.
+func (mr *MockDatabaseMockRecorder) RescheduleOutboxMessage(ctx, id, attempts, nextAttemptAt, lastError interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleOutboxMessage", reflect.TypeOf((*MockDatabase)(nil).RescheduleOutboxMessage), ctx, id, attempts, nextAttemptAt, lastError)
+}
+
+// RevokeInviteLink mocks base method.
+func (m *MockDatabase) RevokeInviteLink(ctx context.Context, id uint) (*gen.ResultInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeInviteLink", ctx, id)
+	ret0, _ := ret[0].(*gen.ResultInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeInviteLink indicates an expected call of RevokeInviteLink.
+func (mr *MockDatabaseMockRecorder) RevokeInviteLink(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeInviteLink", reflect.TypeOf((*MockDatabase)(nil).RevokeInviteLink), ctx, id)
+}
+
+// SaveFormDraft mocks base method.
+func (m *MockDatabase) SaveFormDraft(ctx context.Context, draft *model.FormDraft) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFormDraft", ctx, draft)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveFormDraft indicates an expected call of SaveFormDraft.
+func (mr *MockDatabaseMockRecorder) SaveFormDraft(ctx, draft interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFormDraft", reflect.TypeOf((*MockDatabase)(nil).SaveFormDraft), ctx, draft)
+}
+
+// SavePollVote mocks base method.
+func (m *MockDatabase) SavePollVote(ctx context.Context, vote *model.PollVote) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePollVote", ctx, vote)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePollVote indicates an expected call of SavePollVote.
+func (mr *MockDatabaseMockRecorder) SavePollVote(ctx, vote interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePollVote", reflect.TypeOf((*MockDatabase)(nil).SavePollVote), ctx, vote)
+}
+
+// SearchAcceptedForms mocks base method.
+func (m *MockDatabase) SearchAcceptedForms(ctx context.Context, fragment string, offset, limit int) ([]*model.Form, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchAcceptedForms", ctx, fragment, offset, limit)
+	ret0, _ := ret[0].([]*model.Form)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchAcceptedForms indicates an expected call of SearchAcceptedForms.
+func (mr *MockDatabaseMockRecorder) SearchAcceptedForms(ctx, fragment, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAcceptedForms", reflect.TypeOf((*MockDatabase)(nil).SearchAcceptedForms), ctx, fragment, offset, limit)
+}
+
+// SearchUsers mocks base method.
+func (m *MockDatabase) SearchUsers(ctx context.Context, fragment string, limit int) ([]*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", ctx, fragment, limit)
+	ret0, _ := ret[0].([]*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockDatabaseMockRecorder) SearchUsers(ctx, fragment, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockDatabase)(nil).SearchUsers), ctx, fragment, limit)
+}
+
+// SetBirthdayOptOut mocks base method.
+func (m *MockDatabase) SetBirthdayOptOut(ctx context.Context, telegramID int64, optOut bool) (*gen.ResultInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBirthdayOptOut", ctx, telegramID, optOut)
+	ret0, _ := ret[0].(*gen.ResultInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetBirthdayOptOut indicates an expected call of SetBirthdayOptOut.
+func (mr *MockDatabaseMockRecorder) SetBirthdayOptOut(ctx, telegramID, optOut interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBirthdayOptOut", reflect.TypeOf((*MockDatabase)(nil).SetBirthdayOptOut), ctx, telegramID, optOut)
+}
+
+// SetBotState mocks base method.
+func (m *MockDatabase) SetBotState(ctx context.Context, key, value string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBotState", ctx, key, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBotState indicates an expected call of SetBotState.
+func (mr *MockDatabaseMockRecorder) SetBotState(ctx, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBotState", reflect.TypeOf((*MockDatabase)(nil).SetBotState), ctx, key, value)
+}
+
+// SetUserRole mocks base method.
+func (m *MockDatabase) SetUserRole(ctx context.Context, telegramID int64, role string, grantedBy int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", ctx, telegramID, role, grantedBy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockDatabaseMockRecorder) SetUserRole(ctx, telegramID, role, grantedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockDatabase)(nil).SetUserRole), ctx, telegramID, role, grantedBy)
+}
+
+// SetUserStatus mocks base method.
+func (m *MockDatabase) SetUserStatus(ctx context.Context, id uint, status string) (*gen.ResultInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserStatus", ctx, id, status)
+	ret0, _ := ret[0].(*gen.ResultInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserStatus indicates an expected call of SetUserStatus.
+func (mr *MockDatabaseMockRecorder) SetUserStatus(ctx, id, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserStatus", reflect.TypeOf((*MockDatabase)(nil).SetUserStatus), ctx, id, status)
+}
+
+// UnbanUser mocks base method.
+func (m *MockDatabase) UnbanUser(ctx context.Context, telegramID, unbannedBy int64) (*gen.ResultInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbanUser", ctx, telegramID, unbannedBy)
+	ret0, _ := ret[0].(*gen.ResultInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnbanUser indicates an expected call of UnbanUser.
+func (mr *MockDatabaseMockRecorder) UnbanUser(ctx, telegramID, unbannedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbanUser", reflect.TypeOf((*MockDatabase)(nil).UnbanUser), ctx, telegramID, unbannedBy)
+}
+
+// UpdateMembershipPollTally mocks base method.
+func (m *MockDatabase) UpdateMembershipPollTally(ctx context.Context, pollID string, acceptVotes, rejectVotes int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMembershipPollTally", ctx, pollID, acceptVotes, rejectVotes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMembershipPollTally indicates an expected call of UpdateMembershipPollTally.
+func (mr *MockDatabaseMockRecorder) UpdateMembershipPollTally(ctx, pollID, acceptVotes, rejectVotes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMembershipPollTally", reflect.TypeOf((*MockDatabase)(nil).UpdateMembershipPollTally), ctx, pollID, acceptVotes, rejectVotes)
+}
+
+// UpdateOrCreateUser mocks base method.
+func (m *MockDatabase) UpdateOrCreateUser(ctx context.Context, user *model.User) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrCreateUser", ctx, user)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOrCreateUser indicates an expected call of UpdateOrCreateUser.
+func (mr *MockDatabaseMockRecorder) UpdateOrCreateUser(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrCreateUser", reflect.TypeOf((*MockDatabase)(nil).UpdateOrCreateUser), ctx, user)
+}
+
+// UpdateUserByID mocks base method.
+func (m *MockDatabase) UpdateUserByID(ctx context.Context, id uint, user *model.User) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserByID", ctx, id, user)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserByID indicates an expected call of UpdateUserByID.
+func (mr *MockDatabaseMockRecorder) UpdateUserByID(ctx, id, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserByID", reflect.TypeOf((*MockDatabase)(nil).UpdateUserByID), ctx, id, user)
 }
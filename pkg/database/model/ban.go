@@ -0,0 +1,25 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const TableNameBan = "bans"
+
+// Ban is a record of banning the user, it's kept after the ban is lifted.
+type Ban struct {
+	gorm.Model
+	UserTelegramId int64   `gorm:"column:user_telegram_id; index" json:"user_telegram_id"`
+	User           User    `gorm:"foreignKey:UserTelegramId;references:TelegramID" json:"user"`
+	Reason         *string `gorm:"column:reason; type:text" json:"reason"`
+	BannedBy       int64   `gorm:"column:banned_by" json:"banned_by"`
+	// PreviousStatus is the user's status before the ban, it's restored when the ban is lifted.
+	PreviousStatus string     `gorm:"column:previous_status" json:"previous_status"`
+	UnbannedBy     *int64     `gorm:"column:unbanned_by" json:"unbanned_by"`
+	UnbannedAt     *time.Time `gorm:"column:unbanned_at" json:"unbanned_at"`
+}
+
+func (*Ban) TableName() string {
+	return TableNameBan
+}
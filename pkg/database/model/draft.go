@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+const TableNameFormDraft = "form_drafts"
+
+// FormDraft is a form being filled out in the bot chat, one per user.
+type FormDraft struct {
+	UserTelegramId int64 `gorm:"column:user_telegram_id; primaryKey; autoIncrement:false" json:"user_telegram_id"`
+	// Step is the name of the question the user is answering.
+	Step string `gorm:"column:step" json:"step"`
+	// Form is the JSON of the answers given so far.
+	Form      string    `gorm:"column:form; type:text" json:"form"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (*FormDraft) TableName() string {
+	return TableNameFormDraft
+}
@@ -1,6 +1,13 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"beneburg/pkg/utils"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
 
 const TableNameForm = "forms"
 
@@ -15,8 +22,13 @@ type Form struct {
 	UserTelegramId int64 `gorm:"column:user_telegram_id" json:"user_telegram_id"`
 	User           User  `gorm:"foreignKey:UserTelegramId;references:TelegramID" json:"user"`
 
-	Name        string  `gorm:"column:name" json:"name"`
-	Age         *int32  `gorm:"column:age" json:"age"`
+	Name string `gorm:"column:name" json:"name"`
+	// Age is entered by forms filled out before birthdates, use CurrentAge instead.
+	Age *int32 `gorm:"column:age" json:"age"`
+	// BirthDay and BirthMonth are set together, BirthYear is optional, so the age can stay private.
+	BirthDay    *int32  `gorm:"column:birth_day; index:birthday,priority:2" json:"birth_day"`
+	BirthMonth  *int32  `gorm:"column:birth_month; index:birthday,priority:1" json:"birth_month"`
+	BirthYear   *int32  `gorm:"column:birth_year" json:"birth_year"`
 	Gender      string  `gorm:"column:gender; type:enum('male', 'female', 'nonbinary', 'undefined');default:'undefined'" json:"gender"`
 	About       *string `gorm:"column:about" json:"about"`
 	Hobbies     *string `gorm:"column:hobbies" json:"hobbies"`
@@ -26,6 +38,11 @@ type Form struct {
 	Contacts    *string `gorm:"column:contacts" json:"contacts"`
 
 	Status string `gorm:"column:status; type:enum('new', 'accepted', 'rejected');default:'new'" json:"status"`
+	// RejectionReason is shown to the user, so they can fix the form and send it again.
+	RejectionReason *string `gorm:"column:rejection_reason; type:text" json:"rejection_reason"`
+	// DecidedBy and DecidedAt are set when a reviewer accepts or rejects the form.
+	DecidedBy *int64     `gorm:"column:decided_by" json:"decided_by"`
+	DecidedAt *time.Time `gorm:"column:decided_at" json:"decided_at"`
 }
 
 func (u *Form) RuGender() string {
@@ -44,9 +61,84 @@ func (u *Form) RuGender() string {
 	}
 }
 
+func RuFormStatus(status string) string {
+	switch status {
+	case FormStatusNew:
+		return "на проверке"
+	case FormStatusAccepted:
+		return "одобрена"
+	case FormStatusRejected:
+		return "отклонена"
+	default:
+		return status
+	}
+}
+
+// Birthdate returns "02.01.2006", or "02.01" without the year, empty if it's not set.
+func (u *Form) Birthdate() string {
+	if u == nil || u.BirthDay == nil || u.BirthMonth == nil {
+		return ""
+	}
+	if u.BirthYear == nil {
+		return fmt.Sprintf("%02d.%02d", *u.BirthDay, *u.BirthMonth)
+	}
+	return fmt.Sprintf("%02d.%02d.%d", *u.BirthDay, *u.BirthMonth, *u.BirthYear)
+}
+
+// SetBirthdate parses the format of Birthdate, an empty value clears the birthdate.
+func (u *Form) SetBirthdate(value string) error {
+	// A dot ending the sentence, like in "18.10.", doesn't start a year
+	value = strings.TrimSuffix(strings.TrimSpace(value), ".")
+	if value == "" {
+		u.BirthDay, u.BirthMonth, u.BirthYear = nil, nil, nil
+		return nil
+	}
+	withYear := strings.Count(value, ".") == 2
+	if !withYear {
+		// 2000 is a leap year, so February 29 is a valid birthday without the year
+		value += ".2000"
+	}
+	date, err := time.Parse("2.1.2006", value)
+	if err != nil {
+		return fmt.Errorf("invalid birthdate: %w", err)
+	}
+	if withYear && date.After(time.Now()) {
+		return errors.New("birthdate is in the future")
+	}
+	u.BirthDay = utils.GetAddress(int32(date.Day()))
+	u.BirthMonth = utils.GetAddress(int32(date.Month()))
+	u.BirthYear = nil
+	if withYear {
+		u.BirthYear = utils.GetAddress(int32(date.Year()))
+	}
+	return nil
+}
+
+// AgeAt returns the age on the date, nil if it's unknown. The age entered before birthdates
+// is returned as is.
+func (u *Form) AgeAt(now time.Time) *int32 {
+	if u == nil {
+		return nil
+	}
+	if u.BirthYear == nil || u.BirthMonth == nil || u.BirthDay == nil {
+		return u.Age
+	}
+	age := int32(now.Year()) - *u.BirthYear
+	if month := int32(now.Month()); month < *u.BirthMonth || month == *u.BirthMonth && int32(now.Day()) < *u.BirthDay {
+		age--
+	}
+	return &age
+}
+
+// CurrentAge is the age shown in the form, templates call it on every render.
+func (u *Form) CurrentAge() *int32 {
+	return u.AgeAt(time.Now())
+}
+
 const (
 	UserNameDescription        = "Имя"
 	UserAgeDescription         = "Возраст"
+	UserBirthdateDescription   = "День рождения"
 	UserGenderDescription      = "Пол"
 	UserAboutDescription       = "О себе"
 	UserHobbiesDescription     = "Хобби"
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const TableNameInviteLink = "invite_links"
+
+const (
+	InviteLinkStatusActive  = "active"
+	InviteLinkStatusUsed    = "used"
+	InviteLinkStatusRevoked = "revoked"
+)
+
+// InviteLink is a personal link to the group, it creates a join request, so the bot can
+// check that the request came through the link of the same user.
+type InviteLink struct {
+	gorm.Model
+	UserTelegramId int64      `gorm:"column:user_telegram_id; index" json:"user_telegram_id"`
+	User           User       `gorm:"foreignKey:UserTelegramId;references:TelegramID" json:"user"`
+	Link           string     `gorm:"column:link; size:255; uniqueIndex" json:"link"`
+	ExpireAt       time.Time  `gorm:"column:expire_at; index" json:"expire_at"`
+	Status         string     `gorm:"column:status; type:enum('active', 'used', 'revoked'); default:'active'" json:"status"`
+	UsedAt         *time.Time `gorm:"column:used_at" json:"used_at"`
+	RevokedAt      *time.Time `gorm:"column:revoked_at" json:"revoked_at"`
+}
+
+func (*InviteLink) TableName() string {
+	return TableNameInviteLink
+}
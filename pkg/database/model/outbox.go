@@ -0,0 +1,34 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const TableNameOutboxMessage = "outbox_messages"
+
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusDead    = "dead"
+)
+
+// OutboxMessage is an outgoing Telegram request stored until it is delivered.
+type OutboxMessage struct {
+	gorm.Model
+	ChatID int64 `gorm:"column:chat_id" json:"chat_id"`
+	// Type is the name of the tgbotapi config type the payload is decoded into.
+	Type    string `gorm:"column:type" json:"type"`
+	Payload string `gorm:"column:payload; type:text" json:"payload"`
+
+	Status        string    `gorm:"column:status; type:enum('pending', 'sent', 'dead'); default:'pending'; index:status_next_attempt_at,priority:1" json:"status"`
+	Attempts      int       `gorm:"column:attempts; default:0" json:"attempts"`
+	NextAttemptAt time.Time `gorm:"column:next_attempt_at; index:status_next_attempt_at,priority:2" json:"next_attempt_at"`
+	LastError     *string   `gorm:"column:last_error; type:text" json:"last_error"`
+	// DelayReason explains why the message waits for its next attempt, e.g. a parked chat.
+	DelayReason *string `gorm:"column:delay_reason" json:"delay_reason"`
+}
+
+func (*OutboxMessage) TableName() string {
+	return TableNameOutboxMessage
+}
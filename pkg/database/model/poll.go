@@ -0,0 +1,84 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const TableNameMembershipPoll = "membership_polls"
+
+const (
+	MembershipPollStatusOpen = "open"
+	// MembershipPollStatusEscalated is a stopped poll without a result, admins have to decide.
+	MembershipPollStatusEscalated = "escalated"
+	MembershipPollStatusClosed    = "closed"
+)
+
+const (
+	PollDecisionAccepted = "accepted"
+	PollDecisionRejected = "rejected"
+)
+
+// Options of the membership poll in the order they are sent.
+const (
+	PollOptionAccept = 0
+	PollOptionReject = 1
+)
+
+// MembershipPoll is the group poll about accepting the user who sent the form.
+type MembershipPoll struct {
+	gorm.Model
+	// PollID is the Telegram poll ID, poll and poll_answer updates refer to it.
+	PollID    string `gorm:"column:poll_id; size:64; uniqueIndex" json:"poll_id"`
+	ChatID    int64  `gorm:"column:chat_id; index:chat_message,priority:1" json:"chat_id"`
+	MessageID int    `gorm:"column:message_id; index:chat_message,priority:2" json:"message_id"`
+
+	FormID         uint  `gorm:"column:form_id" json:"form_id"`
+	Form           Form  `gorm:"foreignKey:FormID" json:"form"`
+	UserTelegramId int64 `gorm:"column:user_telegram_id; index" json:"user_telegram_id"`
+	User           User  `gorm:"foreignKey:UserTelegramId;references:TelegramID" json:"user"`
+
+	// AcceptVotes and RejectVotes are the tallies Telegram sent with the last poll update.
+	AcceptVotes int `gorm:"column:accept_votes; default:0" json:"accept_votes"`
+	RejectVotes int `gorm:"column:reject_votes; default:0" json:"reject_votes"`
+
+	Status    string     `gorm:"column:status; type:enum('open', 'escalated', 'closed'); default:'open'" json:"status"`
+	Decision  *string    `gorm:"column:decision; type:enum('accepted', 'rejected')" json:"decision"`
+	DecidedBy *int64     `gorm:"column:decided_by" json:"decided_by"`
+	ClosedAt  *time.Time `gorm:"column:closed_at" json:"closed_at"`
+}
+
+func (*MembershipPoll) TableName() string {
+	return TableNameMembershipPoll
+}
+
+func (p *MembershipPoll) TotalVotes() int {
+	return p.AcceptVotes + p.RejectVotes
+}
+
+func RuPollDecision(decision string) string {
+	switch decision {
+	case PollDecisionAccepted:
+		return "принят"
+	case PollDecisionRejected:
+		return "не принят"
+	default:
+		return decision
+	}
+}
+
+const TableNamePollVote = "poll_votes"
+
+// PollVote is the current answer of a voter. A retracted vote is deleted.
+type PollVote struct {
+	MembershipPollID uint           `gorm:"column:membership_poll_id; primaryKey; autoIncrement:false" json:"membership_poll_id"`
+	MembershipPoll   MembershipPoll `gorm:"foreignKey:MembershipPollID" json:"membership_poll"`
+	VoterTelegramId  int64          `gorm:"column:voter_telegram_id; primaryKey; autoIncrement:false" json:"voter_telegram_id"`
+	Option           int            `gorm:"column:option" json:"option"`
+	CreatedAt        time.Time      `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt        time.Time      `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (*PollVote) TableName() string {
+	return TableNamePollVote
+}
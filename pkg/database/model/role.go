@@ -0,0 +1,103 @@
+package model
+
+import "time"
+
+const TableNameRole = "roles"
+
+const (
+	RoleOwner     = "owner"
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+	// RoleMember is the role of everyone without a row in the roles table.
+	RoleMember = "member"
+)
+
+const (
+	PermissionReviewForms      = "review_forms"
+	PermissionDecideMembership = "decide_membership"
+	PermissionBan              = "ban"
+	PermissionManageRoles      = "manage_roles"
+)
+
+// Roles are ordered from the least to the most privileged.
+var Roles = []string{RoleMember, RoleModerator, RoleAdmin, RoleOwner}
+
+var rolePermissions = map[string][]string{
+	RoleOwner:     {PermissionReviewForms, PermissionDecideMembership, PermissionBan, PermissionManageRoles},
+	RoleAdmin:     {PermissionReviewForms, PermissionDecideMembership, PermissionBan, PermissionManageRoles},
+	RoleModerator: {PermissionReviewForms},
+	RoleMember:    {},
+}
+
+type Role struct {
+	UserTelegramId int64     `gorm:"column:user_telegram_id;primaryKey" json:"user_telegram_id"`
+	User           User      `gorm:"foreignKey:UserTelegramId;references:TelegramID" json:"user"`
+	Role           string    `gorm:"column:role; type:enum('owner', 'admin', 'moderator')" json:"role"`
+	GrantedBy      *int64    `gorm:"column:granted_by" json:"granted_by"`
+	CreatedAt      time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (*Role) TableName() string {
+	return TableNameRole
+}
+
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RoleRank returns the position of the role in Roles, -1 for unknown roles.
+func RoleRank(role string) int {
+	for i, r := range Roles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
+
+func HasPermission(role string, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RolesWithPermission returns all roles that have the permission.
+func RolesWithPermission(permission string) []string {
+	var roles []string
+	for _, role := range Roles {
+		if HasPermission(role, permission) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// CanManageRole reports whether a user with the granter role may grant or revoke the role.
+// Owners manage every role, others only roles below their own.
+func CanManageRole(granter string, role string) bool {
+	if !HasPermission(granter, PermissionManageRoles) {
+		return false
+	}
+	if granter == RoleOwner {
+		return true
+	}
+	return RoleRank(role) < RoleRank(granter)
+}
+
+func RuRole(role string) string {
+	switch role {
+	case RoleOwner:
+		return "владелец"
+	case RoleAdmin:
+		return "администратор"
+	case RoleModerator:
+		return "модератор"
+	default:
+		return "участник"
+	}
+}
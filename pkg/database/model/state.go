@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+const TableNameBotState = "bot_states"
+
+// BotState is a key-value record for the bot's own state that has to survive restarts.
+type BotState struct {
+	Key       string    `gorm:"column:key;primaryKey;size:64" json:"key"`
+	Value     string    `gorm:"column:value" json:"value"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (*BotState) TableName() string {
+	return TableNameBotState
+}
+
+const TableNameProcessedUpdate = "processed_updates"
+
+// ProcessedUpdate marks a Telegram update as processed, so a redelivered update is skipped.
+type ProcessedUpdate struct {
+	UpdateID  int       `gorm:"column:update_id;primaryKey;autoIncrement:false" json:"update_id"`
+	CreatedAt time.Time `gorm:"column:created_at;index" json:"created_at"`
+}
+
+func (*ProcessedUpdate) TableName() string {
+	return TableNameProcessedUpdate
+}
@@ -1,6 +1,9 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"gorm.io/gorm"
+	"time"
+)
 
 const TableNameUser = "users"
 
@@ -22,6 +25,13 @@ type User struct {
 	LastName   *string `gorm:"column:last_name" json:"last_name"`
 
 	Status string `gorm:"column:status; type:enum('new', 'active', 'not_active', 'accepted', 'rejected', 'bot', 'banned'); default:'new'" json:"status"`
+	// BirthdayOptOut turns off the birthday greetings in the group.
+	BirthdayOptOut bool `gorm:"column:birthday_opt_out; default:false" json:"birthday_opt_out"`
+	// ApplyReminders counts the reminders to fill out the form sent to a new user.
+	ApplyReminders  int32      `gorm:"column:apply_reminders; default:0" json:"apply_reminders"`
+	ApplyRemindedAt *time.Time `gorm:"column:apply_reminded_at" json:"apply_reminded_at"`
+	// JoinedAt is the last time the user joined the group, nil for members who joined before it was recorded.
+	JoinedAt *time.Time `gorm:"column:joined_at; index:joined_at" json:"joined_at"`
 }
 
 func (*User) TableName() string {
@@ -32,3 +42,24 @@ const (
 	UserTelegramIDDescription = "Telegram ID"
 	UserUsernameDescription   = "Username"
 )
+
+func RuUserStatus(status string) string {
+	switch status {
+	case UserStatusNew:
+		return "новый"
+	case UserStatusActive:
+		return "участник чата"
+	case UserStatusNotActive:
+		return "вышел из чата"
+	case UserStatusAccepted:
+		return "принят, ещё не вступил в чат"
+	case UserStatusRejected:
+		return "не принят"
+	case UserStatusBot:
+		return "бот"
+	case UserStatusBanned:
+		return "заблокирован"
+	default:
+		return status
+	}
+}
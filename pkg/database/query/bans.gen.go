@@ -0,0 +1,429 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"beneburg/pkg/database/model"
+)
+
+func newBan(db *gorm.DB) ban {
+	_ban := ban{}
+
+	_ban.banDo.UseDB(db)
+	_ban.banDo.UseModel(&model.Ban{})
+
+	tableName := _ban.banDo.TableName()
+	_ban.ALL = field.NewAsterisk(tableName)
+	_ban.ID = field.NewUint(tableName, "id")
+	_ban.CreatedAt = field.NewTime(tableName, "created_at")
+	_ban.UpdatedAt = field.NewTime(tableName, "updated_at")
+	_ban.DeletedAt = field.NewField(tableName, "deleted_at")
+	_ban.UserTelegramId = field.NewInt64(tableName, "user_telegram_id")
+	_ban.Reason = field.NewString(tableName, "reason")
+	_ban.BannedBy = field.NewInt64(tableName, "banned_by")
+	_ban.PreviousStatus = field.NewString(tableName, "previous_status")
+	_ban.UnbannedBy = field.NewInt64(tableName, "unbanned_by")
+	_ban.UnbannedAt = field.NewTime(tableName, "unbanned_at")
+	_ban.User = banBelongsToUser{
+		db: db.Session(&gorm.Session{}),
+
+		RelationField: field.NewRelation("User", "model.User"),
+	}
+
+	_ban.fillFieldMap()
+
+	return _ban
+}
+
+type ban struct {
+	banDo banDo
+
+	ALL            field.Asterisk
+	ID             field.Uint
+	CreatedAt      field.Time
+	UpdatedAt      field.Time
+	DeletedAt      field.Field
+	UserTelegramId field.Int64
+	Reason         field.String
+	BannedBy       field.Int64
+	PreviousStatus field.String
+	UnbannedBy     field.Int64
+	UnbannedAt     field.Time
+	User           banBelongsToUser
+
+	fieldMap map[string]field.Expr
+}
+
+func (b ban) Table(newTableName string) *ban {
+	b.banDo.UseTable(newTableName)
+	return b.updateTableName(newTableName)
+}
+
+func (b ban) As(alias string) *ban {
+	b.banDo.DO = *(b.banDo.As(alias).(*gen.DO))
+	return b.updateTableName(alias)
+}
+
+func (b *ban) updateTableName(table string) *ban {
+	b.ALL = field.NewAsterisk(table)
+	b.ID = field.NewUint(table, "id")
+	b.CreatedAt = field.NewTime(table, "created_at")
+	b.UpdatedAt = field.NewTime(table, "updated_at")
+	b.DeletedAt = field.NewField(table, "deleted_at")
+	b.UserTelegramId = field.NewInt64(table, "user_telegram_id")
+	b.Reason = field.NewString(table, "reason")
+	b.BannedBy = field.NewInt64(table, "banned_by")
+	b.PreviousStatus = field.NewString(table, "previous_status")
+	b.UnbannedBy = field.NewInt64(table, "unbanned_by")
+	b.UnbannedAt = field.NewTime(table, "unbanned_at")
+
+	b.fillFieldMap()
+
+	return b
+}
+
+func (b *ban) WithContext(ctx context.Context) *banDo { return b.banDo.WithContext(ctx) }
+
+func (b ban) TableName() string { return b.banDo.TableName() }
+
+func (b ban) Alias() string { return b.banDo.Alias() }
+
+func (b *ban) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := b.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (b *ban) fillFieldMap() {
+	b.fieldMap = make(map[string]field.Expr, 11)
+	b.fieldMap["id"] = b.ID
+	b.fieldMap["created_at"] = b.CreatedAt
+	b.fieldMap["updated_at"] = b.UpdatedAt
+	b.fieldMap["deleted_at"] = b.DeletedAt
+	b.fieldMap["user_telegram_id"] = b.UserTelegramId
+	b.fieldMap["reason"] = b.Reason
+	b.fieldMap["banned_by"] = b.BannedBy
+	b.fieldMap["previous_status"] = b.PreviousStatus
+	b.fieldMap["unbanned_by"] = b.UnbannedBy
+	b.fieldMap["unbanned_at"] = b.UnbannedAt
+
+}
+
+func (b ban) clone(db *gorm.DB) ban {
+	b.banDo.ReplaceDB(db)
+	return b
+}
+
+type banBelongsToUser struct {
+	db *gorm.DB
+
+	field.RelationField
+}
+
+func (a banBelongsToUser) Where(conds ...field.Expr) *banBelongsToUser {
+	if len(conds) == 0 {
+		return &a
+	}
+
+	exprs := make([]clause.Expression, 0, len(conds))
+	for _, cond := range conds {
+		exprs = append(exprs, cond.BeCond().(clause.Expression))
+	}
+	a.db = a.db.Clauses(clause.Where{Exprs: exprs})
+	return &a
+}
+
+func (a banBelongsToUser) WithContext(ctx context.Context) *banBelongsToUser {
+	a.db = a.db.WithContext(ctx)
+	return &a
+}
+
+func (a banBelongsToUser) Model(m *model.Ban) *banBelongsToUserTx {
+	return &banBelongsToUserTx{a.db.Model(m).Association(a.Name())}
+}
+
+type banBelongsToUserTx struct{ tx *gorm.Association }
+
+func (a banBelongsToUserTx) Find() (result *model.User, err error) {
+	return result, a.tx.Find(&result)
+}
+
+func (a banBelongsToUserTx) Append(values ...*model.User) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Append(targetValues...)
+}
+
+func (a banBelongsToUserTx) Replace(values ...*model.User) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Replace(targetValues...)
+}
+
+func (a banBelongsToUserTx) Delete(values ...*model.User) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Delete(targetValues...)
+}
+
+func (a banBelongsToUserTx) Clear() error {
+	return a.tx.Clear()
+}
+
+func (a banBelongsToUserTx) Count() int64 {
+	return a.tx.Count()
+}
+
+type banDo struct{ gen.DO }
+
+func (b banDo) Debug() *banDo {
+	return b.withDO(b.DO.Debug())
+}
+
+func (b banDo) WithContext(ctx context.Context) *banDo {
+	return b.withDO(b.DO.WithContext(ctx))
+}
+
+func (b banDo) ReadDB() *banDo {
+	return b.Clauses(dbresolver.Read)
+}
+
+func (b banDo) WriteDB() *banDo {
+	return b.Clauses(dbresolver.Write)
+}
+
+func (b banDo) Clauses(conds ...clause.Expression) *banDo {
+	return b.withDO(b.DO.Clauses(conds...))
+}
+
+func (b banDo) Returning(value interface{}, columns ...string) *banDo {
+	return b.withDO(b.DO.Returning(value, columns...))
+}
+
+func (b banDo) Not(conds ...gen.Condition) *banDo {
+	return b.withDO(b.DO.Not(conds...))
+}
+
+func (b banDo) Or(conds ...gen.Condition) *banDo {
+	return b.withDO(b.DO.Or(conds...))
+}
+
+func (b banDo) Select(conds ...field.Expr) *banDo {
+	return b.withDO(b.DO.Select(conds...))
+}
+
+func (b banDo) Where(conds ...gen.Condition) *banDo {
+	return b.withDO(b.DO.Where(conds...))
+}
+
+func (b banDo) Exists(subquery interface{ UnderlyingDB() *gorm.DB }) *banDo {
+	return b.Where(field.CompareSubQuery(field.ExistsOp, nil, subquery.UnderlyingDB()))
+}
+
+func (b banDo) Order(conds ...field.Expr) *banDo {
+	return b.withDO(b.DO.Order(conds...))
+}
+
+func (b banDo) Distinct(cols ...field.Expr) *banDo {
+	return b.withDO(b.DO.Distinct(cols...))
+}
+
+func (b banDo) Omit(cols ...field.Expr) *banDo {
+	return b.withDO(b.DO.Omit(cols...))
+}
+
+func (b banDo) Join(table schema.Tabler, on ...field.Expr) *banDo {
+	return b.withDO(b.DO.Join(table, on...))
+}
+
+func (b banDo) LeftJoin(table schema.Tabler, on ...field.Expr) *banDo {
+	return b.withDO(b.DO.LeftJoin(table, on...))
+}
+
+func (b banDo) RightJoin(table schema.Tabler, on ...field.Expr) *banDo {
+	return b.withDO(b.DO.RightJoin(table, on...))
+}
+
+func (b banDo) Group(cols ...field.Expr) *banDo {
+	return b.withDO(b.DO.Group(cols...))
+}
+
+func (b banDo) Having(conds ...gen.Condition) *banDo {
+	return b.withDO(b.DO.Having(conds...))
+}
+
+func (b banDo) Limit(limit int) *banDo {
+	return b.withDO(b.DO.Limit(limit))
+}
+
+func (b banDo) Offset(offset int) *banDo {
+	return b.withDO(b.DO.Offset(offset))
+}
+
+func (b banDo) Scopes(funcs ...func(gen.Dao) gen.Dao) *banDo {
+	return b.withDO(b.DO.Scopes(funcs...))
+}
+
+func (b banDo) Unscoped() *banDo {
+	return b.withDO(b.DO.Unscoped())
+}
+
+func (b banDo) Create(values ...*model.Ban) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return b.DO.Create(values)
+}
+
+func (b banDo) CreateInBatches(values []*model.Ban, batchSize int) error {
+	return b.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (b banDo) Save(values ...*model.Ban) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return b.DO.Save(values)
+}
+
+func (b banDo) First() (*model.Ban, error) {
+	if result, err := b.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Ban), nil
+	}
+}
+
+func (b banDo) Take() (*model.Ban, error) {
+	if result, err := b.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Ban), nil
+	}
+}
+
+func (b banDo) Last() (*model.Ban, error) {
+	if result, err := b.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Ban), nil
+	}
+}
+
+func (b banDo) Find() ([]*model.Ban, error) {
+	result, err := b.DO.Find()
+	return result.([]*model.Ban), err
+}
+
+func (b banDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.Ban, err error) {
+	buf := make([]*model.Ban, 0, batchSize)
+	err = b.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (b banDo) FindInBatches(result *[]*model.Ban, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return b.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (b banDo) Attrs(attrs ...field.AssignExpr) *banDo {
+	return b.withDO(b.DO.Attrs(attrs...))
+}
+
+func (b banDo) Assign(attrs ...field.AssignExpr) *banDo {
+	return b.withDO(b.DO.Assign(attrs...))
+}
+
+func (b banDo) Joins(fields ...field.RelationField) *banDo {
+	for _, _f := range fields {
+		b = *b.withDO(b.DO.Joins(_f))
+	}
+	return &b
+}
+
+func (b banDo) Preload(fields ...field.RelationField) *banDo {
+	for _, _f := range fields {
+		b = *b.withDO(b.DO.Preload(_f))
+	}
+	return &b
+}
+
+func (b banDo) FirstOrInit() (*model.Ban, error) {
+	if result, err := b.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Ban), nil
+	}
+}
+
+func (b banDo) FirstOrCreate() (*model.Ban, error) {
+	if result, err := b.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Ban), nil
+	}
+}
+
+func (b banDo) FindByPage(offset int, limit int) (result []*model.Ban, count int64, err error) {
+	result, err = b.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = b.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (b banDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = b.Count()
+	if err != nil {
+		return
+	}
+
+	err = b.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (b banDo) Scan(result interface{}) (err error) {
+	return b.DO.Scan(result)
+}
+
+func (b banDo) Delete(models ...*model.Ban) (result gen.ResultInfo, err error) {
+	return b.DO.Delete(models)
+}
+
+func (b *banDo) withDO(do gen.Dao) *banDo {
+	b.DO = *do.(*gen.DO)
+	return b
+}
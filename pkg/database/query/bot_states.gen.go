@@ -0,0 +1,328 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"beneburg/pkg/database/model"
+)
+
+func newBotState(db *gorm.DB) botState {
+	_botState := botState{}
+
+	_botState.botStateDo.UseDB(db)
+	_botState.botStateDo.UseModel(&model.BotState{})
+
+	tableName := _botState.botStateDo.TableName()
+	_botState.ALL = field.NewAsterisk(tableName)
+	_botState.Key = field.NewString(tableName, "key")
+	_botState.Value = field.NewString(tableName, "value")
+	_botState.UpdatedAt = field.NewTime(tableName, "updated_at")
+
+	_botState.fillFieldMap()
+
+	return _botState
+}
+
+type botState struct {
+	botStateDo botStateDo
+
+	ALL       field.Asterisk
+	Key       field.String
+	Value     field.String
+	UpdatedAt field.Time
+
+	fieldMap map[string]field.Expr
+}
+
+func (b botState) Table(newTableName string) *botState {
+	b.botStateDo.UseTable(newTableName)
+	return b.updateTableName(newTableName)
+}
+
+func (b botState) As(alias string) *botState {
+	b.botStateDo.DO = *(b.botStateDo.As(alias).(*gen.DO))
+	return b.updateTableName(alias)
+}
+
+func (b *botState) updateTableName(table string) *botState {
+	b.ALL = field.NewAsterisk(table)
+	b.Key = field.NewString(table, "key")
+	b.Value = field.NewString(table, "value")
+	b.UpdatedAt = field.NewTime(table, "updated_at")
+
+	b.fillFieldMap()
+
+	return b
+}
+
+func (b *botState) WithContext(ctx context.Context) *botStateDo { return b.botStateDo.WithContext(ctx) }
+
+func (b botState) TableName() string { return b.botStateDo.TableName() }
+
+func (b botState) Alias() string { return b.botStateDo.Alias() }
+
+func (b *botState) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := b.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (b *botState) fillFieldMap() {
+	b.fieldMap = make(map[string]field.Expr, 3)
+	b.fieldMap["key"] = b.Key
+	b.fieldMap["value"] = b.Value
+	b.fieldMap["updated_at"] = b.UpdatedAt
+}
+
+func (b botState) clone(db *gorm.DB) botState {
+	b.botStateDo.ReplaceDB(db)
+	return b
+}
+
+type botStateDo struct{ gen.DO }
+
+func (b botStateDo) Debug() *botStateDo {
+	return b.withDO(b.DO.Debug())
+}
+
+func (b botStateDo) WithContext(ctx context.Context) *botStateDo {
+	return b.withDO(b.DO.WithContext(ctx))
+}
+
+func (b botStateDo) ReadDB() *botStateDo {
+	return b.Clauses(dbresolver.Read)
+}
+
+func (b botStateDo) WriteDB() *botStateDo {
+	return b.Clauses(dbresolver.Write)
+}
+
+func (b botStateDo) Clauses(conds ...clause.Expression) *botStateDo {
+	return b.withDO(b.DO.Clauses(conds...))
+}
+
+func (b botStateDo) Returning(value interface{}, columns ...string) *botStateDo {
+	return b.withDO(b.DO.Returning(value, columns...))
+}
+
+func (b botStateDo) Not(conds ...gen.Condition) *botStateDo {
+	return b.withDO(b.DO.Not(conds...))
+}
+
+func (b botStateDo) Or(conds ...gen.Condition) *botStateDo {
+	return b.withDO(b.DO.Or(conds...))
+}
+
+func (b botStateDo) Select(conds ...field.Expr) *botStateDo {
+	return b.withDO(b.DO.Select(conds...))
+}
+
+func (b botStateDo) Where(conds ...gen.Condition) *botStateDo {
+	return b.withDO(b.DO.Where(conds...))
+}
+
+func (b botStateDo) Exists(subquery interface{ UnderlyingDB() *gorm.DB }) *botStateDo {
+	return b.Where(field.CompareSubQuery(field.ExistsOp, nil, subquery.UnderlyingDB()))
+}
+
+func (b botStateDo) Order(conds ...field.Expr) *botStateDo {
+	return b.withDO(b.DO.Order(conds...))
+}
+
+func (b botStateDo) Distinct(cols ...field.Expr) *botStateDo {
+	return b.withDO(b.DO.Distinct(cols...))
+}
+
+func (b botStateDo) Omit(cols ...field.Expr) *botStateDo {
+	return b.withDO(b.DO.Omit(cols...))
+}
+
+func (b botStateDo) Join(table schema.Tabler, on ...field.Expr) *botStateDo {
+	return b.withDO(b.DO.Join(table, on...))
+}
+
+func (b botStateDo) LeftJoin(table schema.Tabler, on ...field.Expr) *botStateDo {
+	return b.withDO(b.DO.LeftJoin(table, on...))
+}
+
+func (b botStateDo) RightJoin(table schema.Tabler, on ...field.Expr) *botStateDo {
+	return b.withDO(b.DO.RightJoin(table, on...))
+}
+
+func (b botStateDo) Group(cols ...field.Expr) *botStateDo {
+	return b.withDO(b.DO.Group(cols...))
+}
+
+func (b botStateDo) Having(conds ...gen.Condition) *botStateDo {
+	return b.withDO(b.DO.Having(conds...))
+}
+
+func (b botStateDo) Limit(limit int) *botStateDo {
+	return b.withDO(b.DO.Limit(limit))
+}
+
+func (b botStateDo) Offset(offset int) *botStateDo {
+	return b.withDO(b.DO.Offset(offset))
+}
+
+func (b botStateDo) Scopes(funcs ...func(gen.Dao) gen.Dao) *botStateDo {
+	return b.withDO(b.DO.Scopes(funcs...))
+}
+
+func (b botStateDo) Unscoped() *botStateDo {
+	return b.withDO(b.DO.Unscoped())
+}
+
+func (b botStateDo) Create(values ...*model.BotState) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return b.DO.Create(values)
+}
+
+func (b botStateDo) CreateInBatches(values []*model.BotState, batchSize int) error {
+	return b.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (b botStateDo) Save(values ...*model.BotState) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return b.DO.Save(values)
+}
+
+func (b botStateDo) First() (*model.BotState, error) {
+	if result, err := b.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.BotState), nil
+	}
+}
+
+func (b botStateDo) Take() (*model.BotState, error) {
+	if result, err := b.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.BotState), nil
+	}
+}
+
+func (b botStateDo) Last() (*model.BotState, error) {
+	if result, err := b.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.BotState), nil
+	}
+}
+
+func (b botStateDo) Find() ([]*model.BotState, error) {
+	result, err := b.DO.Find()
+	return result.([]*model.BotState), err
+}
+
+func (b botStateDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.BotState, err error) {
+	buf := make([]*model.BotState, 0, batchSize)
+	err = b.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (b botStateDo) FindInBatches(result *[]*model.BotState, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return b.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (b botStateDo) Attrs(attrs ...field.AssignExpr) *botStateDo {
+	return b.withDO(b.DO.Attrs(attrs...))
+}
+
+func (b botStateDo) Assign(attrs ...field.AssignExpr) *botStateDo {
+	return b.withDO(b.DO.Assign(attrs...))
+}
+
+func (b botStateDo) Joins(fields ...field.RelationField) *botStateDo {
+	for _, _f := range fields {
+		b = *b.withDO(b.DO.Joins(_f))
+	}
+	return &b
+}
+
+func (b botStateDo) Preload(fields ...field.RelationField) *botStateDo {
+	for _, _f := range fields {
+		b = *b.withDO(b.DO.Preload(_f))
+	}
+	return &b
+}
+
+func (b botStateDo) FirstOrInit() (*model.BotState, error) {
+	if result, err := b.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.BotState), nil
+	}
+}
+
+func (b botStateDo) FirstOrCreate() (*model.BotState, error) {
+	if result, err := b.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.BotState), nil
+	}
+}
+
+func (b botStateDo) FindByPage(offset int, limit int) (result []*model.BotState, count int64, err error) {
+	result, err = b.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = b.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (b botStateDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = b.Count()
+	if err != nil {
+		return
+	}
+
+	err = b.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (b botStateDo) Scan(result interface{}) (err error) {
+	return b.DO.Scan(result)
+}
+
+func (b botStateDo) Delete(models ...*model.BotState) (result gen.ResultInfo, err error) {
+	return b.DO.Delete(models)
+}
+
+func (b *botStateDo) withDO(do gen.Dao) *botStateDo {
+	b.DO = *do.(*gen.DO)
+	return b
+}
@@ -0,0 +1,338 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"beneburg/pkg/database/model"
+)
+
+func newFormDraft(db *gorm.DB) formDraft {
+	_formDraft := formDraft{}
+
+	_formDraft.formDraftDo.UseDB(db)
+	_formDraft.formDraftDo.UseModel(&model.FormDraft{})
+
+	tableName := _formDraft.formDraftDo.TableName()
+	_formDraft.ALL = field.NewAsterisk(tableName)
+	_formDraft.UserTelegramId = field.NewInt64(tableName, "user_telegram_id")
+	_formDraft.Step = field.NewString(tableName, "step")
+	_formDraft.Form = field.NewString(tableName, "form")
+	_formDraft.CreatedAt = field.NewTime(tableName, "created_at")
+	_formDraft.UpdatedAt = field.NewTime(tableName, "updated_at")
+
+	_formDraft.fillFieldMap()
+
+	return _formDraft
+}
+
+type formDraft struct {
+	formDraftDo formDraftDo
+
+	ALL            field.Asterisk
+	UserTelegramId field.Int64
+	Step           field.String
+	Form           field.String
+	CreatedAt      field.Time
+	UpdatedAt      field.Time
+
+	fieldMap map[string]field.Expr
+}
+
+func (f formDraft) Table(newTableName string) *formDraft {
+	f.formDraftDo.UseTable(newTableName)
+	return f.updateTableName(newTableName)
+}
+
+func (f formDraft) As(alias string) *formDraft {
+	f.formDraftDo.DO = *(f.formDraftDo.As(alias).(*gen.DO))
+	return f.updateTableName(alias)
+}
+
+func (f *formDraft) updateTableName(table string) *formDraft {
+	f.ALL = field.NewAsterisk(table)
+	f.UserTelegramId = field.NewInt64(table, "user_telegram_id")
+	f.Step = field.NewString(table, "step")
+	f.Form = field.NewString(table, "form")
+	f.CreatedAt = field.NewTime(table, "created_at")
+	f.UpdatedAt = field.NewTime(table, "updated_at")
+
+	f.fillFieldMap()
+
+	return f
+}
+
+func (f *formDraft) WithContext(ctx context.Context) *formDraftDo {
+	return f.formDraftDo.WithContext(ctx)
+}
+
+func (f formDraft) TableName() string { return f.formDraftDo.TableName() }
+
+func (f formDraft) Alias() string { return f.formDraftDo.Alias() }
+
+func (f *formDraft) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := f.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (f *formDraft) fillFieldMap() {
+	f.fieldMap = make(map[string]field.Expr, 5)
+	f.fieldMap["user_telegram_id"] = f.UserTelegramId
+	f.fieldMap["step"] = f.Step
+	f.fieldMap["form"] = f.Form
+	f.fieldMap["created_at"] = f.CreatedAt
+	f.fieldMap["updated_at"] = f.UpdatedAt
+}
+
+func (f formDraft) clone(db *gorm.DB) formDraft {
+	f.formDraftDo.ReplaceDB(db)
+	return f
+}
+
+type formDraftDo struct{ gen.DO }
+
+func (f formDraftDo) Debug() *formDraftDo {
+	return f.withDO(f.DO.Debug())
+}
+
+func (f formDraftDo) WithContext(ctx context.Context) *formDraftDo {
+	return f.withDO(f.DO.WithContext(ctx))
+}
+
+func (f formDraftDo) ReadDB() *formDraftDo {
+	return f.Clauses(dbresolver.Read)
+}
+
+func (f formDraftDo) WriteDB() *formDraftDo {
+	return f.Clauses(dbresolver.Write)
+}
+
+func (f formDraftDo) Clauses(conds ...clause.Expression) *formDraftDo {
+	return f.withDO(f.DO.Clauses(conds...))
+}
+
+func (f formDraftDo) Returning(value interface{}, columns ...string) *formDraftDo {
+	return f.withDO(f.DO.Returning(value, columns...))
+}
+
+func (f formDraftDo) Not(conds ...gen.Condition) *formDraftDo {
+	return f.withDO(f.DO.Not(conds...))
+}
+
+func (f formDraftDo) Or(conds ...gen.Condition) *formDraftDo {
+	return f.withDO(f.DO.Or(conds...))
+}
+
+func (f formDraftDo) Select(conds ...field.Expr) *formDraftDo {
+	return f.withDO(f.DO.Select(conds...))
+}
+
+func (f formDraftDo) Where(conds ...gen.Condition) *formDraftDo {
+	return f.withDO(f.DO.Where(conds...))
+}
+
+func (f formDraftDo) Exists(subquery interface{ UnderlyingDB() *gorm.DB }) *formDraftDo {
+	return f.Where(field.CompareSubQuery(field.ExistsOp, nil, subquery.UnderlyingDB()))
+}
+
+func (f formDraftDo) Order(conds ...field.Expr) *formDraftDo {
+	return f.withDO(f.DO.Order(conds...))
+}
+
+func (f formDraftDo) Distinct(cols ...field.Expr) *formDraftDo {
+	return f.withDO(f.DO.Distinct(cols...))
+}
+
+func (f formDraftDo) Omit(cols ...field.Expr) *formDraftDo {
+	return f.withDO(f.DO.Omit(cols...))
+}
+
+func (f formDraftDo) Join(table schema.Tabler, on ...field.Expr) *formDraftDo {
+	return f.withDO(f.DO.Join(table, on...))
+}
+
+func (f formDraftDo) LeftJoin(table schema.Tabler, on ...field.Expr) *formDraftDo {
+	return f.withDO(f.DO.LeftJoin(table, on...))
+}
+
+func (f formDraftDo) RightJoin(table schema.Tabler, on ...field.Expr) *formDraftDo {
+	return f.withDO(f.DO.RightJoin(table, on...))
+}
+
+func (f formDraftDo) Group(cols ...field.Expr) *formDraftDo {
+	return f.withDO(f.DO.Group(cols...))
+}
+
+func (f formDraftDo) Having(conds ...gen.Condition) *formDraftDo {
+	return f.withDO(f.DO.Having(conds...))
+}
+
+func (f formDraftDo) Limit(limit int) *formDraftDo {
+	return f.withDO(f.DO.Limit(limit))
+}
+
+func (f formDraftDo) Offset(offset int) *formDraftDo {
+	return f.withDO(f.DO.Offset(offset))
+}
+
+func (f formDraftDo) Scopes(funcs ...func(gen.Dao) gen.Dao) *formDraftDo {
+	return f.withDO(f.DO.Scopes(funcs...))
+}
+
+func (f formDraftDo) Unscoped() *formDraftDo {
+	return f.withDO(f.DO.Unscoped())
+}
+
+func (f formDraftDo) Create(values ...*model.FormDraft) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return f.DO.Create(values)
+}
+
+func (f formDraftDo) CreateInBatches(values []*model.FormDraft, batchSize int) error {
+	return f.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (f formDraftDo) Save(values ...*model.FormDraft) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return f.DO.Save(values)
+}
+
+func (f formDraftDo) First() (*model.FormDraft, error) {
+	if result, err := f.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.FormDraft), nil
+	}
+}
+
+func (f formDraftDo) Take() (*model.FormDraft, error) {
+	if result, err := f.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.FormDraft), nil
+	}
+}
+
+func (f formDraftDo) Last() (*model.FormDraft, error) {
+	if result, err := f.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.FormDraft), nil
+	}
+}
+
+func (f formDraftDo) Find() ([]*model.FormDraft, error) {
+	result, err := f.DO.Find()
+	return result.([]*model.FormDraft), err
+}
+
+func (f formDraftDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.FormDraft, err error) {
+	buf := make([]*model.FormDraft, 0, batchSize)
+	err = f.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (f formDraftDo) FindInBatches(result *[]*model.FormDraft, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return f.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (f formDraftDo) Attrs(attrs ...field.AssignExpr) *formDraftDo {
+	return f.withDO(f.DO.Attrs(attrs...))
+}
+
+func (f formDraftDo) Assign(attrs ...field.AssignExpr) *formDraftDo {
+	return f.withDO(f.DO.Assign(attrs...))
+}
+
+func (f formDraftDo) Joins(fields ...field.RelationField) *formDraftDo {
+	for _, _f := range fields {
+		f = *f.withDO(f.DO.Joins(_f))
+	}
+	return &f
+}
+
+func (f formDraftDo) Preload(fields ...field.RelationField) *formDraftDo {
+	for _, _f := range fields {
+		f = *f.withDO(f.DO.Preload(_f))
+	}
+	return &f
+}
+
+func (f formDraftDo) FirstOrInit() (*model.FormDraft, error) {
+	if result, err := f.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.FormDraft), nil
+	}
+}
+
+func (f formDraftDo) FirstOrCreate() (*model.FormDraft, error) {
+	if result, err := f.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.FormDraft), nil
+	}
+}
+
+func (f formDraftDo) FindByPage(offset int, limit int) (result []*model.FormDraft, count int64, err error) {
+	result, err = f.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = f.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (f formDraftDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = f.Count()
+	if err != nil {
+		return
+	}
+
+	err = f.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (f formDraftDo) Scan(result interface{}) (err error) {
+	return f.DO.Scan(result)
+}
+
+func (f formDraftDo) Delete(models ...*model.FormDraft) (result gen.ResultInfo, err error) {
+	return f.DO.Delete(models)
+}
+
+func (f *formDraftDo) withDO(do gen.Dao) *formDraftDo {
+	f.DO = *do.(*gen.DO)
+	return f
+}
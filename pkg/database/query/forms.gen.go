@@ -34,6 +34,9 @@ func newForm(db *gorm.DB) form {
 	_form.UserTelegramId = field.NewInt64(tableName, "user_telegram_id")
 	_form.Name = field.NewString(tableName, "name")
 	_form.Age = field.NewInt32(tableName, "age")
+	_form.BirthDay = field.NewInt32(tableName, "birth_day")
+	_form.BirthMonth = field.NewInt32(tableName, "birth_month")
+	_form.BirthYear = field.NewInt32(tableName, "birth_year")
 	_form.Gender = field.NewString(tableName, "gender")
 	_form.About = field.NewString(tableName, "about")
 	_form.Hobbies = field.NewString(tableName, "hobbies")
@@ -42,6 +45,9 @@ func newForm(db *gorm.DB) form {
 	_form.CoverLetter = field.NewString(tableName, "cover_letter")
 	_form.Contacts = field.NewString(tableName, "contacts")
 	_form.Status = field.NewString(tableName, "status")
+	_form.RejectionReason = field.NewString(tableName, "rejection_reason")
+	_form.DecidedBy = field.NewInt64(tableName, "decided_by")
+	_form.DecidedAt = field.NewTime(tableName, "decided_at")
 	_form.User = formBelongsToUser{
 		db: db.Session(&gorm.Session{}),
 
@@ -56,23 +62,29 @@ func newForm(db *gorm.DB) form {
 type form struct {
 	formDo formDo
 
-	ALL            field.Asterisk
-	ID             field.Uint
-	CreatedAt      field.Time
-	UpdatedAt      field.Time
-	DeletedAt      field.Field
-	UserTelegramId field.Int64
-	Name           field.String
-	Age            field.Int32
-	Gender         field.String
-	About          field.String
-	Hobbies        field.String
-	Work           field.String
-	Education      field.String
-	CoverLetter    field.String
-	Contacts       field.String
-	Status         field.String
-	User           formBelongsToUser
+	ALL             field.Asterisk
+	ID              field.Uint
+	CreatedAt       field.Time
+	UpdatedAt       field.Time
+	DeletedAt       field.Field
+	UserTelegramId  field.Int64
+	Name            field.String
+	Age             field.Int32
+	BirthDay        field.Int32
+	BirthMonth      field.Int32
+	BirthYear       field.Int32
+	Gender          field.String
+	About           field.String
+	Hobbies         field.String
+	Work            field.String
+	Education       field.String
+	CoverLetter     field.String
+	Contacts        field.String
+	Status          field.String
+	RejectionReason field.String
+	DecidedBy       field.Int64
+	DecidedAt       field.Time
+	User            formBelongsToUser
 
 	fieldMap map[string]field.Expr
 }
@@ -96,6 +108,9 @@ func (f *form) updateTableName(table string) *form {
 	f.UserTelegramId = field.NewInt64(table, "user_telegram_id")
 	f.Name = field.NewString(table, "name")
 	f.Age = field.NewInt32(table, "age")
+	f.BirthDay = field.NewInt32(table, "birth_day")
+	f.BirthMonth = field.NewInt32(table, "birth_month")
+	f.BirthYear = field.NewInt32(table, "birth_year")
 	f.Gender = field.NewString(table, "gender")
 	f.About = field.NewString(table, "about")
 	f.Hobbies = field.NewString(table, "hobbies")
@@ -104,6 +119,9 @@ func (f *form) updateTableName(table string) *form {
 	f.CoverLetter = field.NewString(table, "cover_letter")
 	f.Contacts = field.NewString(table, "contacts")
 	f.Status = field.NewString(table, "status")
+	f.RejectionReason = field.NewString(table, "rejection_reason")
+	f.DecidedBy = field.NewInt64(table, "decided_by")
+	f.DecidedAt = field.NewTime(table, "decided_at")
 
 	f.fillFieldMap()
 
@@ -126,7 +144,7 @@ func (f *form) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (f *form) fillFieldMap() {
-	f.fieldMap = make(map[string]field.Expr, 16)
+	f.fieldMap = make(map[string]field.Expr, 22)
 	f.fieldMap["id"] = f.ID
 	f.fieldMap["created_at"] = f.CreatedAt
 	f.fieldMap["updated_at"] = f.UpdatedAt
@@ -134,6 +152,9 @@ func (f *form) fillFieldMap() {
 	f.fieldMap["user_telegram_id"] = f.UserTelegramId
 	f.fieldMap["name"] = f.Name
 	f.fieldMap["age"] = f.Age
+	f.fieldMap["birth_day"] = f.BirthDay
+	f.fieldMap["birth_month"] = f.BirthMonth
+	f.fieldMap["birth_year"] = f.BirthYear
 	f.fieldMap["gender"] = f.Gender
 	f.fieldMap["about"] = f.About
 	f.fieldMap["hobbies"] = f.Hobbies
@@ -142,6 +163,9 @@ func (f *form) fillFieldMap() {
 	f.fieldMap["cover_letter"] = f.CoverLetter
 	f.fieldMap["contacts"] = f.Contacts
 	f.fieldMap["status"] = f.Status
+	f.fieldMap["rejection_reason"] = f.RejectionReason
+	f.fieldMap["decided_by"] = f.DecidedBy
+	f.fieldMap["decided_at"] = f.DecidedAt
 
 }
 
@@ -15,29 +15,56 @@ import (
 
 func Use(db *gorm.DB) *Query {
 	return &Query{
-		db:    db,
-		Form:  newForm(db),
-		Token: newToken(db),
-		User:  newUser(db),
+		db:              db,
+		Ban:             newBan(db),
+		BotState:        newBotState(db),
+		Form:            newForm(db),
+		FormDraft:       newFormDraft(db),
+		InviteLink:      newInviteLink(db),
+		MembershipPoll:  newMembershipPoll(db),
+		OutboxMessage:   newOutboxMessage(db),
+		PollVote:        newPollVote(db),
+		ProcessedUpdate: newProcessedUpdate(db),
+		Role:            newRole(db),
+		Token:           newToken(db),
+		User:            newUser(db),
 	}
 }
 
 type Query struct {
 	db *gorm.DB
 
-	Form  form
-	Token token
-	User  user
+	Ban             ban
+	BotState        botState
+	Form            form
+	FormDraft       formDraft
+	InviteLink      inviteLink
+	MembershipPoll  membershipPoll
+	OutboxMessage   outboxMessage
+	PollVote        pollVote
+	ProcessedUpdate processedUpdate
+	Role            role
+	Token           token
+	User            user
 }
 
 func (q *Query) Available() bool { return q.db != nil }
 
 func (q *Query) clone(db *gorm.DB) *Query {
 	return &Query{
-		db:    db,
-		Form:  q.Form.clone(db),
-		Token: q.Token.clone(db),
-		User:  q.User.clone(db),
+		db:              db,
+		Ban:             q.Ban.clone(db),
+		BotState:        q.BotState.clone(db),
+		Form:            q.Form.clone(db),
+		FormDraft:       q.FormDraft.clone(db),
+		InviteLink:      q.InviteLink.clone(db),
+		MembershipPoll:  q.MembershipPoll.clone(db),
+		OutboxMessage:   q.OutboxMessage.clone(db),
+		PollVote:        q.PollVote.clone(db),
+		ProcessedUpdate: q.ProcessedUpdate.clone(db),
+		Role:            q.Role.clone(db),
+		Token:           q.Token.clone(db),
+		User:            q.User.clone(db),
 	}
 }
 
@@ -54,16 +81,34 @@ func (q *Query) ReplaceDB(db *gorm.DB) *Query {
 }
 
 type queryCtx struct {
-	Form  *formDo
-	Token *tokenDo
-	User  *userDo
+	Ban             *banDo
+	BotState        *botStateDo
+	Form            *formDo
+	FormDraft       *formDraftDo
+	InviteLink      *inviteLinkDo
+	MembershipPoll  *membershipPollDo
+	OutboxMessage   *outboxMessageDo
+	PollVote        *pollVoteDo
+	ProcessedUpdate *processedUpdateDo
+	Role            *roleDo
+	Token           *tokenDo
+	User            *userDo
 }
 
 func (q *Query) WithContext(ctx context.Context) *queryCtx {
 	return &queryCtx{
-		Form:  q.Form.WithContext(ctx),
-		Token: q.Token.WithContext(ctx),
-		User:  q.User.WithContext(ctx),
+		Ban:             q.Ban.WithContext(ctx),
+		BotState:        q.BotState.WithContext(ctx),
+		Form:            q.Form.WithContext(ctx),
+		FormDraft:       q.FormDraft.WithContext(ctx),
+		InviteLink:      q.InviteLink.WithContext(ctx),
+		MembershipPoll:  q.MembershipPoll.WithContext(ctx),
+		OutboxMessage:   q.OutboxMessage.WithContext(ctx),
+		PollVote:        q.PollVote.WithContext(ctx),
+		ProcessedUpdate: q.ProcessedUpdate.WithContext(ctx),
+		Role:            q.Role.WithContext(ctx),
+		Token:           q.Token.WithContext(ctx),
+		User:            q.User.WithContext(ctx),
 	}
 }
 
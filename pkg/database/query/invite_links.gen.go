@@ -0,0 +1,431 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"beneburg/pkg/database/model"
+)
+
+func newInviteLink(db *gorm.DB) inviteLink {
+	_inviteLink := inviteLink{}
+
+	_inviteLink.inviteLinkDo.UseDB(db)
+	_inviteLink.inviteLinkDo.UseModel(&model.InviteLink{})
+
+	tableName := _inviteLink.inviteLinkDo.TableName()
+	_inviteLink.ALL = field.NewAsterisk(tableName)
+	_inviteLink.ID = field.NewUint(tableName, "id")
+	_inviteLink.CreatedAt = field.NewTime(tableName, "created_at")
+	_inviteLink.UpdatedAt = field.NewTime(tableName, "updated_at")
+	_inviteLink.DeletedAt = field.NewField(tableName, "deleted_at")
+	_inviteLink.UserTelegramId = field.NewInt64(tableName, "user_telegram_id")
+	_inviteLink.Link = field.NewString(tableName, "link")
+	_inviteLink.ExpireAt = field.NewTime(tableName, "expire_at")
+	_inviteLink.Status = field.NewString(tableName, "status")
+	_inviteLink.UsedAt = field.NewTime(tableName, "used_at")
+	_inviteLink.RevokedAt = field.NewTime(tableName, "revoked_at")
+	_inviteLink.User = inviteLinkBelongsToUser{
+		db: db.Session(&gorm.Session{}),
+
+		RelationField: field.NewRelation("User", "model.User"),
+	}
+
+	_inviteLink.fillFieldMap()
+
+	return _inviteLink
+}
+
+type inviteLink struct {
+	inviteLinkDo inviteLinkDo
+
+	ALL            field.Asterisk
+	ID             field.Uint
+	CreatedAt      field.Time
+	UpdatedAt      field.Time
+	DeletedAt      field.Field
+	UserTelegramId field.Int64
+	Link           field.String
+	ExpireAt       field.Time
+	Status         field.String
+	UsedAt         field.Time
+	RevokedAt      field.Time
+	User           inviteLinkBelongsToUser
+
+	fieldMap map[string]field.Expr
+}
+
+func (i inviteLink) Table(newTableName string) *inviteLink {
+	i.inviteLinkDo.UseTable(newTableName)
+	return i.updateTableName(newTableName)
+}
+
+func (i inviteLink) As(alias string) *inviteLink {
+	i.inviteLinkDo.DO = *(i.inviteLinkDo.As(alias).(*gen.DO))
+	return i.updateTableName(alias)
+}
+
+func (i *inviteLink) updateTableName(table string) *inviteLink {
+	i.ALL = field.NewAsterisk(table)
+	i.ID = field.NewUint(table, "id")
+	i.CreatedAt = field.NewTime(table, "created_at")
+	i.UpdatedAt = field.NewTime(table, "updated_at")
+	i.DeletedAt = field.NewField(table, "deleted_at")
+	i.UserTelegramId = field.NewInt64(table, "user_telegram_id")
+	i.Link = field.NewString(table, "link")
+	i.ExpireAt = field.NewTime(table, "expire_at")
+	i.Status = field.NewString(table, "status")
+	i.UsedAt = field.NewTime(table, "used_at")
+	i.RevokedAt = field.NewTime(table, "revoked_at")
+
+	i.fillFieldMap()
+
+	return i
+}
+
+func (i *inviteLink) WithContext(ctx context.Context) *inviteLinkDo {
+	return i.inviteLinkDo.WithContext(ctx)
+}
+
+func (i inviteLink) TableName() string { return i.inviteLinkDo.TableName() }
+
+func (i inviteLink) Alias() string { return i.inviteLinkDo.Alias() }
+
+func (i *inviteLink) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := i.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (i *inviteLink) fillFieldMap() {
+	i.fieldMap = make(map[string]field.Expr, 11)
+	i.fieldMap["id"] = i.ID
+	i.fieldMap["created_at"] = i.CreatedAt
+	i.fieldMap["updated_at"] = i.UpdatedAt
+	i.fieldMap["deleted_at"] = i.DeletedAt
+	i.fieldMap["user_telegram_id"] = i.UserTelegramId
+	i.fieldMap["link"] = i.Link
+	i.fieldMap["expire_at"] = i.ExpireAt
+	i.fieldMap["status"] = i.Status
+	i.fieldMap["used_at"] = i.UsedAt
+	i.fieldMap["revoked_at"] = i.RevokedAt
+
+}
+
+func (i inviteLink) clone(db *gorm.DB) inviteLink {
+	i.inviteLinkDo.ReplaceDB(db)
+	return i
+}
+
+type inviteLinkBelongsToUser struct {
+	db *gorm.DB
+
+	field.RelationField
+}
+
+func (a inviteLinkBelongsToUser) Where(conds ...field.Expr) *inviteLinkBelongsToUser {
+	if len(conds) == 0 {
+		return &a
+	}
+
+	exprs := make([]clause.Expression, 0, len(conds))
+	for _, cond := range conds {
+		exprs = append(exprs, cond.BeCond().(clause.Expression))
+	}
+	a.db = a.db.Clauses(clause.Where{Exprs: exprs})
+	return &a
+}
+
+func (a inviteLinkBelongsToUser) WithContext(ctx context.Context) *inviteLinkBelongsToUser {
+	a.db = a.db.WithContext(ctx)
+	return &a
+}
+
+func (a inviteLinkBelongsToUser) Model(m *model.InviteLink) *inviteLinkBelongsToUserTx {
+	return &inviteLinkBelongsToUserTx{a.db.Model(m).Association(a.Name())}
+}
+
+type inviteLinkBelongsToUserTx struct{ tx *gorm.Association }
+
+func (a inviteLinkBelongsToUserTx) Find() (result *model.User, err error) {
+	return result, a.tx.Find(&result)
+}
+
+func (a inviteLinkBelongsToUserTx) Append(values ...*model.User) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Append(targetValues...)
+}
+
+func (a inviteLinkBelongsToUserTx) Replace(values ...*model.User) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Replace(targetValues...)
+}
+
+func (a inviteLinkBelongsToUserTx) Delete(values ...*model.User) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Delete(targetValues...)
+}
+
+func (a inviteLinkBelongsToUserTx) Clear() error {
+	return a.tx.Clear()
+}
+
+func (a inviteLinkBelongsToUserTx) Count() int64 {
+	return a.tx.Count()
+}
+
+type inviteLinkDo struct{ gen.DO }
+
+func (i inviteLinkDo) Debug() *inviteLinkDo {
+	return i.withDO(i.DO.Debug())
+}
+
+func (i inviteLinkDo) WithContext(ctx context.Context) *inviteLinkDo {
+	return i.withDO(i.DO.WithContext(ctx))
+}
+
+func (i inviteLinkDo) ReadDB() *inviteLinkDo {
+	return i.Clauses(dbresolver.Read)
+}
+
+func (i inviteLinkDo) WriteDB() *inviteLinkDo {
+	return i.Clauses(dbresolver.Write)
+}
+
+func (i inviteLinkDo) Clauses(conds ...clause.Expression) *inviteLinkDo {
+	return i.withDO(i.DO.Clauses(conds...))
+}
+
+func (i inviteLinkDo) Returning(value interface{}, columns ...string) *inviteLinkDo {
+	return i.withDO(i.DO.Returning(value, columns...))
+}
+
+func (i inviteLinkDo) Not(conds ...gen.Condition) *inviteLinkDo {
+	return i.withDO(i.DO.Not(conds...))
+}
+
+func (i inviteLinkDo) Or(conds ...gen.Condition) *inviteLinkDo {
+	return i.withDO(i.DO.Or(conds...))
+}
+
+func (i inviteLinkDo) Select(conds ...field.Expr) *inviteLinkDo {
+	return i.withDO(i.DO.Select(conds...))
+}
+
+func (i inviteLinkDo) Where(conds ...gen.Condition) *inviteLinkDo {
+	return i.withDO(i.DO.Where(conds...))
+}
+
+func (i inviteLinkDo) Exists(subquery interface{ UnderlyingDB() *gorm.DB }) *inviteLinkDo {
+	return i.Where(field.CompareSubQuery(field.ExistsOp, nil, subquery.UnderlyingDB()))
+}
+
+func (i inviteLinkDo) Order(conds ...field.Expr) *inviteLinkDo {
+	return i.withDO(i.DO.Order(conds...))
+}
+
+func (i inviteLinkDo) Distinct(cols ...field.Expr) *inviteLinkDo {
+	return i.withDO(i.DO.Distinct(cols...))
+}
+
+func (i inviteLinkDo) Omit(cols ...field.Expr) *inviteLinkDo {
+	return i.withDO(i.DO.Omit(cols...))
+}
+
+func (i inviteLinkDo) Join(table schema.Tabler, on ...field.Expr) *inviteLinkDo {
+	return i.withDO(i.DO.Join(table, on...))
+}
+
+func (i inviteLinkDo) LeftJoin(table schema.Tabler, on ...field.Expr) *inviteLinkDo {
+	return i.withDO(i.DO.LeftJoin(table, on...))
+}
+
+func (i inviteLinkDo) RightJoin(table schema.Tabler, on ...field.Expr) *inviteLinkDo {
+	return i.withDO(i.DO.RightJoin(table, on...))
+}
+
+func (i inviteLinkDo) Group(cols ...field.Expr) *inviteLinkDo {
+	return i.withDO(i.DO.Group(cols...))
+}
+
+func (i inviteLinkDo) Having(conds ...gen.Condition) *inviteLinkDo {
+	return i.withDO(i.DO.Having(conds...))
+}
+
+func (i inviteLinkDo) Limit(limit int) *inviteLinkDo {
+	return i.withDO(i.DO.Limit(limit))
+}
+
+func (i inviteLinkDo) Offset(offset int) *inviteLinkDo {
+	return i.withDO(i.DO.Offset(offset))
+}
+
+func (i inviteLinkDo) Scopes(funcs ...func(gen.Dao) gen.Dao) *inviteLinkDo {
+	return i.withDO(i.DO.Scopes(funcs...))
+}
+
+func (i inviteLinkDo) Unscoped() *inviteLinkDo {
+	return i.withDO(i.DO.Unscoped())
+}
+
+func (i inviteLinkDo) Create(values ...*model.InviteLink) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return i.DO.Create(values)
+}
+
+func (i inviteLinkDo) CreateInBatches(values []*model.InviteLink, batchSize int) error {
+	return i.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (i inviteLinkDo) Save(values ...*model.InviteLink) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return i.DO.Save(values)
+}
+
+func (i inviteLinkDo) First() (*model.InviteLink, error) {
+	if result, err := i.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.InviteLink), nil
+	}
+}
+
+func (i inviteLinkDo) Take() (*model.InviteLink, error) {
+	if result, err := i.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.InviteLink), nil
+	}
+}
+
+func (i inviteLinkDo) Last() (*model.InviteLink, error) {
+	if result, err := i.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.InviteLink), nil
+	}
+}
+
+func (i inviteLinkDo) Find() ([]*model.InviteLink, error) {
+	result, err := i.DO.Find()
+	return result.([]*model.InviteLink), err
+}
+
+func (i inviteLinkDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.InviteLink, err error) {
+	buf := make([]*model.InviteLink, 0, batchSize)
+	err = i.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (i inviteLinkDo) FindInBatches(result *[]*model.InviteLink, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return i.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (i inviteLinkDo) Attrs(attrs ...field.AssignExpr) *inviteLinkDo {
+	return i.withDO(i.DO.Attrs(attrs...))
+}
+
+func (i inviteLinkDo) Assign(attrs ...field.AssignExpr) *inviteLinkDo {
+	return i.withDO(i.DO.Assign(attrs...))
+}
+
+func (i inviteLinkDo) Joins(fields ...field.RelationField) *inviteLinkDo {
+	for _, _f := range fields {
+		i = *i.withDO(i.DO.Joins(_f))
+	}
+	return &i
+}
+
+func (i inviteLinkDo) Preload(fields ...field.RelationField) *inviteLinkDo {
+	for _, _f := range fields {
+		i = *i.withDO(i.DO.Preload(_f))
+	}
+	return &i
+}
+
+func (i inviteLinkDo) FirstOrInit() (*model.InviteLink, error) {
+	if result, err := i.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.InviteLink), nil
+	}
+}
+
+func (i inviteLinkDo) FirstOrCreate() (*model.InviteLink, error) {
+	if result, err := i.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.InviteLink), nil
+	}
+}
+
+func (i inviteLinkDo) FindByPage(offset int, limit int) (result []*model.InviteLink, count int64, err error) {
+	result, err = i.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = i.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (i inviteLinkDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = i.Count()
+	if err != nil {
+		return
+	}
+
+	err = i.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (i inviteLinkDo) Scan(result interface{}) (err error) {
+	return i.DO.Scan(result)
+}
+
+func (i inviteLinkDo) Delete(models ...*model.InviteLink) (result gen.ResultInfo, err error) {
+	return i.DO.Delete(models)
+}
+
+func (i *inviteLinkDo) withDO(do gen.Dao) *inviteLinkDo {
+	i.DO = *do.(*gen.DO)
+	return i
+}
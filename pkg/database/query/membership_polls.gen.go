@@ -0,0 +1,534 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"beneburg/pkg/database/model"
+)
+
+func newMembershipPoll(db *gorm.DB) membershipPoll {
+	_membershipPoll := membershipPoll{}
+
+	_membershipPoll.membershipPollDo.UseDB(db)
+	_membershipPoll.membershipPollDo.UseModel(&model.MembershipPoll{})
+
+	tableName := _membershipPoll.membershipPollDo.TableName()
+	_membershipPoll.ALL = field.NewAsterisk(tableName)
+	_membershipPoll.ID = field.NewUint(tableName, "id")
+	_membershipPoll.CreatedAt = field.NewTime(tableName, "created_at")
+	_membershipPoll.UpdatedAt = field.NewTime(tableName, "updated_at")
+	_membershipPoll.DeletedAt = field.NewField(tableName, "deleted_at")
+	_membershipPoll.PollID = field.NewString(tableName, "poll_id")
+	_membershipPoll.ChatID = field.NewInt64(tableName, "chat_id")
+	_membershipPoll.MessageID = field.NewInt(tableName, "message_id")
+	_membershipPoll.FormID = field.NewUint(tableName, "form_id")
+	_membershipPoll.UserTelegramId = field.NewInt64(tableName, "user_telegram_id")
+	_membershipPoll.AcceptVotes = field.NewInt(tableName, "accept_votes")
+	_membershipPoll.RejectVotes = field.NewInt(tableName, "reject_votes")
+	_membershipPoll.Status = field.NewString(tableName, "status")
+	_membershipPoll.Decision = field.NewString(tableName, "decision")
+	_membershipPoll.DecidedBy = field.NewInt64(tableName, "decided_by")
+	_membershipPoll.ClosedAt = field.NewTime(tableName, "closed_at")
+	_membershipPoll.Form = membershipPollBelongsToForm{
+		db: db.Session(&gorm.Session{}),
+
+		RelationField: field.NewRelation("Form", "model.Form"),
+		User: struct {
+			field.RelationField
+		}{
+			RelationField: field.NewRelation("Form.User", "model.User"),
+		},
+	}
+
+	_membershipPoll.User = membershipPollBelongsToUser{
+		db: db.Session(&gorm.Session{}),
+
+		RelationField: field.NewRelation("User", "model.User"),
+	}
+
+	_membershipPoll.fillFieldMap()
+
+	return _membershipPoll
+}
+
+type membershipPoll struct {
+	membershipPollDo membershipPollDo
+
+	ALL            field.Asterisk
+	ID             field.Uint
+	CreatedAt      field.Time
+	UpdatedAt      field.Time
+	DeletedAt      field.Field
+	PollID         field.String
+	ChatID         field.Int64
+	MessageID      field.Int
+	FormID         field.Uint
+	UserTelegramId field.Int64
+	AcceptVotes    field.Int
+	RejectVotes    field.Int
+	Status         field.String
+	Decision       field.String
+	DecidedBy      field.Int64
+	ClosedAt       field.Time
+	Form           membershipPollBelongsToForm
+
+	User membershipPollBelongsToUser
+
+	fieldMap map[string]field.Expr
+}
+
+func (m membershipPoll) Table(newTableName string) *membershipPoll {
+	m.membershipPollDo.UseTable(newTableName)
+	return m.updateTableName(newTableName)
+}
+
+func (m membershipPoll) As(alias string) *membershipPoll {
+	m.membershipPollDo.DO = *(m.membershipPollDo.As(alias).(*gen.DO))
+	return m.updateTableName(alias)
+}
+
+func (m *membershipPoll) updateTableName(table string) *membershipPoll {
+	m.ALL = field.NewAsterisk(table)
+	m.ID = field.NewUint(table, "id")
+	m.CreatedAt = field.NewTime(table, "created_at")
+	m.UpdatedAt = field.NewTime(table, "updated_at")
+	m.DeletedAt = field.NewField(table, "deleted_at")
+	m.PollID = field.NewString(table, "poll_id")
+	m.ChatID = field.NewInt64(table, "chat_id")
+	m.MessageID = field.NewInt(table, "message_id")
+	m.FormID = field.NewUint(table, "form_id")
+	m.UserTelegramId = field.NewInt64(table, "user_telegram_id")
+	m.AcceptVotes = field.NewInt(table, "accept_votes")
+	m.RejectVotes = field.NewInt(table, "reject_votes")
+	m.Status = field.NewString(table, "status")
+	m.Decision = field.NewString(table, "decision")
+	m.DecidedBy = field.NewInt64(table, "decided_by")
+	m.ClosedAt = field.NewTime(table, "closed_at")
+
+	m.fillFieldMap()
+
+	return m
+}
+
+func (m *membershipPoll) WithContext(ctx context.Context) *membershipPollDo {
+	return m.membershipPollDo.WithContext(ctx)
+}
+
+func (m membershipPoll) TableName() string { return m.membershipPollDo.TableName() }
+
+func (m membershipPoll) Alias() string { return m.membershipPollDo.Alias() }
+
+func (m *membershipPoll) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := m.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (m *membershipPoll) fillFieldMap() {
+	m.fieldMap = make(map[string]field.Expr, 17)
+	m.fieldMap["id"] = m.ID
+	m.fieldMap["created_at"] = m.CreatedAt
+	m.fieldMap["updated_at"] = m.UpdatedAt
+	m.fieldMap["deleted_at"] = m.DeletedAt
+	m.fieldMap["poll_id"] = m.PollID
+	m.fieldMap["chat_id"] = m.ChatID
+	m.fieldMap["message_id"] = m.MessageID
+	m.fieldMap["form_id"] = m.FormID
+	m.fieldMap["user_telegram_id"] = m.UserTelegramId
+	m.fieldMap["accept_votes"] = m.AcceptVotes
+	m.fieldMap["reject_votes"] = m.RejectVotes
+	m.fieldMap["status"] = m.Status
+	m.fieldMap["decision"] = m.Decision
+	m.fieldMap["decided_by"] = m.DecidedBy
+	m.fieldMap["closed_at"] = m.ClosedAt
+
+}
+
+func (m membershipPoll) clone(db *gorm.DB) membershipPoll {
+	m.membershipPollDo.ReplaceDB(db)
+	return m
+}
+
+type membershipPollBelongsToForm struct {
+	db *gorm.DB
+
+	field.RelationField
+
+	User struct {
+		field.RelationField
+	}
+}
+
+func (a membershipPollBelongsToForm) Where(conds ...field.Expr) *membershipPollBelongsToForm {
+	if len(conds) == 0 {
+		return &a
+	}
+
+	exprs := make([]clause.Expression, 0, len(conds))
+	for _, cond := range conds {
+		exprs = append(exprs, cond.BeCond().(clause.Expression))
+	}
+	a.db = a.db.Clauses(clause.Where{Exprs: exprs})
+	return &a
+}
+
+func (a membershipPollBelongsToForm) WithContext(ctx context.Context) *membershipPollBelongsToForm {
+	a.db = a.db.WithContext(ctx)
+	return &a
+}
+
+func (a membershipPollBelongsToForm) Model(m *model.MembershipPoll) *membershipPollBelongsToFormTx {
+	return &membershipPollBelongsToFormTx{a.db.Model(m).Association(a.Name())}
+}
+
+type membershipPollBelongsToFormTx struct{ tx *gorm.Association }
+
+func (a membershipPollBelongsToFormTx) Find() (result *model.Form, err error) {
+	return result, a.tx.Find(&result)
+}
+
+func (a membershipPollBelongsToFormTx) Append(values ...*model.Form) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Append(targetValues...)
+}
+
+func (a membershipPollBelongsToFormTx) Replace(values ...*model.Form) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Replace(targetValues...)
+}
+
+func (a membershipPollBelongsToFormTx) Delete(values ...*model.Form) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Delete(targetValues...)
+}
+
+func (a membershipPollBelongsToFormTx) Clear() error {
+	return a.tx.Clear()
+}
+
+func (a membershipPollBelongsToFormTx) Count() int64 {
+	return a.tx.Count()
+}
+
+type membershipPollBelongsToUser struct {
+	db *gorm.DB
+
+	field.RelationField
+}
+
+func (a membershipPollBelongsToUser) Where(conds ...field.Expr) *membershipPollBelongsToUser {
+	if len(conds) == 0 {
+		return &a
+	}
+
+	exprs := make([]clause.Expression, 0, len(conds))
+	for _, cond := range conds {
+		exprs = append(exprs, cond.BeCond().(clause.Expression))
+	}
+	a.db = a.db.Clauses(clause.Where{Exprs: exprs})
+	return &a
+}
+
+func (a membershipPollBelongsToUser) WithContext(ctx context.Context) *membershipPollBelongsToUser {
+	a.db = a.db.WithContext(ctx)
+	return &a
+}
+
+func (a membershipPollBelongsToUser) Model(m *model.MembershipPoll) *membershipPollBelongsToUserTx {
+	return &membershipPollBelongsToUserTx{a.db.Model(m).Association(a.Name())}
+}
+
+type membershipPollBelongsToUserTx struct{ tx *gorm.Association }
+
+func (a membershipPollBelongsToUserTx) Find() (result *model.User, err error) {
+	return result, a.tx.Find(&result)
+}
+
+func (a membershipPollBelongsToUserTx) Append(values ...*model.User) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Append(targetValues...)
+}
+
+func (a membershipPollBelongsToUserTx) Replace(values ...*model.User) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Replace(targetValues...)
+}
+
+func (a membershipPollBelongsToUserTx) Delete(values ...*model.User) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Delete(targetValues...)
+}
+
+func (a membershipPollBelongsToUserTx) Clear() error {
+	return a.tx.Clear()
+}
+
+func (a membershipPollBelongsToUserTx) Count() int64 {
+	return a.tx.Count()
+}
+
+type membershipPollDo struct{ gen.DO }
+
+func (m membershipPollDo) Debug() *membershipPollDo {
+	return m.withDO(m.DO.Debug())
+}
+
+func (m membershipPollDo) WithContext(ctx context.Context) *membershipPollDo {
+	return m.withDO(m.DO.WithContext(ctx))
+}
+
+func (m membershipPollDo) ReadDB() *membershipPollDo {
+	return m.Clauses(dbresolver.Read)
+}
+
+func (m membershipPollDo) WriteDB() *membershipPollDo {
+	return m.Clauses(dbresolver.Write)
+}
+
+func (m membershipPollDo) Clauses(conds ...clause.Expression) *membershipPollDo {
+	return m.withDO(m.DO.Clauses(conds...))
+}
+
+func (m membershipPollDo) Returning(value interface{}, columns ...string) *membershipPollDo {
+	return m.withDO(m.DO.Returning(value, columns...))
+}
+
+func (m membershipPollDo) Not(conds ...gen.Condition) *membershipPollDo {
+	return m.withDO(m.DO.Not(conds...))
+}
+
+func (m membershipPollDo) Or(conds ...gen.Condition) *membershipPollDo {
+	return m.withDO(m.DO.Or(conds...))
+}
+
+func (m membershipPollDo) Select(conds ...field.Expr) *membershipPollDo {
+	return m.withDO(m.DO.Select(conds...))
+}
+
+func (m membershipPollDo) Where(conds ...gen.Condition) *membershipPollDo {
+	return m.withDO(m.DO.Where(conds...))
+}
+
+func (m membershipPollDo) Exists(subquery interface{ UnderlyingDB() *gorm.DB }) *membershipPollDo {
+	return m.Where(field.CompareSubQuery(field.ExistsOp, nil, subquery.UnderlyingDB()))
+}
+
+func (m membershipPollDo) Order(conds ...field.Expr) *membershipPollDo {
+	return m.withDO(m.DO.Order(conds...))
+}
+
+func (m membershipPollDo) Distinct(cols ...field.Expr) *membershipPollDo {
+	return m.withDO(m.DO.Distinct(cols...))
+}
+
+func (m membershipPollDo) Omit(cols ...field.Expr) *membershipPollDo {
+	return m.withDO(m.DO.Omit(cols...))
+}
+
+func (m membershipPollDo) Join(table schema.Tabler, on ...field.Expr) *membershipPollDo {
+	return m.withDO(m.DO.Join(table, on...))
+}
+
+func (m membershipPollDo) LeftJoin(table schema.Tabler, on ...field.Expr) *membershipPollDo {
+	return m.withDO(m.DO.LeftJoin(table, on...))
+}
+
+func (m membershipPollDo) RightJoin(table schema.Tabler, on ...field.Expr) *membershipPollDo {
+	return m.withDO(m.DO.RightJoin(table, on...))
+}
+
+func (m membershipPollDo) Group(cols ...field.Expr) *membershipPollDo {
+	return m.withDO(m.DO.Group(cols...))
+}
+
+func (m membershipPollDo) Having(conds ...gen.Condition) *membershipPollDo {
+	return m.withDO(m.DO.Having(conds...))
+}
+
+func (m membershipPollDo) Limit(limit int) *membershipPollDo {
+	return m.withDO(m.DO.Limit(limit))
+}
+
+func (m membershipPollDo) Offset(offset int) *membershipPollDo {
+	return m.withDO(m.DO.Offset(offset))
+}
+
+func (m membershipPollDo) Scopes(funcs ...func(gen.Dao) gen.Dao) *membershipPollDo {
+	return m.withDO(m.DO.Scopes(funcs...))
+}
+
+func (m membershipPollDo) Unscoped() *membershipPollDo {
+	return m.withDO(m.DO.Unscoped())
+}
+
+func (m membershipPollDo) Create(values ...*model.MembershipPoll) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return m.DO.Create(values)
+}
+
+func (m membershipPollDo) CreateInBatches(values []*model.MembershipPoll, batchSize int) error {
+	return m.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (m membershipPollDo) Save(values ...*model.MembershipPoll) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return m.DO.Save(values)
+}
+
+func (m membershipPollDo) First() (*model.MembershipPoll, error) {
+	if result, err := m.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.MembershipPoll), nil
+	}
+}
+
+func (m membershipPollDo) Take() (*model.MembershipPoll, error) {
+	if result, err := m.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.MembershipPoll), nil
+	}
+}
+
+func (m membershipPollDo) Last() (*model.MembershipPoll, error) {
+	if result, err := m.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.MembershipPoll), nil
+	}
+}
+
+func (m membershipPollDo) Find() ([]*model.MembershipPoll, error) {
+	result, err := m.DO.Find()
+	return result.([]*model.MembershipPoll), err
+}
+
+func (m membershipPollDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.MembershipPoll, err error) {
+	buf := make([]*model.MembershipPoll, 0, batchSize)
+	err = m.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (m membershipPollDo) FindInBatches(result *[]*model.MembershipPoll, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return m.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (m membershipPollDo) Attrs(attrs ...field.AssignExpr) *membershipPollDo {
+	return m.withDO(m.DO.Attrs(attrs...))
+}
+
+func (m membershipPollDo) Assign(attrs ...field.AssignExpr) *membershipPollDo {
+	return m.withDO(m.DO.Assign(attrs...))
+}
+
+func (m membershipPollDo) Joins(fields ...field.RelationField) *membershipPollDo {
+	for _, _f := range fields {
+		m = *m.withDO(m.DO.Joins(_f))
+	}
+	return &m
+}
+
+func (m membershipPollDo) Preload(fields ...field.RelationField) *membershipPollDo {
+	for _, _f := range fields {
+		m = *m.withDO(m.DO.Preload(_f))
+	}
+	return &m
+}
+
+func (m membershipPollDo) FirstOrInit() (*model.MembershipPoll, error) {
+	if result, err := m.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.MembershipPoll), nil
+	}
+}
+
+func (m membershipPollDo) FirstOrCreate() (*model.MembershipPoll, error) {
+	if result, err := m.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.MembershipPoll), nil
+	}
+}
+
+func (m membershipPollDo) FindByPage(offset int, limit int) (result []*model.MembershipPoll, count int64, err error) {
+	result, err = m.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = m.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (m membershipPollDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = m.Count()
+	if err != nil {
+		return
+	}
+
+	err = m.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (m membershipPollDo) Scan(result interface{}) (err error) {
+	return m.DO.Scan(result)
+}
+
+func (m membershipPollDo) Delete(models ...*model.MembershipPoll) (result gen.ResultInfo, err error) {
+	return m.DO.Delete(models)
+}
+
+func (m *membershipPollDo) withDO(do gen.Dao) *membershipPollDo {
+	m.DO = *do.(*gen.DO)
+	return m
+}
@@ -0,0 +1,366 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"beneburg/pkg/database/model"
+)
+
+func newOutboxMessage(db *gorm.DB) outboxMessage {
+	_outboxMessage := outboxMessage{}
+
+	_outboxMessage.outboxMessageDo.UseDB(db)
+	_outboxMessage.outboxMessageDo.UseModel(&model.OutboxMessage{})
+
+	tableName := _outboxMessage.outboxMessageDo.TableName()
+	_outboxMessage.ALL = field.NewAsterisk(tableName)
+	_outboxMessage.ID = field.NewUint(tableName, "id")
+	_outboxMessage.CreatedAt = field.NewTime(tableName, "created_at")
+	_outboxMessage.UpdatedAt = field.NewTime(tableName, "updated_at")
+	_outboxMessage.DeletedAt = field.NewField(tableName, "deleted_at")
+	_outboxMessage.ChatID = field.NewInt64(tableName, "chat_id")
+	_outboxMessage.Type = field.NewString(tableName, "type")
+	_outboxMessage.Payload = field.NewString(tableName, "payload")
+	_outboxMessage.Status = field.NewString(tableName, "status")
+	_outboxMessage.Attempts = field.NewInt(tableName, "attempts")
+	_outboxMessage.NextAttemptAt = field.NewTime(tableName, "next_attempt_at")
+	_outboxMessage.LastError = field.NewString(tableName, "last_error")
+	_outboxMessage.DelayReason = field.NewString(tableName, "delay_reason")
+
+	_outboxMessage.fillFieldMap()
+
+	return _outboxMessage
+}
+
+type outboxMessage struct {
+	outboxMessageDo outboxMessageDo
+
+	ALL           field.Asterisk
+	ID            field.Uint
+	CreatedAt     field.Time
+	UpdatedAt     field.Time
+	DeletedAt     field.Field
+	ChatID        field.Int64
+	Type          field.String
+	Payload       field.String
+	Status        field.String
+	Attempts      field.Int
+	NextAttemptAt field.Time
+	LastError     field.String
+	DelayReason   field.String
+
+	fieldMap map[string]field.Expr
+}
+
+func (o outboxMessage) Table(newTableName string) *outboxMessage {
+	o.outboxMessageDo.UseTable(newTableName)
+	return o.updateTableName(newTableName)
+}
+
+func (o outboxMessage) As(alias string) *outboxMessage {
+	o.outboxMessageDo.DO = *(o.outboxMessageDo.As(alias).(*gen.DO))
+	return o.updateTableName(alias)
+}
+
+func (o *outboxMessage) updateTableName(table string) *outboxMessage {
+	o.ALL = field.NewAsterisk(table)
+	o.ID = field.NewUint(table, "id")
+	o.CreatedAt = field.NewTime(table, "created_at")
+	o.UpdatedAt = field.NewTime(table, "updated_at")
+	o.DeletedAt = field.NewField(table, "deleted_at")
+	o.ChatID = field.NewInt64(table, "chat_id")
+	o.Type = field.NewString(table, "type")
+	o.Payload = field.NewString(table, "payload")
+	o.Status = field.NewString(table, "status")
+	o.Attempts = field.NewInt(table, "attempts")
+	o.NextAttemptAt = field.NewTime(table, "next_attempt_at")
+	o.LastError = field.NewString(table, "last_error")
+	o.DelayReason = field.NewString(table, "delay_reason")
+
+	o.fillFieldMap()
+
+	return o
+}
+
+func (o *outboxMessage) WithContext(ctx context.Context) *outboxMessageDo {
+	return o.outboxMessageDo.WithContext(ctx)
+}
+
+func (o outboxMessage) TableName() string { return o.outboxMessageDo.TableName() }
+
+func (o outboxMessage) Alias() string { return o.outboxMessageDo.Alias() }
+
+func (o *outboxMessage) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := o.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (o *outboxMessage) fillFieldMap() {
+	o.fieldMap = make(map[string]field.Expr, 12)
+	o.fieldMap["id"] = o.ID
+	o.fieldMap["created_at"] = o.CreatedAt
+	o.fieldMap["updated_at"] = o.UpdatedAt
+	o.fieldMap["deleted_at"] = o.DeletedAt
+	o.fieldMap["chat_id"] = o.ChatID
+	o.fieldMap["type"] = o.Type
+	o.fieldMap["payload"] = o.Payload
+	o.fieldMap["status"] = o.Status
+	o.fieldMap["attempts"] = o.Attempts
+	o.fieldMap["next_attempt_at"] = o.NextAttemptAt
+	o.fieldMap["last_error"] = o.LastError
+	o.fieldMap["delay_reason"] = o.DelayReason
+}
+
+func (o outboxMessage) clone(db *gorm.DB) outboxMessage {
+	o.outboxMessageDo.ReplaceDB(db)
+	return o
+}
+
+type outboxMessageDo struct{ gen.DO }
+
+func (o outboxMessageDo) Debug() *outboxMessageDo {
+	return o.withDO(o.DO.Debug())
+}
+
+func (o outboxMessageDo) WithContext(ctx context.Context) *outboxMessageDo {
+	return o.withDO(o.DO.WithContext(ctx))
+}
+
+func (o outboxMessageDo) ReadDB() *outboxMessageDo {
+	return o.Clauses(dbresolver.Read)
+}
+
+func (o outboxMessageDo) WriteDB() *outboxMessageDo {
+	return o.Clauses(dbresolver.Write)
+}
+
+func (o outboxMessageDo) Clauses(conds ...clause.Expression) *outboxMessageDo {
+	return o.withDO(o.DO.Clauses(conds...))
+}
+
+func (o outboxMessageDo) Returning(value interface{}, columns ...string) *outboxMessageDo {
+	return o.withDO(o.DO.Returning(value, columns...))
+}
+
+func (o outboxMessageDo) Not(conds ...gen.Condition) *outboxMessageDo {
+	return o.withDO(o.DO.Not(conds...))
+}
+
+func (o outboxMessageDo) Or(conds ...gen.Condition) *outboxMessageDo {
+	return o.withDO(o.DO.Or(conds...))
+}
+
+func (o outboxMessageDo) Select(conds ...field.Expr) *outboxMessageDo {
+	return o.withDO(o.DO.Select(conds...))
+}
+
+func (o outboxMessageDo) Where(conds ...gen.Condition) *outboxMessageDo {
+	return o.withDO(o.DO.Where(conds...))
+}
+
+func (o outboxMessageDo) Exists(subquery interface{ UnderlyingDB() *gorm.DB }) *outboxMessageDo {
+	return o.Where(field.CompareSubQuery(field.ExistsOp, nil, subquery.UnderlyingDB()))
+}
+
+func (o outboxMessageDo) Order(conds ...field.Expr) *outboxMessageDo {
+	return o.withDO(o.DO.Order(conds...))
+}
+
+func (o outboxMessageDo) Distinct(cols ...field.Expr) *outboxMessageDo {
+	return o.withDO(o.DO.Distinct(cols...))
+}
+
+func (o outboxMessageDo) Omit(cols ...field.Expr) *outboxMessageDo {
+	return o.withDO(o.DO.Omit(cols...))
+}
+
+func (o outboxMessageDo) Join(table schema.Tabler, on ...field.Expr) *outboxMessageDo {
+	return o.withDO(o.DO.Join(table, on...))
+}
+
+func (o outboxMessageDo) LeftJoin(table schema.Tabler, on ...field.Expr) *outboxMessageDo {
+	return o.withDO(o.DO.LeftJoin(table, on...))
+}
+
+func (o outboxMessageDo) RightJoin(table schema.Tabler, on ...field.Expr) *outboxMessageDo {
+	return o.withDO(o.DO.RightJoin(table, on...))
+}
+
+func (o outboxMessageDo) Group(cols ...field.Expr) *outboxMessageDo {
+	return o.withDO(o.DO.Group(cols...))
+}
+
+func (o outboxMessageDo) Having(conds ...gen.Condition) *outboxMessageDo {
+	return o.withDO(o.DO.Having(conds...))
+}
+
+func (o outboxMessageDo) Limit(limit int) *outboxMessageDo {
+	return o.withDO(o.DO.Limit(limit))
+}
+
+func (o outboxMessageDo) Offset(offset int) *outboxMessageDo {
+	return o.withDO(o.DO.Offset(offset))
+}
+
+func (o outboxMessageDo) Scopes(funcs ...func(gen.Dao) gen.Dao) *outboxMessageDo {
+	return o.withDO(o.DO.Scopes(funcs...))
+}
+
+func (o outboxMessageDo) Unscoped() *outboxMessageDo {
+	return o.withDO(o.DO.Unscoped())
+}
+
+func (o outboxMessageDo) Create(values ...*model.OutboxMessage) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return o.DO.Create(values)
+}
+
+func (o outboxMessageDo) CreateInBatches(values []*model.OutboxMessage, batchSize int) error {
+	return o.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (o outboxMessageDo) Save(values ...*model.OutboxMessage) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return o.DO.Save(values)
+}
+
+func (o outboxMessageDo) First() (*model.OutboxMessage, error) {
+	if result, err := o.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.OutboxMessage), nil
+	}
+}
+
+func (o outboxMessageDo) Take() (*model.OutboxMessage, error) {
+	if result, err := o.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.OutboxMessage), nil
+	}
+}
+
+func (o outboxMessageDo) Last() (*model.OutboxMessage, error) {
+	if result, err := o.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.OutboxMessage), nil
+	}
+}
+
+func (o outboxMessageDo) Find() ([]*model.OutboxMessage, error) {
+	result, err := o.DO.Find()
+	return result.([]*model.OutboxMessage), err
+}
+
+func (o outboxMessageDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.OutboxMessage, err error) {
+	buf := make([]*model.OutboxMessage, 0, batchSize)
+	err = o.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (o outboxMessageDo) FindInBatches(result *[]*model.OutboxMessage, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return o.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (o outboxMessageDo) Attrs(attrs ...field.AssignExpr) *outboxMessageDo {
+	return o.withDO(o.DO.Attrs(attrs...))
+}
+
+func (o outboxMessageDo) Assign(attrs ...field.AssignExpr) *outboxMessageDo {
+	return o.withDO(o.DO.Assign(attrs...))
+}
+
+func (o outboxMessageDo) Joins(fields ...field.RelationField) *outboxMessageDo {
+	for _, _f := range fields {
+		o = *o.withDO(o.DO.Joins(_f))
+	}
+	return &o
+}
+
+func (o outboxMessageDo) Preload(fields ...field.RelationField) *outboxMessageDo {
+	for _, _f := range fields {
+		o = *o.withDO(o.DO.Preload(_f))
+	}
+	return &o
+}
+
+func (o outboxMessageDo) FirstOrInit() (*model.OutboxMessage, error) {
+	if result, err := o.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.OutboxMessage), nil
+	}
+}
+
+func (o outboxMessageDo) FirstOrCreate() (*model.OutboxMessage, error) {
+	if result, err := o.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.OutboxMessage), nil
+	}
+}
+
+func (o outboxMessageDo) FindByPage(offset int, limit int) (result []*model.OutboxMessage, count int64, err error) {
+	result, err = o.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = o.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (o outboxMessageDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = o.Count()
+	if err != nil {
+		return
+	}
+
+	err = o.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (o outboxMessageDo) Scan(result interface{}) (err error) {
+	return o.DO.Scan(result)
+}
+
+func (o outboxMessageDo) Delete(models ...*model.OutboxMessage) (result gen.ResultInfo, err error) {
+	return o.DO.Delete(models)
+}
+
+func (o *outboxMessageDo) withDO(do gen.Dao) *outboxMessageDo {
+	o.DO = *do.(*gen.DO)
+	return o
+}
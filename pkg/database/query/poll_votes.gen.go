@@ -0,0 +1,437 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"beneburg/pkg/database/model"
+)
+
+func newPollVote(db *gorm.DB) pollVote {
+	_pollVote := pollVote{}
+
+	_pollVote.pollVoteDo.UseDB(db)
+	_pollVote.pollVoteDo.UseModel(&model.PollVote{})
+
+	tableName := _pollVote.pollVoteDo.TableName()
+	_pollVote.ALL = field.NewAsterisk(tableName)
+	_pollVote.MembershipPollID = field.NewUint(tableName, "membership_poll_id")
+	_pollVote.VoterTelegramId = field.NewInt64(tableName, "voter_telegram_id")
+	_pollVote.Option = field.NewInt(tableName, "option")
+	_pollVote.CreatedAt = field.NewTime(tableName, "created_at")
+	_pollVote.UpdatedAt = field.NewTime(tableName, "updated_at")
+	_pollVote.MembershipPoll = pollVoteBelongsToMembershipPoll{
+		db: db.Session(&gorm.Session{}),
+
+		RelationField: field.NewRelation("MembershipPoll", "model.MembershipPoll"),
+		Form: struct {
+			field.RelationField
+			User struct {
+				field.RelationField
+			}
+		}{
+			RelationField: field.NewRelation("MembershipPoll.Form", "model.Form"),
+			User: struct {
+				field.RelationField
+			}{
+				RelationField: field.NewRelation("MembershipPoll.Form.User", "model.User"),
+			},
+		},
+		User: struct {
+			field.RelationField
+		}{
+			RelationField: field.NewRelation("MembershipPoll.User", "model.User"),
+		},
+	}
+
+	_pollVote.fillFieldMap()
+
+	return _pollVote
+}
+
+type pollVote struct {
+	pollVoteDo pollVoteDo
+
+	ALL              field.Asterisk
+	MembershipPollID field.Uint
+	VoterTelegramId  field.Int64
+	Option           field.Int
+	CreatedAt        field.Time
+	UpdatedAt        field.Time
+	MembershipPoll   pollVoteBelongsToMembershipPoll
+
+	fieldMap map[string]field.Expr
+}
+
+func (p pollVote) Table(newTableName string) *pollVote {
+	p.pollVoteDo.UseTable(newTableName)
+	return p.updateTableName(newTableName)
+}
+
+func (p pollVote) As(alias string) *pollVote {
+	p.pollVoteDo.DO = *(p.pollVoteDo.As(alias).(*gen.DO))
+	return p.updateTableName(alias)
+}
+
+func (p *pollVote) updateTableName(table string) *pollVote {
+	p.ALL = field.NewAsterisk(table)
+	p.MembershipPollID = field.NewUint(table, "membership_poll_id")
+	p.VoterTelegramId = field.NewInt64(table, "voter_telegram_id")
+	p.Option = field.NewInt(table, "option")
+	p.CreatedAt = field.NewTime(table, "created_at")
+	p.UpdatedAt = field.NewTime(table, "updated_at")
+
+	p.fillFieldMap()
+
+	return p
+}
+
+func (p *pollVote) WithContext(ctx context.Context) *pollVoteDo { return p.pollVoteDo.WithContext(ctx) }
+
+func (p pollVote) TableName() string { return p.pollVoteDo.TableName() }
+
+func (p pollVote) Alias() string { return p.pollVoteDo.Alias() }
+
+func (p *pollVote) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := p.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (p *pollVote) fillFieldMap() {
+	p.fieldMap = make(map[string]field.Expr, 6)
+	p.fieldMap["membership_poll_id"] = p.MembershipPollID
+	p.fieldMap["voter_telegram_id"] = p.VoterTelegramId
+	p.fieldMap["option"] = p.Option
+	p.fieldMap["created_at"] = p.CreatedAt
+	p.fieldMap["updated_at"] = p.UpdatedAt
+
+}
+
+func (p pollVote) clone(db *gorm.DB) pollVote {
+	p.pollVoteDo.ReplaceDB(db)
+	return p
+}
+
+type pollVoteBelongsToMembershipPoll struct {
+	db *gorm.DB
+
+	field.RelationField
+
+	Form struct {
+		field.RelationField
+		User struct {
+			field.RelationField
+		}
+	}
+	User struct {
+		field.RelationField
+	}
+}
+
+func (a pollVoteBelongsToMembershipPoll) Where(conds ...field.Expr) *pollVoteBelongsToMembershipPoll {
+	if len(conds) == 0 {
+		return &a
+	}
+
+	exprs := make([]clause.Expression, 0, len(conds))
+	for _, cond := range conds {
+		exprs = append(exprs, cond.BeCond().(clause.Expression))
+	}
+	a.db = a.db.Clauses(clause.Where{Exprs: exprs})
+	return &a
+}
+
+func (a pollVoteBelongsToMembershipPoll) WithContext(ctx context.Context) *pollVoteBelongsToMembershipPoll {
+	a.db = a.db.WithContext(ctx)
+	return &a
+}
+
+func (a pollVoteBelongsToMembershipPoll) Model(m *model.PollVote) *pollVoteBelongsToMembershipPollTx {
+	return &pollVoteBelongsToMembershipPollTx{a.db.Model(m).Association(a.Name())}
+}
+
+type pollVoteBelongsToMembershipPollTx struct{ tx *gorm.Association }
+
+func (a pollVoteBelongsToMembershipPollTx) Find() (result *model.MembershipPoll, err error) {
+	return result, a.tx.Find(&result)
+}
+
+func (a pollVoteBelongsToMembershipPollTx) Append(values ...*model.MembershipPoll) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Append(targetValues...)
+}
+
+func (a pollVoteBelongsToMembershipPollTx) Replace(values ...*model.MembershipPoll) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Replace(targetValues...)
+}
+
+func (a pollVoteBelongsToMembershipPollTx) Delete(values ...*model.MembershipPoll) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Delete(targetValues...)
+}
+
+func (a pollVoteBelongsToMembershipPollTx) Clear() error {
+	return a.tx.Clear()
+}
+
+func (a pollVoteBelongsToMembershipPollTx) Count() int64 {
+	return a.tx.Count()
+}
+
+type pollVoteDo struct{ gen.DO }
+
+func (p pollVoteDo) Debug() *pollVoteDo {
+	return p.withDO(p.DO.Debug())
+}
+
+func (p pollVoteDo) WithContext(ctx context.Context) *pollVoteDo {
+	return p.withDO(p.DO.WithContext(ctx))
+}
+
+func (p pollVoteDo) ReadDB() *pollVoteDo {
+	return p.Clauses(dbresolver.Read)
+}
+
+func (p pollVoteDo) WriteDB() *pollVoteDo {
+	return p.Clauses(dbresolver.Write)
+}
+
+func (p pollVoteDo) Clauses(conds ...clause.Expression) *pollVoteDo {
+	return p.withDO(p.DO.Clauses(conds...))
+}
+
+func (p pollVoteDo) Returning(value interface{}, columns ...string) *pollVoteDo {
+	return p.withDO(p.DO.Returning(value, columns...))
+}
+
+func (p pollVoteDo) Not(conds ...gen.Condition) *pollVoteDo {
+	return p.withDO(p.DO.Not(conds...))
+}
+
+func (p pollVoteDo) Or(conds ...gen.Condition) *pollVoteDo {
+	return p.withDO(p.DO.Or(conds...))
+}
+
+func (p pollVoteDo) Select(conds ...field.Expr) *pollVoteDo {
+	return p.withDO(p.DO.Select(conds...))
+}
+
+func (p pollVoteDo) Where(conds ...gen.Condition) *pollVoteDo {
+	return p.withDO(p.DO.Where(conds...))
+}
+
+func (p pollVoteDo) Exists(subquery interface{ UnderlyingDB() *gorm.DB }) *pollVoteDo {
+	return p.Where(field.CompareSubQuery(field.ExistsOp, nil, subquery.UnderlyingDB()))
+}
+
+func (p pollVoteDo) Order(conds ...field.Expr) *pollVoteDo {
+	return p.withDO(p.DO.Order(conds...))
+}
+
+func (p pollVoteDo) Distinct(cols ...field.Expr) *pollVoteDo {
+	return p.withDO(p.DO.Distinct(cols...))
+}
+
+func (p pollVoteDo) Omit(cols ...field.Expr) *pollVoteDo {
+	return p.withDO(p.DO.Omit(cols...))
+}
+
+func (p pollVoteDo) Join(table schema.Tabler, on ...field.Expr) *pollVoteDo {
+	return p.withDO(p.DO.Join(table, on...))
+}
+
+func (p pollVoteDo) LeftJoin(table schema.Tabler, on ...field.Expr) *pollVoteDo {
+	return p.withDO(p.DO.LeftJoin(table, on...))
+}
+
+func (p pollVoteDo) RightJoin(table schema.Tabler, on ...field.Expr) *pollVoteDo {
+	return p.withDO(p.DO.RightJoin(table, on...))
+}
+
+func (p pollVoteDo) Group(cols ...field.Expr) *pollVoteDo {
+	return p.withDO(p.DO.Group(cols...))
+}
+
+func (p pollVoteDo) Having(conds ...gen.Condition) *pollVoteDo {
+	return p.withDO(p.DO.Having(conds...))
+}
+
+func (p pollVoteDo) Limit(limit int) *pollVoteDo {
+	return p.withDO(p.DO.Limit(limit))
+}
+
+func (p pollVoteDo) Offset(offset int) *pollVoteDo {
+	return p.withDO(p.DO.Offset(offset))
+}
+
+func (p pollVoteDo) Scopes(funcs ...func(gen.Dao) gen.Dao) *pollVoteDo {
+	return p.withDO(p.DO.Scopes(funcs...))
+}
+
+func (p pollVoteDo) Unscoped() *pollVoteDo {
+	return p.withDO(p.DO.Unscoped())
+}
+
+func (p pollVoteDo) Create(values ...*model.PollVote) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return p.DO.Create(values)
+}
+
+func (p pollVoteDo) CreateInBatches(values []*model.PollVote, batchSize int) error {
+	return p.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (p pollVoteDo) Save(values ...*model.PollVote) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return p.DO.Save(values)
+}
+
+func (p pollVoteDo) First() (*model.PollVote, error) {
+	if result, err := p.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.PollVote), nil
+	}
+}
+
+func (p pollVoteDo) Take() (*model.PollVote, error) {
+	if result, err := p.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.PollVote), nil
+	}
+}
+
+func (p pollVoteDo) Last() (*model.PollVote, error) {
+	if result, err := p.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.PollVote), nil
+	}
+}
+
+func (p pollVoteDo) Find() ([]*model.PollVote, error) {
+	result, err := p.DO.Find()
+	return result.([]*model.PollVote), err
+}
+
+func (p pollVoteDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.PollVote, err error) {
+	buf := make([]*model.PollVote, 0, batchSize)
+	err = p.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (p pollVoteDo) FindInBatches(result *[]*model.PollVote, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return p.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (p pollVoteDo) Attrs(attrs ...field.AssignExpr) *pollVoteDo {
+	return p.withDO(p.DO.Attrs(attrs...))
+}
+
+func (p pollVoteDo) Assign(attrs ...field.AssignExpr) *pollVoteDo {
+	return p.withDO(p.DO.Assign(attrs...))
+}
+
+func (p pollVoteDo) Joins(fields ...field.RelationField) *pollVoteDo {
+	for _, _f := range fields {
+		p = *p.withDO(p.DO.Joins(_f))
+	}
+	return &p
+}
+
+func (p pollVoteDo) Preload(fields ...field.RelationField) *pollVoteDo {
+	for _, _f := range fields {
+		p = *p.withDO(p.DO.Preload(_f))
+	}
+	return &p
+}
+
+func (p pollVoteDo) FirstOrInit() (*model.PollVote, error) {
+	if result, err := p.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.PollVote), nil
+	}
+}
+
+func (p pollVoteDo) FirstOrCreate() (*model.PollVote, error) {
+	if result, err := p.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.PollVote), nil
+	}
+}
+
+func (p pollVoteDo) FindByPage(offset int, limit int) (result []*model.PollVote, count int64, err error) {
+	result, err = p.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = p.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (p pollVoteDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = p.Count()
+	if err != nil {
+		return
+	}
+
+	err = p.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (p pollVoteDo) Scan(result interface{}) (err error) {
+	return p.DO.Scan(result)
+}
+
+func (p pollVoteDo) Delete(models ...*model.PollVote) (result gen.ResultInfo, err error) {
+	return p.DO.Delete(models)
+}
+
+func (p *pollVoteDo) withDO(do gen.Dao) *pollVoteDo {
+	p.DO = *do.(*gen.DO)
+	return p
+}
@@ -0,0 +1,326 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"beneburg/pkg/database/model"
+)
+
+func newProcessedUpdate(db *gorm.DB) processedUpdate {
+	_processedUpdate := processedUpdate{}
+
+	_processedUpdate.processedUpdateDo.UseDB(db)
+	_processedUpdate.processedUpdateDo.UseModel(&model.ProcessedUpdate{})
+
+	tableName := _processedUpdate.processedUpdateDo.TableName()
+	_processedUpdate.ALL = field.NewAsterisk(tableName)
+	_processedUpdate.UpdateID = field.NewInt(tableName, "update_id")
+	_processedUpdate.CreatedAt = field.NewTime(tableName, "created_at")
+
+	_processedUpdate.fillFieldMap()
+
+	return _processedUpdate
+}
+
+type processedUpdate struct {
+	processedUpdateDo processedUpdateDo
+
+	ALL       field.Asterisk
+	UpdateID  field.Int
+	CreatedAt field.Time
+
+	fieldMap map[string]field.Expr
+}
+
+func (p processedUpdate) Table(newTableName string) *processedUpdate {
+	p.processedUpdateDo.UseTable(newTableName)
+	return p.updateTableName(newTableName)
+}
+
+func (p processedUpdate) As(alias string) *processedUpdate {
+	p.processedUpdateDo.DO = *(p.processedUpdateDo.As(alias).(*gen.DO))
+	return p.updateTableName(alias)
+}
+
+func (p *processedUpdate) updateTableName(table string) *processedUpdate {
+	p.ALL = field.NewAsterisk(table)
+	p.UpdateID = field.NewInt(table, "update_id")
+	p.CreatedAt = field.NewTime(table, "created_at")
+
+	p.fillFieldMap()
+
+	return p
+}
+
+func (p *processedUpdate) WithContext(ctx context.Context) *processedUpdateDo {
+	return p.processedUpdateDo.WithContext(ctx)
+}
+
+func (p processedUpdate) TableName() string { return p.processedUpdateDo.TableName() }
+
+func (p processedUpdate) Alias() string { return p.processedUpdateDo.Alias() }
+
+func (p *processedUpdate) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := p.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (p *processedUpdate) fillFieldMap() {
+	p.fieldMap = make(map[string]field.Expr, 2)
+	p.fieldMap["update_id"] = p.UpdateID
+	p.fieldMap["created_at"] = p.CreatedAt
+}
+
+func (p processedUpdate) clone(db *gorm.DB) processedUpdate {
+	p.processedUpdateDo.ReplaceDB(db)
+	return p
+}
+
+type processedUpdateDo struct{ gen.DO }
+
+func (p processedUpdateDo) Debug() *processedUpdateDo {
+	return p.withDO(p.DO.Debug())
+}
+
+func (p processedUpdateDo) WithContext(ctx context.Context) *processedUpdateDo {
+	return p.withDO(p.DO.WithContext(ctx))
+}
+
+func (p processedUpdateDo) ReadDB() *processedUpdateDo {
+	return p.Clauses(dbresolver.Read)
+}
+
+func (p processedUpdateDo) WriteDB() *processedUpdateDo {
+	return p.Clauses(dbresolver.Write)
+}
+
+func (p processedUpdateDo) Clauses(conds ...clause.Expression) *processedUpdateDo {
+	return p.withDO(p.DO.Clauses(conds...))
+}
+
+func (p processedUpdateDo) Returning(value interface{}, columns ...string) *processedUpdateDo {
+	return p.withDO(p.DO.Returning(value, columns...))
+}
+
+func (p processedUpdateDo) Not(conds ...gen.Condition) *processedUpdateDo {
+	return p.withDO(p.DO.Not(conds...))
+}
+
+func (p processedUpdateDo) Or(conds ...gen.Condition) *processedUpdateDo {
+	return p.withDO(p.DO.Or(conds...))
+}
+
+func (p processedUpdateDo) Select(conds ...field.Expr) *processedUpdateDo {
+	return p.withDO(p.DO.Select(conds...))
+}
+
+func (p processedUpdateDo) Where(conds ...gen.Condition) *processedUpdateDo {
+	return p.withDO(p.DO.Where(conds...))
+}
+
+func (p processedUpdateDo) Exists(subquery interface{ UnderlyingDB() *gorm.DB }) *processedUpdateDo {
+	return p.Where(field.CompareSubQuery(field.ExistsOp, nil, subquery.UnderlyingDB()))
+}
+
+func (p processedUpdateDo) Order(conds ...field.Expr) *processedUpdateDo {
+	return p.withDO(p.DO.Order(conds...))
+}
+
+func (p processedUpdateDo) Distinct(cols ...field.Expr) *processedUpdateDo {
+	return p.withDO(p.DO.Distinct(cols...))
+}
+
+func (p processedUpdateDo) Omit(cols ...field.Expr) *processedUpdateDo {
+	return p.withDO(p.DO.Omit(cols...))
+}
+
+func (p processedUpdateDo) Join(table schema.Tabler, on ...field.Expr) *processedUpdateDo {
+	return p.withDO(p.DO.Join(table, on...))
+}
+
+func (p processedUpdateDo) LeftJoin(table schema.Tabler, on ...field.Expr) *processedUpdateDo {
+	return p.withDO(p.DO.LeftJoin(table, on...))
+}
+
+func (p processedUpdateDo) RightJoin(table schema.Tabler, on ...field.Expr) *processedUpdateDo {
+	return p.withDO(p.DO.RightJoin(table, on...))
+}
+
+func (p processedUpdateDo) Group(cols ...field.Expr) *processedUpdateDo {
+	return p.withDO(p.DO.Group(cols...))
+}
+
+func (p processedUpdateDo) Having(conds ...gen.Condition) *processedUpdateDo {
+	return p.withDO(p.DO.Having(conds...))
+}
+
+func (p processedUpdateDo) Limit(limit int) *processedUpdateDo {
+	return p.withDO(p.DO.Limit(limit))
+}
+
+func (p processedUpdateDo) Offset(offset int) *processedUpdateDo {
+	return p.withDO(p.DO.Offset(offset))
+}
+
+func (p processedUpdateDo) Scopes(funcs ...func(gen.Dao) gen.Dao) *processedUpdateDo {
+	return p.withDO(p.DO.Scopes(funcs...))
+}
+
+func (p processedUpdateDo) Unscoped() *processedUpdateDo {
+	return p.withDO(p.DO.Unscoped())
+}
+
+func (p processedUpdateDo) Create(values ...*model.ProcessedUpdate) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return p.DO.Create(values)
+}
+
+func (p processedUpdateDo) CreateInBatches(values []*model.ProcessedUpdate, batchSize int) error {
+	return p.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (p processedUpdateDo) Save(values ...*model.ProcessedUpdate) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return p.DO.Save(values)
+}
+
+func (p processedUpdateDo) First() (*model.ProcessedUpdate, error) {
+	if result, err := p.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.ProcessedUpdate), nil
+	}
+}
+
+func (p processedUpdateDo) Take() (*model.ProcessedUpdate, error) {
+	if result, err := p.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.ProcessedUpdate), nil
+	}
+}
+
+func (p processedUpdateDo) Last() (*model.ProcessedUpdate, error) {
+	if result, err := p.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.ProcessedUpdate), nil
+	}
+}
+
+func (p processedUpdateDo) Find() ([]*model.ProcessedUpdate, error) {
+	result, err := p.DO.Find()
+	return result.([]*model.ProcessedUpdate), err
+}
+
+func (p processedUpdateDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.ProcessedUpdate, err error) {
+	buf := make([]*model.ProcessedUpdate, 0, batchSize)
+	err = p.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (p processedUpdateDo) FindInBatches(result *[]*model.ProcessedUpdate, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return p.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (p processedUpdateDo) Attrs(attrs ...field.AssignExpr) *processedUpdateDo {
+	return p.withDO(p.DO.Attrs(attrs...))
+}
+
+func (p processedUpdateDo) Assign(attrs ...field.AssignExpr) *processedUpdateDo {
+	return p.withDO(p.DO.Assign(attrs...))
+}
+
+func (p processedUpdateDo) Joins(fields ...field.RelationField) *processedUpdateDo {
+	for _, _f := range fields {
+		p = *p.withDO(p.DO.Joins(_f))
+	}
+	return &p
+}
+
+func (p processedUpdateDo) Preload(fields ...field.RelationField) *processedUpdateDo {
+	for _, _f := range fields {
+		p = *p.withDO(p.DO.Preload(_f))
+	}
+	return &p
+}
+
+func (p processedUpdateDo) FirstOrInit() (*model.ProcessedUpdate, error) {
+	if result, err := p.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.ProcessedUpdate), nil
+	}
+}
+
+func (p processedUpdateDo) FirstOrCreate() (*model.ProcessedUpdate, error) {
+	if result, err := p.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.ProcessedUpdate), nil
+	}
+}
+
+func (p processedUpdateDo) FindByPage(offset int, limit int) (result []*model.ProcessedUpdate, count int64, err error) {
+	result, err = p.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = p.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (p processedUpdateDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = p.Count()
+	if err != nil {
+		return
+	}
+
+	err = p.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (p processedUpdateDo) Scan(result interface{}) (err error) {
+	return p.DO.Scan(result)
+}
+
+func (p processedUpdateDo) Delete(models ...*model.ProcessedUpdate) (result gen.ResultInfo, err error) {
+	return p.DO.Delete(models)
+}
+
+func (p *processedUpdateDo) withDO(do gen.Dao) *processedUpdateDo {
+	p.DO = *do.(*gen.DO)
+	return p
+}
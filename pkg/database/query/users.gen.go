@@ -36,6 +36,10 @@ func newUser(db *gorm.DB) user {
 	_user.FirstName = field.NewString(tableName, "first_name")
 	_user.LastName = field.NewString(tableName, "last_name")
 	_user.Status = field.NewString(tableName, "status")
+	_user.BirthdayOptOut = field.NewBool(tableName, "birthday_opt_out")
+	_user.ApplyReminders = field.NewInt32(tableName, "apply_reminders")
+	_user.ApplyRemindedAt = field.NewTime(tableName, "apply_reminded_at")
+	_user.JoinedAt = field.NewTime(tableName, "joined_at")
 
 	_user.fillFieldMap()
 
@@ -45,16 +49,20 @@ func newUser(db *gorm.DB) user {
 type user struct {
 	userDo userDo
 
-	ALL        field.Asterisk
-	ID         field.Uint
-	CreatedAt  field.Time
-	UpdatedAt  field.Time
-	DeletedAt  field.Field
-	TelegramID field.Int64
-	Username   field.String
-	FirstName  field.String
-	LastName   field.String
-	Status     field.String
+	ALL             field.Asterisk
+	ID              field.Uint
+	CreatedAt       field.Time
+	UpdatedAt       field.Time
+	DeletedAt       field.Field
+	TelegramID      field.Int64
+	Username        field.String
+	FirstName       field.String
+	LastName        field.String
+	Status          field.String
+	BirthdayOptOut  field.Bool
+	ApplyReminders  field.Int32
+	ApplyRemindedAt field.Time
+	JoinedAt        field.Time
 
 	fieldMap map[string]field.Expr
 }
@@ -80,6 +88,10 @@ func (u *user) updateTableName(table string) *user {
 	u.FirstName = field.NewString(table, "first_name")
 	u.LastName = field.NewString(table, "last_name")
 	u.Status = field.NewString(table, "status")
+	u.BirthdayOptOut = field.NewBool(table, "birthday_opt_out")
+	u.ApplyReminders = field.NewInt32(table, "apply_reminders")
+	u.ApplyRemindedAt = field.NewTime(table, "apply_reminded_at")
+	u.JoinedAt = field.NewTime(table, "joined_at")
 
 	u.fillFieldMap()
 
@@ -102,7 +114,7 @@ func (u *user) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (u *user) fillFieldMap() {
-	u.fieldMap = make(map[string]field.Expr, 9)
+	u.fieldMap = make(map[string]field.Expr, 13)
 	u.fieldMap["id"] = u.ID
 	u.fieldMap["created_at"] = u.CreatedAt
 	u.fieldMap["updated_at"] = u.UpdatedAt
@@ -112,6 +124,10 @@ func (u *user) fillFieldMap() {
 	u.fieldMap["first_name"] = u.FirstName
 	u.fieldMap["last_name"] = u.LastName
 	u.fieldMap["status"] = u.Status
+	u.fieldMap["birthday_opt_out"] = u.BirthdayOptOut
+	u.fieldMap["apply_reminders"] = u.ApplyReminders
+	u.fieldMap["apply_reminded_at"] = u.ApplyRemindedAt
+	u.fieldMap["joined_at"] = u.JoinedAt
 }
 
 func (u user) clone(db *gorm.DB) user {
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"beneburg/pkg/database"
+	"beneburg/pkg/database/model"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// RequirePermission aborts the request unless the current user's role has the permission.
+// It must be used after TokenAuth.Auth.
+func RequirePermission(db database.Database, logger *zap.Logger, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := c.MustGet("currentUser").(*model.User)
+		role, err := db.GetUserRole(c, user.TelegramID)
+		if err != nil {
+			logger.Named("RequirePermission").Error("Error getting user role", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if !model.HasPermission(role, permission) {
+			logger.Named("RequirePermission").Info("Permission denied", zap.Int64("telegram_id", user.TelegramID), zap.String("permission", permission))
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Set("currentRole", role)
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	mock_database "beneburg/pkg/database/mocks"
+	"beneburg/pkg/database/model"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_RequirePermission(t *testing.T) {
+	logger := zap.L()
+	newRouter := func(dbMock *mock_database.MockDatabase) *gin.Engine {
+		r := gin.New()
+		r.Use(func(ctx *gin.Context) {
+			ctx.Set("currentUser", &model.User{TelegramID: 10})
+		})
+		r.Use(RequirePermission(dbMock, logger, model.PermissionReviewForms))
+		r.GET("/test", func(ctx *gin.Context) {
+			ctx.String(200, ctx.GetString("currentRole"))
+		})
+		return r
+	}
+	t.Run("Permission granted", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+		dbMock := mock_database.NewMockDatabase(controller)
+		dbMock.EXPECT().GetUserRole(gomock.Any(), int64(10)).Return(model.RoleModerator, nil).Times(1)
+
+		newRouter(dbMock).ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, model.RoleModerator, w.Body.String())
+	})
+	t.Run("Permission denied", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+		dbMock := mock_database.NewMockDatabase(controller)
+		dbMock.EXPECT().GetUserRole(gomock.Any(), int64(10)).Return(model.RoleMember, nil).Times(1)
+
+		newRouter(dbMock).ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,389 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"encoding/json"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"strings"
+)
+
+const (
+	applyStepConfirm = "confirm"
+
+	applyMinAge = 14
+	applyMaxAge = 120
+)
+
+// applyStep is a question of the form filled out in the bot chat.
+type applyStep struct {
+	name     string
+	question string
+	optional bool
+	// set validates the answer and stores it in the form. The error is shown to the user
+	// by Templator.ApplyAnswerInvalid.
+	set func(form *model.Form, answer string) error
+	// choices are offered as buttons instead of a text answer.
+	choices []applyChoice
+}
+
+type applyChoice struct {
+	value string
+	text  string
+}
+
+var (
+	errApplyEmptyAnswer   = errors.New("answer is empty")
+	errApplyUnknownChoice = errors.New("answer is not one of the choices")
+)
+
+// hasChoice tells whether the value is one of the buttons of the step. The choices come
+// in unsigned callback data, so a modified client can send any value.
+func (s applyStep) hasChoice(value string) bool {
+	for _, choice := range s.choices {
+		if choice.value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// applySteps follow the fields of profile.gohtml in the same order.
+var applySteps = []applyStep{
+	{
+		name:     "name",
+		question: "Как к тебе обращаться?",
+		set: func(form *model.Form, answer string) error {
+			form.Name = answer
+			return nil
+		},
+	},
+	{
+		// The step keeps the name of the age question it replaced, names are stored in drafts
+		name:     "age",
+		question: "Когда у тебя день рождения? Напиши ДД.ММ.ГГГГ или только ДД.ММ, если не хочешь показывать возраст.",
+		optional: true,
+		set:      SetFormBirthdate,
+	},
+	{
+		name:     "gender",
+		question: "Какой у тебя пол?",
+		set: func(form *model.Form, answer string) error {
+			form.Gender = answer
+			return nil
+		},
+		choices: []applyChoice{
+			{value: "male", text: "Мужской"},
+			{value: "female", text: "Женский"},
+			{value: "nonbinary", text: "Небинарный"},
+			{value: "undefined", text: "Не указывать"},
+		},
+	},
+	{
+		name:     "about",
+		question: "Расскажи о себе.",
+		set: func(form *model.Form, answer string) error {
+			form.About = &answer
+			return nil
+		},
+	},
+	{
+		name:     "hobbies",
+		question: "Какие у тебя хобби?",
+		optional: true,
+		set: func(form *model.Form, answer string) error {
+			form.Hobbies = &answer
+			return nil
+		},
+	},
+	{
+		name:     "work",
+		question: "Где и кем работаешь?",
+		optional: true,
+		set: func(form *model.Form, answer string) error {
+			form.Work = &answer
+			return nil
+		},
+	},
+	{
+		name:     "education",
+		question: "Где учишься или учился?",
+		optional: true,
+		set: func(form *model.Form, answer string) error {
+			form.Education = &answer
+			return nil
+		},
+	},
+	{
+		name:     "cover_letter",
+		question: "Почему хочешь к нам?",
+		set: func(form *model.Form, answer string) error {
+			form.CoverLetter = &answer
+			return nil
+		},
+	},
+	{
+		name:     "contacts",
+		question: "Как с тобой связаться, кроме Telegram?",
+		optional: true,
+		set: func(form *model.Form, answer string) error {
+			form.Contacts = &answer
+			return nil
+		},
+	},
+}
+
+// applyStepIndex returns len(applySteps) for the confirmation and -1 for an unknown step.
+func applyStepIndex(name string) int {
+	if name == applyStepConfirm {
+		return len(applySteps)
+	}
+	for i, step := range applySteps {
+		if step.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func applyStepName(index int) string {
+	if index >= len(applySteps) {
+		return applyStepConfirm
+	}
+	return applySteps[index].name
+}
+
+func (b *botManager) applyStepKeyboard(index int) *tgbotapi.InlineKeyboardMarkup {
+	name := applyStepName(index)
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if index < len(applySteps) {
+		for _, choice := range applySteps[index].choices {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(choice.text, b.callbacks.MustEncode(actionApplyChoice, name, choice.value))))
+		}
+	} else {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Отправить", b.callbacks.MustEncode(actionApplySubmit, name))))
+	}
+	var controls []tgbotapi.InlineKeyboardButton
+	if index > 0 {
+		controls = append(controls, tgbotapi.NewInlineKeyboardButtonData("Назад", b.callbacks.MustEncode(actionApplyBack, name)))
+	}
+	if index < len(applySteps) && applySteps[index].optional {
+		controls = append(controls, tgbotapi.NewInlineKeyboardButtonData("Пропустить", b.callbacks.MustEncode(actionApplySkip, name)))
+	}
+	controls = append(controls, tgbotapi.NewInlineKeyboardButtonData("Отменить", b.callbacks.MustEncode(actionApplyCancel, name)))
+	rows = append(rows, controls)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &keyboard
+}
+
+func (b *botManager) processApplyCommand(message *tgbotapi.Message, _ commandArgs) {
+	b.logger.Named("processApplyCommand").Debug("Processing apply command")
+	if message.From == nil {
+		b.logger.Named("processApplyCommand").Error("Message's From is nil")
+		return
+	}
+	if b.hasFormOnReview(message.From.ID) {
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.ApplyFormOnReview()))
+		return
+	}
+	draft, form, err := b.loadFormDraft(message.From.ID)
+	if err == nil {
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.ApplyResumed()))
+		b.askApplyStep(message.Chat.ID, applyStepIndex(draft.Step), form)
+		return
+	}
+	if !errors.Is(err, noRecordError) {
+		b.logger.Named("processApplyCommand").Error("Error while loading draft", zap.Error(err))
+		return
+	}
+	form = &model.Form{Gender: "undefined"}
+	err = b.saveFormDraft(message.From.ID, 0, form)
+	if err != nil {
+		b.logger.Named("processApplyCommand").Error("Error while saving draft", zap.Error(err))
+		return
+	}
+	b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.ApplyStarted()))
+	b.askApplyStep(message.Chat.ID, 0, form)
+}
+
+func (b *botManager) hasFormOnReview(telegramID int64) bool {
+	form, err := b.db.GetLastForm(b.ctx, telegramID)
+	if err != nil {
+		if !errors.Is(err, noRecordError) {
+			b.logger.Named("hasFormOnReview").Error("Error while getting last form", zap.Error(err))
+		}
+		return false
+	}
+	return form.Status == model.FormStatusNew
+}
+
+func (b *botManager) loadFormDraft(telegramID int64) (*model.FormDraft, *model.Form, error) {
+	draft, err := b.db.GetFormDraft(b.ctx, telegramID)
+	if err != nil {
+		return nil, nil, err
+	}
+	form := &model.Form{}
+	err = json.Unmarshal([]byte(draft.Form), form)
+	if err != nil {
+		return nil, nil, err
+	}
+	return draft, form, nil
+}
+
+func (b *botManager) saveFormDraft(telegramID int64, index int, form *model.Form) error {
+	data, err := json.Marshal(form)
+	if err != nil {
+		return err
+	}
+	return b.db.SaveFormDraft(b.ctx, &model.FormDraft{
+		UserTelegramId: telegramID,
+		Step:           applyStepName(index),
+		Form:           string(data),
+	})
+}
+
+func (b *botManager) askApplyStep(chatID int64, index int, form *model.Form) {
+	var msg tgbotapi.MessageConfig
+	if index >= len(applySteps) {
+		msg = tgbotapi.NewMessage(chatID, b.templator.ApplyConfirm(form))
+		msg.ParseMode = tgbotapi.ModeHTML
+	} else {
+		msg = tgbotapi.NewMessage(chatID, applySteps[index].question)
+	}
+	msg.ReplyMarkup = b.applyStepKeyboard(index)
+	b.send(msg)
+}
+
+// moveApplyStep saves the draft at the step and asks its question.
+func (b *botManager) moveApplyStep(chatID int64, telegramID int64, index int, form *model.Form) {
+	err := b.saveFormDraft(telegramID, index, form)
+	if err != nil {
+		b.logger.Named("moveApplyStep").Error("Error while saving draft", zap.Error(err))
+		return
+	}
+	b.askApplyStep(chatID, index, form)
+}
+
+// processApplyAnswer takes a text answer to the current question. It returns false if
+// the user is not filling out a form.
+func (b *botManager) processApplyAnswer(message *tgbotapi.Message) bool {
+	if message.From == nil {
+		return false
+	}
+	draft, form, err := b.loadFormDraft(message.From.ID)
+	if err != nil {
+		if !errors.Is(err, noRecordError) {
+			b.logger.Named("processApplyAnswer").Error("Error while loading draft", zap.Error(err))
+		}
+		return false
+	}
+	index := applyStepIndex(draft.Step)
+	if index < 0 || index >= len(applySteps) || len(applySteps[index].choices) > 0 {
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.ApplyUseButtons()))
+		return true
+	}
+	answer := strings.TrimSpace(message.Text)
+	if answer == "" {
+		err = errApplyEmptyAnswer
+	} else {
+		err = applySteps[index].set(form, answer)
+	}
+	if err != nil {
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.ApplyAnswerInvalid(err)))
+		return true
+	}
+	b.moveApplyStep(message.Chat.ID, message.From.ID, index+1, form)
+	return true
+}
+
+// processApplyCallbackQuery handles the buttons of the form questions, value is set only by
+// the "choice" action. Buttons of the questions that are already answered are ignored.
+func (b *botManager) processApplyCallbackQuery(query *tgbotapi.CallbackQuery, action string, stepName string, value string) callbackAnswer {
+	b.logger.Named("processApplyCallbackQuery").Debug("Processing apply callback query", zap.String("action", action), zap.String("step", stepName))
+	chatID := query.Message.Chat.ID
+	telegramID := query.From.ID
+
+	draft, form, err := b.loadFormDraft(telegramID)
+	if err != nil {
+		b.clearKeyboard(chatID, query.Message.MessageID)
+		if !errors.Is(err, noRecordError) {
+			b.logger.Named("processApplyCallbackQuery").Error("Error while loading draft", zap.Error(err))
+			return b.callbackFailed()
+		}
+		return callbackAnswer{text: b.templator.ApplyStepAnswered()}
+	}
+	if draft.Step != stepName {
+		b.logger.Named("processApplyCallbackQuery").Info("Button of another step", zap.String("step", draft.Step), zap.String("button_step", stepName))
+		return callbackAnswer{text: b.templator.ApplyStepAnswered()}
+	}
+	index := applyStepIndex(draft.Step)
+	messageID := query.Message.MessageID
+
+	// The keyboard is cleared only when the step is left, so a refused answer can be changed
+	switch action {
+	case "choice":
+		if index < 0 || index >= len(applySteps) {
+			b.logger.Named("processApplyCallbackQuery").Error("Apply choice is invalid", zap.String("step", stepName), zap.String("value", value))
+			return b.callbackFailed()
+		}
+		step := applySteps[index]
+		if !step.hasChoice(value) {
+			b.logger.Named("processApplyCallbackQuery").Info("Unknown apply choice", zap.String("step", stepName), zap.String("value", value))
+			return callbackAnswer{text: b.templator.ApplyAnswerInvalid(errApplyUnknownChoice), alert: true}
+		}
+		err = step.set(form, value)
+		if err != nil {
+			return callbackAnswer{text: b.templator.ApplyAnswerInvalid(err), alert: true}
+		}
+		b.clearKeyboard(chatID, messageID)
+		b.moveApplyStep(chatID, telegramID, index+1, form)
+	case "skip":
+		if index < 0 || index >= len(applySteps) || !applySteps[index].optional {
+			return callbackAnswer{}
+		}
+		b.clearKeyboard(chatID, messageID)
+		b.moveApplyStep(chatID, telegramID, index+1, form)
+	case "back":
+		if index <= 0 {
+			return callbackAnswer{}
+		}
+		b.clearKeyboard(chatID, messageID)
+		b.moveApplyStep(chatID, telegramID, index-1, form)
+	case "cancel":
+		err = b.db.DeleteFormDraft(b.ctx, telegramID)
+		if err != nil {
+			b.logger.Named("processApplyCallbackQuery").Error("Error while deleting draft", zap.Error(err))
+			return b.callbackFailed()
+		}
+		b.clearKeyboard(chatID, messageID)
+		b.send(tgbotapi.NewMessage(chatID, b.templator.ApplyCancelled()))
+	case "submit":
+		return b.submitFormDraft(chatID, messageID, telegramID, form)
+	}
+	return callbackAnswer{}
+}
+
+func (b *botManager) submitFormDraft(chatID int64, messageID int, telegramID int64, form *model.Form) callbackAnswer {
+	if b.hasFormOnReview(telegramID) {
+		b.clearKeyboard(chatID, messageID)
+		b.send(tgbotapi.NewMessage(chatID, b.templator.ApplyFormOnReview()))
+		return callbackAnswer{}
+	}
+	user, err := b.db.GetUserByTelegramID(b.ctx, telegramID)
+	if err != nil {
+		b.logger.Named("submitFormDraft").Error("Error while getting user", zap.Error(err))
+		return b.callbackFailed()
+	}
+	err = SubmitForm(b.ctx, b.db, b.send, b.templator, b.callbacks, user, form)
+	if err != nil {
+		b.logger.Named("submitFormDraft").Error("Error while submitting form", zap.Error(err))
+		return b.callbackFailed()
+	}
+	b.clearKeyboard(chatID, messageID)
+	err = b.db.DeleteFormDraft(b.ctx, telegramID)
+	if err != nil {
+		b.logger.Named("submitFormDraft").Error("Error while deleting draft", zap.Error(err))
+	}
+	return b.callbackDone()
+}
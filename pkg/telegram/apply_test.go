@@ -0,0 +1,44 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_applySteps(t *testing.T) {
+	t.Run("Step names are unique", func(t *testing.T) {
+		for i, step := range applySteps {
+			assert.Equal(t, i, applyStepIndex(step.name))
+		}
+		assert.Equal(t, len(applySteps), applyStepIndex(applyStepConfirm))
+		assert.Equal(t, -1, applyStepIndex("unknown"))
+	})
+
+	t.Run("Only declared choices are accepted", func(t *testing.T) {
+		gender := applySteps[applyStepIndex("gender")]
+		assert.True(t, gender.hasChoice("female"))
+		assert.False(t, gender.hasChoice("<b>forged</b>"))
+		assert.False(t, applySteps[applyStepIndex("name")].hasChoice(""))
+	})
+
+	t.Run("Birthdate is validated", func(t *testing.T) {
+		birthdate := applySteps[applyStepIndex("age")]
+		form := &model.Form{}
+		assert.ErrorIs(t, birthdate.set(form, "двадцать"), errBirthdateFormat)
+		assert.ErrorIs(t, birthdate.set(form, "31.02.1995"), errBirthdateFormat)
+		assert.ErrorIs(t, birthdate.set(form, fmt.Sprintf("01.01.%d", time.Now().Year()-3)), errBirthdateAge)
+		assert.Nil(t, form.BirthDay)
+		assert.NoError(t, birthdate.set(form, "18.10."))
+		assert.Equal(t, "18.10", form.Birthdate())
+		assert.NoError(t, birthdate.set(form, "29.02"))
+		assert.Equal(t, "29.02", form.Birthdate())
+		assert.Nil(t, form.CurrentAge())
+		assert.NoError(t, birthdate.set(form, "18.10.1995"))
+		assert.Equal(t, "18.10.1995", form.Birthdate())
+		assert.Equal(t, int32(30), *form.AgeAt(time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC)))
+		assert.Equal(t, int32(31), *form.AgeAt(time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)))
+	})
+}
@@ -0,0 +1,138 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"strings"
+)
+
+// banTarget returns the user the ban command is about and the rest of its arguments.
+// The user is the sender of the replied message, not the author of a forwarded one, or the
+// first argument otherwise.
+func (b *botManager) banTarget(message *tgbotapi.Message, args commandArgs) (*model.User, string, error) {
+	if reply := message.ReplyToMessage; reply != nil {
+		from := reply.From
+		if from == nil || from.IsBot {
+			return nil, "", noRecordError
+		}
+		user, err := b.db.GetUserByTelegramID(b.ctx, from.ID)
+		return user, strings.TrimSpace(args["@username|id"] + " " + args["причина"]), err
+	}
+	user, err := b.findUser(args["@username|id"])
+	return user, args["причина"], err
+}
+
+func (b *botManager) processBanCommand(message *tgbotapi.Message, args commandArgs) {
+	b.logger.Named("processBanCommand").Debug("Processing ban command")
+	if message.ReplyToMessage == nil && args["@username|id"] == "" {
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.BanCommandNoTarget()))
+		return
+	}
+	user, reasonText, err := b.banTarget(message, args)
+	if err != nil {
+		if errors.Is(err, noRecordError) {
+			b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.InfoCommandNoUser()))
+			return
+		}
+		b.logger.Named("processBanCommand").Error("Error while getting user", zap.Error(err))
+		return
+	}
+	bannerRole := b.userRole(message.From.ID)
+	currentRole := b.userRole(user.TelegramID)
+	if user.TelegramID == message.From.ID || (currentRole != model.RoleMember && !model.CanManageRole(bannerRole, currentRole)) {
+		b.logger.Named("processBanCommand").Info("Ban is not allowed", zap.Int64("telegram_id", user.TelegramID), zap.String("role", currentRole))
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.CommandNotAllowed()))
+		return
+	}
+	var reason *string
+	if reasonText != "" {
+		reason = &reasonText
+	}
+	// The ban rejects the open poll, its message in the group is stopped after the ban
+	poll, err := b.db.GetOpenMembershipPollByUser(b.ctx, user.TelegramID)
+	if err != nil && !errors.Is(err, noRecordError) {
+		b.logger.Named("processBanCommand").Error("Error while getting open poll", zap.Error(err))
+	}
+	result, err := b.db.BanUser(b.ctx, user.TelegramID, message.From.ID, reason)
+	if err != nil {
+		b.logger.Named("processBanCommand").Error("Error while banning user", zap.Error(err))
+		return
+	}
+	if result.RowsAffected == 0 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.templator.UserAlreadyBanned(user))
+		msg.ParseMode = tgbotapi.ModeHTML
+		b.send(msg)
+		return
+	}
+	b.logger.Named("processBanCommand").Info("User banned", zap.Int64("telegram_id", user.TelegramID), zap.Int64("banned_by", message.From.ID), zap.Stringp("reason", reason))
+
+	if currentRole != model.RoleMember {
+		err = b.db.SetUserRole(b.ctx, user.TelegramID, model.RoleMember, message.From.ID)
+		if err != nil {
+			b.logger.Named("processBanCommand").Error("Error while revoking role", zap.Error(err))
+		} else {
+			b.syncUserCommands(user.TelegramID, model.RoleMember)
+		}
+	}
+	b.revokeUserInviteLinks(user.TelegramID)
+	if poll != nil {
+		b.send(tgbotapi.NewStopPoll(poll.ChatID, poll.MessageID))
+	}
+	b.send(tgbotapi.BanChatMemberConfig{
+		ChatMemberConfig: tgbotapi.ChatMemberConfig{
+			ChatID: b.groupID,
+			UserID: user.TelegramID,
+		},
+	})
+	userMsg := tgbotapi.NewMessage(user.TelegramID, b.templator.BannedReply(reason))
+	userMsg.ParseMode = tgbotapi.ModeHTML
+	userMsg.DisableWebPagePreview = true
+	b.send(userMsg)
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.templator.UserBanned(user, reason))
+	msg.ParseMode = tgbotapi.ModeHTML
+	b.send(msg)
+}
+
+func (b *botManager) processUnbanCommand(message *tgbotapi.Message, args commandArgs) {
+	b.logger.Named("processUnbanCommand").Debug("Processing unban command")
+	if message.ReplyToMessage == nil && args["@username|id"] == "" {
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.BanCommandNoTarget()))
+		return
+	}
+	user, _, err := b.banTarget(message, args)
+	if err != nil {
+		if errors.Is(err, noRecordError) {
+			b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.InfoCommandNoUser()))
+			return
+		}
+		b.logger.Named("processUnbanCommand").Error("Error while getting user", zap.Error(err))
+		return
+	}
+	result, err := b.db.UnbanUser(b.ctx, user.TelegramID, message.From.ID)
+	if err != nil {
+		b.logger.Named("processUnbanCommand").Error("Error while unbanning user", zap.Error(err))
+		return
+	}
+	if result.RowsAffected == 0 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.templator.UserNotBanned(user))
+		msg.ParseMode = tgbotapi.ModeHTML
+		b.send(msg)
+		return
+	}
+	b.logger.Named("processUnbanCommand").Info("User unbanned", zap.Int64("telegram_id", user.TelegramID), zap.Int64("unbanned_by", message.From.ID))
+
+	// Only lifts the ban in the group, the user has to join again
+	b.send(tgbotapi.UnbanChatMemberConfig{
+		ChatMemberConfig: tgbotapi.ChatMemberConfig{
+			ChatID: b.groupID,
+			UserID: user.TelegramID,
+		},
+		OnlyIfBanned: true,
+	})
+	b.send(tgbotapi.NewMessage(user.TelegramID, b.templator.UnbannedReply()))
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.templator.UserUnbanned(user))
+	msg.ParseMode = tgbotapi.ModeHTML
+	b.send(msg)
+}
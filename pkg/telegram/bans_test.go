@@ -0,0 +1,71 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gen"
+	"gorm.io/gorm"
+	"strconv"
+	"testing"
+)
+
+func Test_banTarget(t *testing.T) {
+	t.Run("Reply to a forwarded message targets its sender", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		member := &model.User{TelegramID: 10}
+		dbMock.EXPECT().GetUserByTelegramID(gomock.Any(), int64(10)).Return(member, nil)
+		message := &tgbotapi.Message{ReplyToMessage: &tgbotapi.Message{
+			From:        &tgbotapi.User{ID: 10},
+			ForwardFrom: &tgbotapi.User{ID: 20},
+		}}
+		got, reason, err := b.banTarget(message, commandArgs{"@username|id": "спам", "причина": "в чате"})
+		assert.NoError(t, err)
+		assert.Equal(t, member, got)
+		assert.Equal(t, "спам в чате", reason)
+	})
+}
+
+func Test_processBanCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		bannerRole  string
+		targetID    int64
+		targetRole  string
+		wantAllowed bool
+	}{
+		{name: "Moderator bans a member", bannerRole: model.RoleModerator, targetID: 10, targetRole: model.RoleMember, wantAllowed: true},
+		{name: "Moderator can't ban an admin", bannerRole: model.RoleModerator, targetID: 10, targetRole: model.RoleAdmin},
+		{name: "Admin can't ban an admin", bannerRole: model.RoleAdmin, targetID: 10, targetRole: model.RoleAdmin},
+		{name: "Nobody bans themselves", bannerRole: model.RoleOwner, targetID: 1, targetRole: model.RoleOwner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, dbMock := newTestBot(t)
+			target := &model.User{TelegramID: tt.targetID}
+			dbMock.EXPECT().GetUserByTelegramID(gomock.Any(), tt.targetID).Return(target, nil)
+			dbMock.EXPECT().GetUserRole(gomock.Any(), int64(1)).Return(tt.bannerRole, nil).AnyTimes()
+			dbMock.EXPECT().GetUserRole(gomock.Any(), tt.targetID).Return(tt.targetRole, nil).AnyTimes()
+			var texts []string
+			dbMock.EXPECT().CreateOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, message *model.OutboxMessage) (*model.OutboxMessage, error) {
+				if message.Type == "MessageConfig" {
+					msg, err := decodeOutboxMessage(message)
+					assert.NoError(t, err)
+					texts = append(texts, msg.(tgbotapi.MessageConfig).Text)
+				}
+				return message, nil
+			}).AnyTimes()
+			if tt.wantAllowed {
+				dbMock.EXPECT().GetOpenMembershipPollByUser(gomock.Any(), tt.targetID).Return(nil, gorm.ErrRecordNotFound)
+				dbMock.EXPECT().BanUser(gomock.Any(), tt.targetID, int64(1), nil).Return(&gen.ResultInfo{RowsAffected: 1}, nil)
+				dbMock.EXPECT().GetActiveInviteLinksByUser(gomock.Any(), tt.targetID).Return(nil, nil)
+			}
+			message := &tgbotapi.Message{From: &tgbotapi.User{ID: 1}, Chat: &tgbotapi.Chat{ID: 1}}
+			b.processBanCommand(message, commandArgs{"@username|id": strconv.FormatInt(tt.targetID, 10)})
+			assert.NotEmpty(t, texts)
+			assert.Equal(t, !tt.wantAllowed, texts[len(texts)-1] == b.templator.CommandNotAllowed())
+		})
+	}
+}
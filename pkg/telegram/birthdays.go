@@ -0,0 +1,113 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"errors"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	birthdayCheckInterval = time.Hour
+	// birthdayGreetingHour is the local hour after which the group is congratulated.
+	birthdayGreetingHour = 10
+	// birthdaysGreetedKey keeps the last greeted date, so a restart doesn't greet twice.
+	birthdaysGreetedKey = "birthdays_greeted"
+
+	birthdayArgOn  = "on"
+	birthdayArgOff = "off"
+)
+
+var (
+	errBirthdateFormat = errors.New("birthdate format is invalid")
+	errBirthdateAge    = errors.New("age is out of range")
+)
+
+// SetFormBirthdate validates the birthdate of the form filled out in the bot or on the website.
+// The bot explains the error with Templator.ApplyAnswerInvalid.
+func SetFormBirthdate(form *model.Form, value string) error {
+	// The form is changed only when the whole value is valid
+	checked := &model.Form{}
+	err := checked.SetBirthdate(value)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errBirthdateFormat, err)
+	}
+	if age := checked.CurrentAge(); checked.BirthYear != nil && (*age < applyMinAge || *age > applyMaxAge) {
+		return fmt.Errorf("%w: %d", errBirthdateAge, *age)
+	}
+	form.BirthDay, form.BirthMonth, form.BirthYear = checked.BirthDay, checked.BirthMonth, checked.BirthYear
+	return nil
+}
+
+// birthdayDays returns the days of the month whose birthdays are celebrated on the date.
+// February 29 birthdays are celebrated on February 28 in common years.
+func birthdayDays(date time.Time) []int {
+	days := []int{date.Day()}
+	if date.Month() == time.February && date.Day() == 28 && date.AddDate(0, 0, 1).Month() == time.March {
+		days = append(days, 29)
+	}
+	return days
+}
+
+// greetBirthdays congratulates the members whose birthday is today once a day.
+func (b *botManager) greetBirthdays() {
+	now := time.Now()
+	if now.Hour() < birthdayGreetingHour {
+		return
+	}
+	// The local date at midnight UTC, so days are exactly 24 hours apart whatever the time zone
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if !b.claimPeriod(birthdaysGreetedKey, today, 24*time.Hour) {
+		return
+	}
+	forms, err := b.db.GetBirthdayForms(b.ctx, int(now.Month()), birthdayDays(now)...)
+	if err != nil {
+		b.logger.Named("greetBirthdays").Error("Error while getting birthdays", zap.Error(err))
+		return
+	}
+	b.logger.Named("greetBirthdays").Info("Greeting birthdays", zap.Int("count", len(forms)))
+	if len(forms) == 0 {
+		return
+	}
+	msg := tgbotapi.NewMessage(b.groupID, b.templator.BirthdayGreeting(forms))
+	msg.ParseMode = tgbotapi.ModeHTML
+	b.send(msg)
+}
+
+// processBirthdayCommand turns the user's birthday greetings on or off, without an argument
+// it tells whether they are on.
+func (b *botManager) processBirthdayCommand(message *tgbotapi.Message, args commandArgs) {
+	b.logger.Named("processBirthdayCommand").Debug("Processing birthday command")
+	if message.From == nil {
+		b.logger.Named("processBirthdayCommand").Error("Message's From is nil")
+		return
+	}
+	var optOut bool
+	switch args["on|off"] {
+	case "":
+		user, err := b.db.GetUserByTelegramID(b.ctx, message.From.ID)
+		if err != nil {
+			b.logger.Named("processBirthdayCommand").Error("Error while getting user", zap.Error(err))
+			return
+		}
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.BirthdayGreetingsState(user.BirthdayOptOut)))
+		return
+	case birthdayArgOn:
+		optOut = false
+	case birthdayArgOff:
+		optOut = true
+	default:
+		c, _ := b.commands.lookup("birthday")
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.CommandUsage(c.usage())))
+		return
+	}
+	_, err := b.db.SetBirthdayOptOut(b.ctx, message.From.ID, optOut)
+	if err != nil {
+		b.logger.Named("processBirthdayCommand").Error("Error while saving birthday opt-out", zap.Error(err))
+		return
+	}
+	b.logger.Named("processBirthdayCommand").Info("Birthday greetings changed", zap.Int64("telegram_id", message.From.ID), zap.Bool("opt_out", optOut))
+	b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.BirthdayGreetingsChanged(optOut)))
+}
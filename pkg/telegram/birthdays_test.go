@@ -0,0 +1,18 @@
+package telegram
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_birthdayDays(t *testing.T) {
+	t.Run("Usual day", func(t *testing.T) {
+		assert.Equal(t, []int{18}, birthdayDays(time.Date(2026, time.October, 18, 10, 0, 0, 0, time.UTC)))
+	})
+	t.Run("February 29 is celebrated on February 28 in common years", func(t *testing.T) {
+		assert.Equal(t, []int{28, 29}, birthdayDays(time.Date(2026, time.February, 28, 10, 0, 0, 0, time.UTC)))
+		assert.Equal(t, []int{28}, birthdayDays(time.Date(2028, time.February, 28, 10, 0, 0, 0, time.UTC)))
+		assert.Equal(t, []int{29}, birthdayDays(time.Date(2028, time.February, 29, 10, 0, 0, 0, time.UTC)))
+	})
+}
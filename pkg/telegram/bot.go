@@ -1,85 +1,218 @@
 package telegram
 
 import (
+	"beneburg/pkg/callback"
 	"beneburg/pkg/database"
 	"beneburg/pkg/database/model"
 	"context"
 	"errors"
 	"fmt"
+	"github.com/gin-gonic/gin"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"go.uber.org/zap"
-	"golang.org/x/time/rate"
-	"strings"
+	"gorm.io/gorm"
+	"sync"
 	"time"
 )
 
 //go:generate mockgen -source=bot.go -destination=./mocks/mock_bot.go -package=mock_telegram
 type Bot interface {
 	Start()
+	// Stop stops receiving updates and the background jobs, finishes the updates being
+	// processed and flushes the outgoing messages. It returns ctx's error if ctx is done before that.
+	Stop(ctx context.Context) error
 	GetSendFunc() TelegramBotSendFunc
 	SetLogger(logger *zap.Logger)
+	SetWebhook(url string, secretToken string)
+	SetPollRules(rules PollRules)
+	SetReminderRules(rules ReminderRules)
+	SetDigestRules(rules DigestRules)
+	SetCallbackCodec(codec *callback.Codec)
+	UpdatesMode() string
+	RegisterWebhook(router gin.IRouter)
 }
 
 type TelegramBotSendFunc func(message tgbotapi.Chattable)
 
 type botManager struct {
-	bot        TgBotAPI
-	db         database.Database
-	templator  Templator
-	adminID    int64
-	groupID    int64
-	inviteLink string
+	bot         TgBotAPI
+	db          database.Database
+	templator   Templator
+	adminID     int64
+	groupID     int64
+	webhook     *webhookConfig
+	commands    *commandRegistry
+	dispatcher  *updateDispatcher
+	scheduler   *sendScheduler
+	offsets     *offsetTracker
+	pollRules   PollRules
+	callbacks   *callback.Codec
+	reminders   ReminderRules
+	digestRules DigestRules
+	// signedCallbacksSince tells the buttons sent before they were signed, see decodeCallbackQuery.
+	signedCallbacksSince time.Time
 
 	updatesChan  chan tgbotapi.Update
-	messagesChan chan tgbotapi.Chattable
+	messagesChan chan outgoingMessage
+	outboxWakeup chan struct{}
+	// reconcileRequests gets the chat IDs of users who asked for a reconciliation run.
+	reconcileRequests chan int64
+
+	// ctx lives until the bot is stopped, receiveCtx only until updates stop being received,
+	// jobsCtx until the background jobs are stopped, see startJob.
+	ctx           context.Context
+	cancel        context.CancelFunc
+	receiveCtx    context.Context
+	stopReceiving context.CancelFunc
+	jobsCtx       context.Context
+	stopJobs      context.CancelFunc
+	jobs          *sync.WaitGroup
+	stopOutbox    chan struct{}
+	stopSending   chan struct{}
+	updatesDone   chan struct{}
+	outboxDone    chan struct{}
+	sendingDone   chan struct{}
 
-	ctx    context.Context
 	logger *zap.Logger
 }
 
-func NewBot(ctx context.Context, bot TgBotAPI, db database.Database, adminID int64, groupID int64, inviteLink string, domain string) Bot {
-	return &botManager{
-		bot:          bot,
-		templator:    NewTemplator(domain),
-		db:           db,
-		ctx:          ctx,
-		adminID:      adminID,
-		groupID:      groupID,
-		inviteLink:   inviteLink,
-		updatesChan:  make(chan tgbotapi.Update, 60),
-		messagesChan: make(chan tgbotapi.Chattable, 60),
-	}
+func NewBot(ctx context.Context, bot TgBotAPI, db database.Database, adminID int64, groupID int64, domain string) Bot {
+	ctx, cancel := context.WithCancel(ctx)
+	receiveCtx, stopReceiving := context.WithCancel(ctx)
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	b := &botManager{
+		bot:               bot,
+		templator:         NewTemplator(domain),
+		db:                db,
+		ctx:               ctx,
+		cancel:            cancel,
+		receiveCtx:        receiveCtx,
+		stopReceiving:     stopReceiving,
+		jobsCtx:           jobsCtx,
+		stopJobs:          stopJobs,
+		jobs:              &sync.WaitGroup{},
+		stopOutbox:        make(chan struct{}),
+		stopSending:       make(chan struct{}),
+		updatesDone:       make(chan struct{}),
+		outboxDone:        make(chan struct{}),
+		sendingDone:       make(chan struct{}),
+		adminID:           adminID,
+		groupID:           groupID,
+		updatesChan:       make(chan tgbotapi.Update, 60),
+		messagesChan:      make(chan outgoingMessage, 60),
+		outboxWakeup:      make(chan struct{}, 1),
+		reconcileRequests: make(chan int64, 1),
+		scheduler:         newSendScheduler(),
+		offsets:           newOffsetTracker(),
+		pollRules:         DefaultPollRules,
+		reminders:         DefaultReminderRules,
+		digestRules:       DefaultDigestRules,
+		callbacks:         NewCallbackCodec(randomCallbackSecret()),
+	}
+	b.registerCommands()
+	b.dispatcher = newUpdateDispatcher(updateWorkersCount, updateWorkerQueueLen, updateHandlerTimeout, func(ctx context.Context, update tgbotapi.Update) {
+		defer b.finishUpdate(update.UpdateID)
+		b.withContext(ctx).processUpdateOnce(update)
+	})
+	return b
 }
 
-var noRecordError = fmt.Errorf("record not found")
+// withContext returns a shallow copy of the bot which uses ctx in handlers instead of b.ctx.
+func (b *botManager) withContext(ctx context.Context) *botManager {
+	handlerBot := *b
+	handlerBot.ctx = ctx
+	return &handlerBot
+}
+
+var noRecordError = gorm.ErrRecordNotFound
 
 func (b *botManager) SetLogger(logger *zap.Logger) {
 	b.logger = logger
 }
 
-// TODO: will be parallelized
 func (b *botManager) GetSendFunc() TelegramBotSendFunc {
 	return b.send
 }
 
 func (b *botManager) Start() {
-	go b.startGettingUpdates()
+	b.loadSignedCallbacksSince()
+	switch b.UpdatesMode() {
+	case UpdatesModeWebhook:
+		go b.startWebhook()
+	default:
+		go b.startGettingUpdates()
+	}
 	go b.startProcessingUpdates()
 	go b.startProcessingMessages()
+	go b.startProcessingOutbox()
+	b.runPeriodically("deleteProcessedUpdates", processedUpdatesCleanupEvery, (*botManager).deleteProcessedUpdates)
+	b.runPeriodically("closeDuePolls", pollCheckInterval, (*botManager).closeDuePolls)
+	b.startJob((*botManager).startReconcilingMembership)
+	b.runPeriodically("revokeExpiredInviteLinks", inviteLinkCheckInterval, (*botManager).revokeExpiredInviteLinks)
+	b.runPeriodically("greetBirthdays", birthdayCheckInterval, (*botManager).greetBirthdays)
+	b.runPeriodically("remindPendingReviews", reminderCheckInterval, (*botManager).remindPendingReviews)
+	b.runPeriodically("remindToApply", reminderCheckInterval, (*botManager).remindToApply)
+	b.runPeriodically("postDigest", digestCheckInterval, (*botManager).postDigest)
+	b.bootstrapOwner()
+	b.syncCommands()
+}
+
+func (b *botManager) Stop(ctx context.Context) error {
+	defer b.cancel()
+	b.logger.Named("Stop").Info("Stopping bot")
+	b.stopJobs()
+	b.stopReceiving()
+	if b.UpdatesMode() == UpdatesModeWebhook {
+		b.stopWebhook()
+	}
+	// The jobs are waited for before the outbox stops, so the messages they stored are sent
+	jobsDone := make(chan struct{})
+	go func() {
+		b.jobs.Wait()
+		close(jobsDone)
+	}()
+	steps := []struct {
+		name string
+		stop chan struct{}
+		done chan struct{}
+	}{
+		{name: "updates", done: b.updatesDone},
+		{name: "jobs", done: jobsDone},
+		{name: "outbox", stop: b.stopOutbox, done: b.outboxDone},
+		{name: "sending", stop: b.stopSending, done: b.sendingDone},
+	}
+	for _, step := range steps {
+		if step.stop != nil {
+			close(step.stop)
+		}
+		select {
+		case <-step.done:
+			b.logger.Named("Stop").Debug("Stopped", zap.String("step", step.name))
+		case <-ctx.Done():
+			b.logger.Named("Stop").Warn("Timeout while stopping bot", zap.String("step", step.name))
+			return ctx.Err()
+		}
+	}
+	b.logger.Named("Stop").Info("Bot stopped")
+	return nil
 }
 
 // TODO: add With() with context to all loggings
 func (b *botManager) startGettingUpdates() {
-	var offset = 0
+	b.dropWebhookIfSet()
+	b.offsets.reset(b.loadUpdateOffset())
 	for {
 		select {
-		case <-b.ctx.Done():
+		case <-b.receiveCtx.Done():
 			return
 		default:
 		}
+		// The offset confirms only the processed updates, so the updates being processed
+		// are returned again and skipped
 		updates, err := b.bot.GetUpdates(tgbotapi.UpdateConfig{
-			Offset:  offset,
-			Timeout: 60,
+			Offset:         b.offsets.offset(),
+			Timeout:        60,
+			AllowedUpdates: allowedUpdates,
 		})
 		b.logger.Named("startGettingUpdates").Debug("Got updates", zap.Int("updates_count", len(updates)))
 		if err != nil {
@@ -88,56 +221,53 @@ func (b *botManager) startGettingUpdates() {
 			time.Sleep(time.Second * 3)
 			continue
 		}
+		received := 0
 		for _, update := range updates {
-			if update.UpdateID >= offset {
-				offset = update.UpdateID + 1
-				b.updatesChan <- update
+			if !b.offsets.receive(update.UpdateID) {
+				continue
+			}
+			received++
+			select {
+			case b.updatesChan <- update:
+			case <-b.receiveCtx.Done():
+				// Not confirmed to Telegram, so it will be received again after restart
+				return
 			}
 		}
-	}
-}
-
-func (b *botManager) startProcessingUpdates() {
-	for {
-		select {
-		case <-b.ctx.Done():
-			return
-		case update := <-b.updatesChan:
-			b.processUpdate(update)
+		if len(updates) > 0 && received == 0 {
+			// Otherwise getUpdates returns the same updates immediately
+			select {
+			case <-b.offsets.progress:
+			case <-time.After(inFlightUpdatesDelay):
+			case <-b.receiveCtx.Done():
+				return
+			}
 		}
 	}
 }
 
-func (b *botManager) startProcessingMessages() {
-	limiter := rate.NewLimiter(30, 30)
+func (b *botManager) startProcessingUpdates() {
+	defer close(b.updatesDone)
+	b.dispatcher.start(b.ctx, b.logger)
+	defer b.dispatcher.stop()
 	for {
 		select {
-		case <-b.ctx.Done():
-			return
-		case message := <-b.messagesChan:
-			_ = limiter.Wait(b.ctx)
-			_, err := b.bot.Request(message)
-			switch typedErr := err.(type) {
-			case *tgbotapi.Error:
-				b.logger.Named("startProcessingMessages").Error("Error while sending message", zap.Error(typedErr), zap.Any("message", message))
-				if typedErr.Code == 429 {
-					b.logger.Named("startProcessingMessages").Info("Too many requests, sleeping for 1 second")
-					time.Sleep(time.Second)
-					b.messagesChan <- message
+		case <-b.receiveCtx.Done():
+			// Process the updates that were received before stopping
+			for {
+				select {
+				case update := <-b.updatesChan:
+					b.dispatcher.dispatch(b.ctx, update)
+				default:
+					return
 				}
-			case nil:
-				continue
-			default:
-				b.logger.Named("startProcessingMessages").Error("Error while sending message", zap.Error(err), zap.Any("message", message))
 			}
+		case update := <-b.updatesChan:
+			b.dispatcher.dispatch(b.ctx, update)
 		}
 	}
 }
 
-func (b *botManager) send(message tgbotapi.Chattable) {
-	b.messagesChan <- message
-}
-
 func (b *botManager) processUpdate(update tgbotapi.Update) {
 	b.logger.Named("processUpdate").Info("Processing update", zap.Int("update_id", update.UpdateID))
 	if update.Message != nil {
@@ -153,37 +283,37 @@ func (b *botManager) processUpdate(update tgbotapi.Update) {
 		b.processChatJoinRequest(update.ChatJoinRequest)
 		return
 	}
+	if update.InlineQuery != nil {
+		b.processInlineQuery(update.InlineQuery)
+		return
+	}
+	if update.Poll != nil {
+		b.processPoll(update.Poll)
+		return
+	}
+	if update.PollAnswer != nil {
+		b.processPollAnswer(update.PollAnswer)
+		return
+	}
+	if update.ChatMember != nil {
+		b.processChatMember(update.ChatMember)
+		return
+	}
+	if update.MyChatMember != nil {
+		b.processMyChatMember(update.MyChatMember)
+		return
+	}
 }
 
 func (b *botManager) processMessage(message *tgbotapi.Message) {
 	b.logger.Named("processMessage").Debug("Processing message", zap.String("chat_title", message.Chat.Title))
 	if from := message.From; from != nil && !from.IsBot {
 		b.logger.Named("processMessage").Debug("Processing message from user", zap.String("username", from.UserName), zap.String("first_name", from.FirstName), zap.String("last_name", from.LastName))
-		user := model.User{
-			FirstName: from.FirstName,
-			LastName: func() *string {
-				if from.LastName != "" {
-					return &from.LastName
-				} else {
-					return nil
-				}
-			}(),
-			TelegramID: from.ID,
-			Username: func() *string {
-				if from.UserName != "" {
-					return &from.UserName
-				} else {
-					return nil
-				}
-			}(),
-			Status: func() string {
-				if message.Chat != nil && message.Chat.ID == b.groupID {
-					return model.UserStatusActive
-				}
-				return model.UserStatusNew
-			}(),
+		status := model.UserStatusNew
+		if message.Chat != nil && message.Chat.ID == b.groupID {
+			status = model.UserStatusActive
 		}
-		_, err := b.db.UpdateOrCreateUser(b.ctx, &user)
+		_, err := b.db.UpdateOrCreateUser(b.ctx, userFromTelegram(from, status))
 		if err != nil {
 			b.logger.Named("processMessage").Error("Error while updating user", zap.Error(err))
 			return
@@ -202,7 +332,15 @@ func (b *botManager) processMessage(message *tgbotapi.Message) {
 func (b *botManager) processPrivateMessage(message *tgbotapi.Message) {
 	b.logger.Named("processPrivateMessage").Debug("Processing private message")
 	if message.IsCommand() {
-		b.processPrivateCommand(message)
+		b.processCommand(message, scopePrivate)
+		return
+	}
+
+	if message.ReplyToMessage != nil && b.processRejectionReason(message) {
+		return
+	}
+
+	if b.processApplyAnswer(message) {
 		return
 	}
 
@@ -215,7 +353,7 @@ func (b *botManager) processPrivateMessage(message *tgbotapi.Message) {
 func (b *botManager) processGroupMessage(message *tgbotapi.Message) {
 	b.logger.Named("processGroupMessage").Debug("Processing group message")
 	if message.IsCommand() {
-		b.processGroupCommand(message)
+		b.processCommand(message, scopeGroup)
 		return
 	}
 	if message.NewChatMembers != nil {
@@ -223,43 +361,18 @@ func (b *botManager) processGroupMessage(message *tgbotapi.Message) {
 		return
 	}
 
-	if message.LeftChatMember != nil {
-		b.processLeftChatMember(message)
-		return
-	}
-
 	if message.Text == "ping" {
 		b.processPing(message)
 		return
 	}
 }
 
-func (b *botManager) processGroupCommand(message *tgbotapi.Message) {
-	b.logger.Named("processGroupCommand").Debug("Processing group command", zap.String("command", message.Command()))
-	if message.Command() == "info" {
-		b.processInfoCommand(message)
-		return
-	}
-}
-
 func (b *botManager) processPing(message *tgbotapi.Message) {
 	b.logger.Named("processPing").Debug("Processing ping")
 	b.send(tgbotapi.NewMessage(message.Chat.ID, "pong"))
 }
 
-func (b *botManager) processPrivateCommand(message *tgbotapi.Message) {
-	b.logger.Named("processPrivateCommand").Debug("Processing private command", zap.String("command", message.Command()))
-	if message.Command() == "start" {
-		b.processStartCommand(message)
-		return
-	}
-	if message.Command() == "login" {
-		b.processLoginCommand(message)
-		return
-	}
-}
-
-func (b *botManager) processInfoCommand(message *tgbotapi.Message) {
+func (b *botManager) processInfoCommand(message *tgbotapi.Message, _ commandArgs) {
 	b.logger.Named("processInfoCommand").Debug("Processing info command")
 	if message.ReplyToMessage == nil || message.ReplyToMessage.From == nil {
 		b.logger.Named("processInfoCommand").Debug("No reply to message")
@@ -268,7 +381,7 @@ func (b *botManager) processInfoCommand(message *tgbotapi.Message) {
 	}
 	user, err := b.db.GetUserByTelegramID(b.ctx, message.ReplyToMessage.From.ID)
 	if err != nil {
-		if errors.As(err, &noRecordError) {
+		if errors.Is(err, noRecordError) {
 			b.logger.Named("processInfoCommand").Info("No user found in db", zap.Error(err))
 			b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.InfoCommandNoUser()))
 			return
@@ -279,7 +392,7 @@ func (b *botManager) processInfoCommand(message *tgbotapi.Message) {
 	b.logger.Named("processInfoCommand").Debug("User found", zap.Stringp("username", user.Username), zap.Int64("telegram_id", user.TelegramID))
 	form, err := b.db.GetActualForm(b.ctx, user.TelegramID)
 	if err != nil {
-		if errors.As(err, &noRecordError) {
+		if errors.Is(err, noRecordError) {
 			b.logger.Named("processInfoCommand").Info("No form found in db", zap.Error(err))
 			b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.InfoCommandNoUser()))
 			return
@@ -293,12 +406,24 @@ func (b *botManager) processInfoCommand(message *tgbotapi.Message) {
 	b.send(msg)
 }
 
-func (b *botManager) processLoginCommand(message *tgbotapi.Message) {
+func (b *botManager) processLoginCommand(message *tgbotapi.Message, _ commandArgs) {
 	b.logger.Named("processLoginCommand").Debug("Processing login command")
 	if message.From == nil {
 		b.logger.Named("processLoginCommand").Error("Message's From is nil")
 		return
 	}
+	user, err := b.db.GetUserByTelegramID(b.ctx, message.From.ID)
+	if err != nil && !errors.Is(err, noRecordError) {
+		b.logger.Named("processLoginCommand").Error("Error while getting user", zap.Error(err))
+		return
+	}
+	if user != nil && user.Status == model.UserStatusBanned {
+		b.logger.Named("processLoginCommand").Info("User is banned", zap.Int64("telegram_id", user.TelegramID))
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.templator.LoginBanned())
+		msg.ParseMode = tgbotapi.ModeHTML
+		b.send(msg)
+		return
+	}
 	token, err := b.db.CreateOrProlongToken(b.ctx, message.From.ID)
 	if err != nil {
 		b.logger.Named("processLoginCommand").Error("Error while creating token", zap.Error(err))
@@ -310,7 +435,7 @@ func (b *botManager) processLoginCommand(message *tgbotapi.Message) {
 	b.send(msg)
 }
 
-func (b *botManager) processStartCommand(message *tgbotapi.Message) {
+func (b *botManager) processStartCommand(message *tgbotapi.Message, _ commandArgs) {
 	b.logger.Named("processStartCommand").Debug("Processing start command")
 	if message.From == nil {
 		b.logger.Named("processStartCommand").Error("Message's From is nil")
@@ -324,6 +449,11 @@ func (b *botManager) processStartCommand(message *tgbotapi.Message) {
 
 func (b *botManager) processCallbackQuery(query *tgbotapi.CallbackQuery) {
 	b.logger.Named("processCallbackQuery").Debug("Processing callback query", zap.String("data", query.Data))
+	// The query is answered whatever happens below, otherwise the button keeps spinning
+	answer := b.callbackFailed()
+	defer func() {
+		b.answerCallbackQuery(query, answer)
+	}()
 	if query.Message == nil {
 		b.logger.Named("processCallbackQuery").Error("Callback query's message is nil")
 		return
@@ -333,104 +463,71 @@ func (b *botManager) processCallbackQuery(query *tgbotapi.CallbackQuery) {
 		return
 	}
 	if query.From == nil {
-		b.logger.Named("processFormCallbackQuery").Error("Callback query's message's from is nil")
+		b.logger.Named("processCallbackQuery").Error("Callback query's from is nil")
 		return
 	}
-	switch {
-	case strings.HasPrefix(query.Data, "admin:"):
-		if query.From.ID != b.adminID {
-			b.logger.Named("processCallbackQuery").Info("Callback query message is not from admin")
-			return
-		}
-		var data string
-		_, err := fmt.Sscanf(query.Data, "admin:%s", &data)
-		if err != nil {
-			b.logger.Named("processCallbackQuery").Error("Error while parsing admin callback query", zap.Error(err))
-			return
-		}
-		switch {
-		case strings.HasPrefix(data, "form:"):
-			b.processFormCallbackQuery(query.Message.Chat.ID, query.Message.MessageID, data)
-		case strings.HasPrefix(data, "user:"):
-			b.processUserCallbackQuery(query.Message.Chat.ID, query.Message.MessageID, data)
-		}
-	default:
+	payload := b.decodeCallbackQuery(query)
+	if payload == nil {
+		answer = callbackAnswer{text: b.templator.CallbackInvalid(), alert: true}
 		return
 	}
-
-	// TODO: make a request via bot.send
-	_, err := b.bot.MakeRequest("answerCallbackQuery", map[string]string{
-		"callback_query_id": query.ID,
-	})
-
-	if err != nil {
-		b.logger.Named("processFormCallbackQuery").Error("Error while answering callback query", zap.Error(err))
-		return
-	}
-
-}
-
-func (b *botManager) processFormCallbackQuery(chatID int64, messageID int, queryData string) {
-	b.logger.Named("processFormCallbackQuery").Debug("Processing form callback query", zap.Int64("chatID", chatID), zap.Int("messageID", messageID), zap.String("queryData", queryData))
-	var err error
-	var data string
-	_, err = fmt.Sscanf(queryData, "form:%s", &data)
-	if err != nil {
-		b.logger.Named("processFormCallbackQuery").Error("Error while parsing form callback query", zap.Error(err))
+	handler, ok := callbackHandlers[payload.Action]
+	if !ok {
+		b.logger.Named("processCallbackQuery").Error("Callback action has no handler", zap.String("action", payload.Action.Name))
 		return
 	}
-
-	if !strings.HasPrefix(data, "accept:") && !strings.HasPrefix(data, "reject:") {
-		b.logger.Named("processFormCallbackQuery").Error("Form callback query's data is invalid")
+	if handler.permission != "" && !b.hasPermission(query.From, handler.permission) {
+		b.logger.Named("processCallbackQuery").Info("User is not allowed to press the button", zap.Int64("telegram_id", query.From.ID), zap.String("action", payload.Action.Name))
+		answer = callbackAnswer{text: b.templator.CallbackNotAllowed(), alert: true}
 		return
 	}
+	answer = handler.handle(b, query, payload)
+}
 
-	var formID uint
-	var command string
-	switch {
-	case strings.HasPrefix(data, "accept:"):
-		command = "accept"
-		_, err = fmt.Sscanf(data, "accept:%d", &formID)
-	case strings.HasPrefix(data, "reject:"):
-		command = "reject"
-		_, err = fmt.Sscanf(data, "reject:%d", &formID)
-	}
-	if err != nil {
-		b.logger.Named("processFormCallbackQuery").Error("Error while parsing form callback query", zap.Error(err))
-		return
-	}
+// answerCallbackQuery is not stored in the outbox, an answer is useless after a restart.
+func (b *botManager) answerCallbackQuery(query *tgbotapi.CallbackQuery, answer callbackAnswer) {
+	callbackConfig := tgbotapi.NewCallback(query.ID, answer.text)
+	callbackConfig.ShowAlert = answer.alert
+	b.send(callbackConfig)
+}
 
+// acceptForm returns the answer for the reviewer.
+func (b *botManager) acceptForm(chatID int64, messageID int, formID uint, from int64) callbackAnswer {
+	b.logger.Named("acceptForm").Debug("Accepting form", zap.Int64("chatID", chatID), zap.Int("messageID", messageID), zap.Uint("formID", formID))
 	form, err := b.db.GetFormByID(b.ctx, formID)
 	if err != nil {
-		b.logger.Named("sendNewFormToGroup").Error("Error while getting form", zap.Error(err))
-		return
+		b.logger.Named("acceptForm").Error("Error while getting form", zap.Error(err))
+		return b.callbackFailed()
 	}
 	user, err := b.db.GetUserByTelegramID(b.ctx, form.UserTelegramId)
 	if err != nil {
-		b.logger.Named("sendNewFormToGroup").Error("Error while getting user", zap.Error(err))
-		return
+		b.logger.Named("acceptForm").Error("Error while getting user", zap.Error(err))
+		return b.callbackFailed()
 	}
 
-	switch command {
-	case "accept":
-		_, err = b.db.AcceptForm(b.ctx, formID)
-	case "reject":
-		_, err = b.db.RejectForm(b.ctx, formID)
-	}
+	result, err := b.db.AcceptForm(b.ctx, formID, from)
 	if err != nil {
-		b.logger.Named("processFormCallbackQuery").Error("Error while changing form's status", zap.Error(err))
-		return
-	}
+		b.logger.Named("acceptForm").Error("Error while changing form's status", zap.Error(err))
+		return b.callbackFailed()
+	}
+	if result.RowsAffected == 0 {
+		// Another reviewer was first or the update is processed again
+		b.logger.Named("acceptForm").Info("Form is already reviewed", zap.Uint("formID", formID), zap.String("status", form.Status))
+		return b.refuseFormDecision(chatID, messageID, formID)
+	}
+
+	b.sendNewFormToGroup(user, form)
+	acceptMsg := tgbotapi.NewMessage(user.TelegramID, b.templator.AcceptFormReply(user.Status))
+	b.send(acceptMsg)
+	now := time.Now()
+	form.Status = model.FormStatusAccepted
+	form.DecidedBy = &from
+	form.DecidedAt = &now
+	b.stampFormReview(chatID, messageID, user, form)
+	return callbackAnswer{text: b.templator.FormAccepted()}
+}
 
-	switch command {
-	case "accept":
-		b.sendNewFormToGroup(user, form)
-		acceptMsg := tgbotapi.NewMessage(user.TelegramID, b.templator.AcceptFormReply(user.Status))
-		b.send(acceptMsg)
-	case "reject":
-		rejectMsg := tgbotapi.NewMessage(user.TelegramID, b.templator.RejectFormReply(user.Status))
-		b.send(rejectMsg)
-	}
+func (b *botManager) clearKeyboard(chatID int64, messageID int) {
 	editKeyboard := tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}})
 	b.send(editKeyboard)
 }
@@ -441,99 +538,88 @@ func (b *botManager) sendNewFormToGroup(user *model.User, form *model.Form) {
 	msg := tgbotapi.NewMessage(b.groupID, b.templator.NewFormMessage(user, form))
 	msg.ParseMode = tgbotapi.ModeHTML
 
-	// TODO: make a request in goroutine with returning value
-	sentMessage, err := b.bot.Send(msg)
+	// The poll replies to the message, so the message doesn't go through the outbox
+	sentMessage, err := b.sendNow(msg)
 	if err != nil {
 		b.logger.Named("sendNewFormToGroup").Error("Error while sending new form to group", zap.Error(err))
-		return
 	}
 	if user.Status == model.UserStatusActive {
 		b.logger.Named("sendNewFormToGroup").Debug("User is not new, skipping poll")
 		return
 	}
-	poll := tgbotapi.NewPoll(b.groupID, b.templator.NewFormPoll(), "Принимаем", "Отклоняем")
-	poll.ReplyToMessageID = sentMessage.MessageID
-	acceptUser := tgbotapi.NewInlineKeyboardButtonData("Принять (для Эди)", fmt.Sprintf("admin:user:accept:%d", user.TelegramID))
-	rejectUser := tgbotapi.NewInlineKeyboardButtonData("Отклонить (для Эди)", fmt.Sprintf("admin:user:reject:%d", user.TelegramID))
-	poll.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(acceptUser, rejectUser))
-	b.send(poll)
-}
-
-func (b *botManager) processUserCallbackQuery(chatID int64, messageID int, queryData string) {
-	b.logger.Named("processUserCallbackQuery").Debug("Processing user callback query", zap.Int64("chatID", chatID), zap.Int("messageID", messageID), zap.String("queryData", queryData))
-	var err error
-	var data string
-	_, err = fmt.Sscanf(queryData, "user:%s", &data)
 	if err != nil {
-		b.logger.Named("processUserCallbackQuery").Error("Error while parsing user callback query", zap.Error(err))
-		return
-	}
-
-	if !strings.HasPrefix(data, "accept:") && !strings.HasPrefix(data, "reject:") {
-		b.logger.Named("processUserCallbackQuery").Error("User callback query's data is invalid")
-		return
-	}
-
-	var userID int64
-	var command string
-	switch {
-	case strings.HasPrefix(data, "accept:"):
-		command = "accept"
-		_, err = fmt.Sscanf(data, "accept:%d", &userID)
-	case strings.HasPrefix(data, "reject:"):
-		command = "reject"
-		_, err = fmt.Sscanf(data, "reject:%d", &userID)
-	}
-	if err != nil {
-		b.logger.Named("processUserCallbackQuery").Error("Error while parsing user callback query", zap.Error(err))
+		b.notifyPollNotSent(user)
 		return
 	}
+	b.sendMembershipPoll(user, form, sentMessage.MessageID)
+}
 
+// decideMembershipByButton handles the admin buttons under the membership poll.
+func (b *botManager) decideMembershipByButton(chatID int64, messageID int, userID int64, decision string, decidedBy int64) callbackAnswer {
+	b.logger.Named("decideMembershipByButton").Debug("Deciding membership", zap.Int64("chatID", chatID), zap.Int("messageID", messageID), zap.Int64("userID", userID), zap.String("decision", decision))
 	user, err := b.db.GetUserByTelegramID(b.ctx, userID)
 	if err != nil {
-		b.logger.Named("sendNewFormToGroup").Error("Error while getting user", zap.Error(err))
-		return
+		b.logger.Named("decideMembershipByButton").Error("Error while getting user", zap.Error(err))
+		return b.callbackFailed()
 	}
+	return b.decideMembership(chatID, messageID, user, decision, &decidedBy)
+}
 
-	switch command {
-	case "accept":
-		b.logger.Named("processUserCallbackQuery").Debug("Accepting user", zap.Int64("userTelegramID", user.TelegramID))
-		_, err := b.db.AcceptUser(b.ctx, user.ID)
-		if err != nil {
-			b.logger.Named("processUserCallbackQuery").Error("Error while accepting user", zap.Error(err))
-			return
+// decideMembership accepts or rejects the user whose poll is the message. decidedBy is nil
+// when the decision is made automatically by the poll result. It returns the answer for
+// the admin who pressed the button.
+func (b *botManager) decideMembership(chatID int64, messageID int, user *model.User, decision string, decidedBy *int64) callbackAnswer {
+	poll, ok, err := b.closeMembershipPoll(chatID, messageID, user, decision, decidedBy)
+	if err != nil {
+		return b.callbackFailed()
+	}
+	if !ok {
+		// Another admin was first or the update is processed again
+		return callbackAnswer{text: b.templator.MembershipAlreadyDecided(poll), alert: true}
+	}
+	// The buttons are in a staff chat if the poll couldn't be sent, then the group knows nothing to reply to
+	inGroup := chatID == b.groupID
+	// An escalated poll is already stopped
+	stopPoll := inGroup && (poll == nil || poll.Status != model.MembershipPollStatusEscalated)
+
+	switch decision {
+	case model.PollDecisionAccepted:
+		b.logger.Named("decideMembership").Debug("User accepted", zap.Int64("userTelegramID", user.TelegramID))
+		b.sendAcceptedInvite(user)
+		if stopPoll {
+			b.send(tgbotapi.NewStopPoll(chatID, messageID))
 		}
-		acceptMsg := tgbotapi.NewMessage(user.TelegramID, b.templator.AcceptUserReply(b.inviteLink))
-		acceptMsg.ParseMode = tgbotapi.ModeHTML
-		b.send(acceptMsg)
-		stopPoll := tgbotapi.NewStopPoll(chatID, messageID)
-		b.send(stopPoll)
-		acceptGroupMsg := tgbotapi.NewMessage(b.groupID, b.templator.AcceptUserGroupReply())
-		acceptGroupMsg.ReplyToMessageID = messageID
-		b.send(acceptGroupMsg)
-
-	case "reject":
-		b.logger.Named("processUserCallbackQuery").Debug("Rejecting user", zap.Int64("userTelegramID", user.TelegramID))
-		_, err := b.db.RejectUser(b.ctx, user.ID)
-		if err != nil {
-			b.logger.Named("processUserCallbackQuery").Error("Error while rejecting user", zap.Error(err))
-			return
+		if inGroup {
+			acceptGroupMsg := tgbotapi.NewMessage(b.groupID, b.templator.AcceptUserGroupReply())
+			acceptGroupMsg.ReplyToMessageID = messageID
+			b.send(acceptGroupMsg)
 		}
+
+	case model.PollDecisionRejected:
+		b.logger.Named("decideMembership").Debug("User rejected", zap.Int64("userTelegramID", user.TelegramID))
+		b.revokeUserInviteLinks(user.TelegramID)
 		rejectMsg := tgbotapi.NewMessage(user.TelegramID, b.templator.RejectUserReply())
 		b.send(rejectMsg)
-		stopPoll := tgbotapi.NewStopPoll(chatID, messageID)
-		b.send(stopPoll)
-		rejectGroupMsg := tgbotapi.NewMessage(b.groupID, b.templator.RejectUserGroupReply())
-		rejectGroupMsg.ReplyToMessageID = messageID
-		b.send(rejectGroupMsg)
+		if stopPoll {
+			b.send(tgbotapi.NewStopPoll(chatID, messageID))
+		}
+		if inGroup {
+			rejectGroupMsg := tgbotapi.NewMessage(b.groupID, b.templator.RejectUserGroupReply())
+			rejectGroupMsg.ReplyToMessageID = messageID
+			b.send(rejectGroupMsg)
+		}
+	}
+	if !inGroup {
+		b.clearKeyboard(chatID, messageID)
 	}
+	return b.callbackDone()
 }
 
 func (b *botManager) processChatJoinRequest(request *tgbotapi.ChatJoinRequest) {
 	b.logger.Named("processChatJoinRequest").Debug("Processing chat join request")
 	user, err := b.db.GetUserByTelegramID(b.ctx, request.From.ID)
 	if err != nil {
-		if errors.As(err, &noRecordError) {
+		if errors.Is(err, noRecordError) {
 			b.logger.Named("processChatJoinRequest").Info("User is not in database")
 			rejectRequest := tgbotapi.DeclineChatJoinRequest{
 				ChatConfig: tgbotapi.ChatConfig{
@@ -549,7 +635,25 @@ func (b *botManager) processChatJoinRequest(request *tgbotapi.ChatJoinRequest) {
 	}
 	switch user.Status {
 	case model.UserStatusActive, model.UserStatusAccepted, model.UserStatusNotActive:
+		link, reason := b.checkJoinRequestLink(request)
+		if link == nil {
+			b.logger.Named("processChatJoinRequest").Info("Join request without the user's own link", zap.Int64("telegram_id", request.From.ID), zap.String("reason", reason))
+			b.send(tgbotapi.DeclineChatJoinRequest{
+				ChatConfig: tgbotapi.ChatConfig{
+					ChatID: request.Chat.ID,
+				},
+				UserID: request.From.ID,
+			})
+			b.send(tgbotapi.NewMessage(request.From.ID, b.templator.JoinRequestWrongLink()))
+			b.notifyStaff(model.PermissionDecideMembership, func(chatID int64) tgbotapi.Chattable {
+				msg := tgbotapi.NewMessage(chatID, b.templator.JoinRequestDeclined(user, reason))
+				msg.ParseMode = tgbotapi.ModeHTML
+				return msg
+			})
+			return
+		}
 		b.logger.Named("processChatJoinRequest").Debug("User accepted")
+		b.useInviteLink(link)
 		acceptRequest := tgbotapi.ApproveChatJoinRequestConfig{
 			ChatConfig: tgbotapi.ChatConfig{
 				ChatID: request.Chat.ID,
@@ -557,8 +661,9 @@ func (b *botManager) processChatJoinRequest(request *tgbotapi.ChatJoinRequest) {
 			UserID: request.From.ID,
 		}
 		b.send(acceptRequest)
-		adminMsg := tgbotapi.NewMessage(b.adminID, fmt.Sprintf("Принял пользователя %v", request.From))
-		b.send(adminMsg)
+		b.notifyStaff(model.PermissionDecideMembership, func(chatID int64) tgbotapi.Chattable {
+			return tgbotapi.NewMessage(chatID, fmt.Sprintf("Принял пользователя %v", request.From))
+		})
 	default:
 		b.logger.Named("processChatJoinRequest").Debug("User rejected")
 		rejectRequest := tgbotapi.DeclineChatJoinRequest{
@@ -568,23 +673,16 @@ func (b *botManager) processChatJoinRequest(request *tgbotapi.ChatJoinRequest) {
 			UserID: request.From.ID,
 		}
 		b.send(rejectRequest)
-		adminMsg := tgbotapi.NewMessage(b.adminID, fmt.Sprintf("Отклонил пользователя %v", request.From))
-		b.send(adminMsg)
+		b.notifyStaff(model.PermissionDecideMembership, func(chatID int64) tgbotapi.Chattable {
+			return tgbotapi.NewMessage(chatID, fmt.Sprintf("Отклонил пользователя %v", request.From))
+		})
 	}
 }
 
+// processNewChatMembers only greets the member. The status is kept by processChatMember,
+// the service message is keyed by the group and may be processed out of order with it.
 func (b *botManager) processNewChatMembers(message *tgbotapi.Message) {
 	b.logger.Named("processNewChatMembers").Debug("Processing new chat members")
-	for _, user := range message.NewChatMembers {
-		_, err := b.db.UpdateOrCreateUser(b.ctx, &model.User{
-			TelegramID: user.ID,
-			Status:     model.UserStatusActive,
-		})
-		if err != nil {
-			b.logger.Named("processNewChatMembers").Error("Error while updating user", zap.Error(err))
-			return
-		}
-	}
 	if len(message.NewChatMembers) > 1 {
 		b.logger.Named("processNewChatMembers").Debug("More than one user joined")
 		return
@@ -593,15 +691,3 @@ func (b *botManager) processNewChatMembers(message *tgbotapi.Message) {
 	msg.ReplyToMessageID = message.MessageID
 	b.send(msg)
 }
-
-func (b *botManager) processLeftChatMember(message *tgbotapi.Message) {
-	b.logger.Named("processLeftChatMember").Debug("Processing left chat member")
-	_, err := b.db.UpdateOrCreateUser(b.ctx, &model.User{
-		TelegramID: message.LeftChatMember.ID,
-		Status:     model.UserStatusNotActive,
-	})
-	if err != nil {
-		b.logger.Named("processLeftChatMember").Error("Error while updating user", zap.Error(err))
-		return
-	}
-}
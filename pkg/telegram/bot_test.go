@@ -0,0 +1,37 @@
+package telegram
+
+import (
+	mock_database "beneburg/pkg/database/mocks"
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+// newTestBot returns a bot on a mocked database. Direct sends go to messagesChan, which
+// holds one message, the outbox is written through the mock. Tests change the rules directly.
+func newTestBot(t *testing.T) (*botManager, *mock_database.MockDatabase) {
+	controller := gomock.NewController(t)
+	dbMock := mock_database.NewMockDatabase(controller)
+	return &botManager{
+		db:           dbMock,
+		ctx:          context.Background(),
+		logger:       zap.NewNop(),
+		templator:    NewTemplator("https://example.com"),
+		callbacks:    NewCallbackCodec([]byte("secret")),
+		reminders:    DefaultReminderRules,
+		messagesChan: make(chan outgoingMessage, 1),
+		outboxWakeup: make(chan struct{}, 1),
+	}, dbMock
+}
+
+// fakeBotAPI answers Send with send, the other methods of the API are not implemented.
+type fakeBotAPI struct {
+	TgBotAPI
+	send func(c tgbotapi.Chattable) (tgbotapi.Message, error)
+}
+
+func (f fakeBotAPI) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return f.send(c)
+}
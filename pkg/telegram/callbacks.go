@@ -0,0 +1,206 @@
+package telegram
+
+import (
+	"beneburg/pkg/callback"
+	"beneburg/pkg/database/model"
+	"crypto/rand"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"time"
+)
+
+// signedCallbacksSinceKey keeps the time the bot first sent signed buttons. It's saved once,
+// so a restart doesn't make newer messages accept unsigned admin buttons.
+const signedCallbacksSinceKey = "signed_callbacks_since"
+
+// Callback actions of inline buttons. Names are stored in sent buttons, so they must not change.
+var (
+	actionFormAccept = &callback.Action{Name: "form.accept", Args: []callback.Kind{callback.Uint}, Admin: true}
+	actionFormReject = &callback.Action{Name: "form.reject", Args: []callback.Kind{callback.Uint}, Admin: true}
+	// actionFormReason takes the form ID and the index in rejectionReasonPresets.
+	actionFormReason = &callback.Action{Name: "form.reason", Args: []callback.Kind{callback.Uint, callback.Int}, Admin: true}
+	actionFormCancel = &callback.Action{Name: "form.cancel", Args: []callback.Kind{callback.Uint}, Admin: true}
+	actionFormResend = &callback.Action{Name: "form.resend", Args: []callback.Kind{callback.Uint}, Admin: true}
+
+	actionUserAccept = &callback.Action{Name: "user.accept", Args: []callback.Kind{callback.Int64}, Admin: true}
+	actionUserReject = &callback.Action{Name: "user.reject", Args: []callback.Kind{callback.Int64}, Admin: true}
+
+	actionWhois      = &callback.Action{Name: "whois", Args: []callback.Kind{callback.Int64}, Admin: true}
+	actionStatusMenu = &callback.Action{Name: "status.menu", Args: []callback.Kind{callback.Int64}, Admin: true}
+	actionStatusBack = &callback.Action{Name: "status.back", Args: []callback.Kind{callback.Int64}, Admin: true}
+	actionStatusSet  = &callback.Action{Name: "status.set", Args: []callback.Kind{callback.Int64, callback.String}, Admin: true}
+
+	// Apply actions take the name of the step the button belongs to.
+	actionApplyChoice = &callback.Action{Name: "apply.choice", Args: []callback.Kind{callback.String, callback.String}}
+	actionApplySkip   = &callback.Action{Name: "apply.skip", Args: []callback.Kind{callback.String}}
+	actionApplyBack   = &callback.Action{Name: "apply.back", Args: []callback.Kind{callback.String}}
+	actionApplyCancel = &callback.Action{Name: "apply.cancel", Args: []callback.Kind{callback.String}}
+	actionApplySubmit = &callback.Action{Name: "apply.submit", Args: []callback.Kind{callback.String}}
+)
+
+// NewCallbackCodec returns the codec of the bot's buttons. The secret signs admin buttons,
+// so it must be the same for every instance and stay the same across restarts.
+func NewCallbackCodec(secret []byte) *callback.Codec {
+	codec := callback.NewCodec(secret)
+	codec.Register(
+		actionFormAccept, actionFormReject, actionFormReason, actionFormCancel, actionFormResend,
+		actionUserAccept, actionUserReject,
+		actionWhois, actionStatusMenu, actionStatusBack, actionStatusSet,
+		actionApplyChoice, actionApplySkip, actionApplyBack, actionApplyCancel, actionApplySubmit,
+	)
+	// Buttons sent before the versioned format
+	codec.RegisterLegacy("admin:form:accept:", actionFormAccept)
+	codec.RegisterLegacy("admin:form:reject:", actionFormReject)
+	codec.RegisterLegacy("admin:form:reason:", actionFormReason)
+	codec.RegisterLegacy("admin:form:cancel:", actionFormCancel)
+	codec.RegisterLegacy("admin:form:resend:", actionFormResend)
+	codec.RegisterLegacy("admin:user:accept:", actionUserAccept)
+	codec.RegisterLegacy("admin:user:reject:", actionUserReject)
+	codec.RegisterLegacy("admin:whois:", actionWhois)
+	codec.RegisterLegacy("admin:status:menu:", actionStatusMenu)
+	codec.RegisterLegacy("admin:status:back:", actionStatusBack)
+	codec.RegisterLegacy("admin:status:set:", actionStatusSet)
+	codec.RegisterLegacy("apply:choice:", actionApplyChoice)
+	codec.RegisterLegacy("apply:skip:", actionApplySkip)
+	codec.RegisterLegacy("apply:back:", actionApplyBack)
+	codec.RegisterLegacy("apply:cancel:", actionApplyCancel)
+	codec.RegisterLegacy("apply:submit:", actionApplySubmit)
+	return codec
+}
+
+// randomCallbackSecret is used until SetCallbackCodec is called, buttons signed with it
+// stop working after a restart.
+func randomCallbackSecret() []byte {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		panic(err)
+	}
+	return secret
+}
+
+func (b *botManager) SetCallbackCodec(codec *callback.Codec) {
+	b.callbacks = codec
+}
+
+// callbackAnswer is shown to the user who pressed the button: a toast, or an alert that
+// has to be closed when the user must notice it. An empty answer just stops the spinner.
+type callbackAnswer struct {
+	text  string
+	alert bool
+}
+
+func (b *botManager) callbackDone() callbackAnswer {
+	return callbackAnswer{text: b.templator.CallbackDone()}
+}
+
+// callbackFailed is the answer when the handler failed, the error itself goes to the log.
+func (b *botManager) callbackFailed() callbackAnswer {
+	return callbackAnswer{text: b.templator.CallbackFailed(), alert: true}
+}
+
+// callbackHandler handles a decoded button press and returns the answer for the user.
+type callbackHandler struct {
+	// permission required to press the button, empty if anyone can press it
+	permission string
+	handle     func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer
+}
+
+var callbackHandlers = map[*callback.Action]callbackHandler{
+	actionFormAccept: {permission: model.PermissionReviewForms, handle: func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer {
+		return b.acceptForm(query.Message.Chat.ID, query.Message.MessageID, payload.Uint(0), query.From.ID)
+	}},
+	actionFormReject: {permission: model.PermissionReviewForms, handle: func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer {
+		return b.promptRejectionReason(query.Message.Chat.ID, query.Message.MessageID, payload.Uint(0))
+	}},
+	actionFormReason: {permission: model.PermissionReviewForms, handle: func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer {
+		preset := payload.Int(1)
+		if preset < 0 || preset >= len(rejectionReasonPresets) {
+			b.logger.Named("callbackHandlers").Error("Unknown rejection reason preset", zap.Int("preset", preset))
+			return b.callbackFailed()
+		}
+		return b.rejectForm(query.Message.Chat.ID, query.Message.MessageID, payload.Uint(0), rejectionReasonPresets[preset], query.From.ID)
+	}},
+	actionFormCancel: {permission: model.PermissionReviewForms, handle: func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer {
+		return b.cancelRejection(query.Message.Chat.ID, query.Message.MessageID, payload.Uint(0))
+	}},
+	actionFormResend: {permission: model.PermissionReviewForms, handle: func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer {
+		return b.resendFormReview(query.Message.Chat.ID, payload.Uint(0))
+	}},
+	actionUserAccept: {permission: model.PermissionDecideMembership, handle: func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer {
+		return b.decideMembershipByButton(query.Message.Chat.ID, query.Message.MessageID, payload.Int64(0), model.PollDecisionAccepted, query.From.ID)
+	}},
+	actionUserReject: {permission: model.PermissionDecideMembership, handle: func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer {
+		return b.decideMembershipByButton(query.Message.Chat.ID, query.Message.MessageID, payload.Int64(0), model.PollDecisionRejected, query.From.ID)
+	}},
+	actionWhois: {permission: model.PermissionReviewForms, handle: func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer {
+		return b.processWhoisCallbackQuery(query.Message.Chat.ID, payload.Int64(0))
+	}},
+	actionStatusMenu: {permission: model.PermissionDecideMembership, handle: func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer {
+		return b.processStatusCallbackQuery(query.Message.Chat.ID, query.Message.MessageID, payload.Int64(0), "menu", "")
+	}},
+	actionStatusBack: {permission: model.PermissionDecideMembership, handle: func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer {
+		return b.processStatusCallbackQuery(query.Message.Chat.ID, query.Message.MessageID, payload.Int64(0), "back", "")
+	}},
+	actionStatusSet: {permission: model.PermissionDecideMembership, handle: func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer {
+		return b.processStatusCallbackQuery(query.Message.Chat.ID, query.Message.MessageID, payload.Int64(0), "set", payload.String(1))
+	}},
+	actionApplyChoice: {handle: func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer {
+		return b.processApplyCallbackQuery(query, "choice", payload.String(0), payload.String(1))
+	}},
+	actionApplySkip: {handle: func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer {
+		return b.processApplyCallbackQuery(query, "skip", payload.String(0), "")
+	}},
+	actionApplyBack: {handle: func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer {
+		return b.processApplyCallbackQuery(query, "back", payload.String(0), "")
+	}},
+	actionApplyCancel: {handle: func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer {
+		return b.processApplyCallbackQuery(query, "cancel", payload.String(0), "")
+	}},
+	actionApplySubmit: {handle: func(b *botManager, query *tgbotapi.CallbackQuery, payload *callback.Payload) callbackAnswer {
+		return b.processApplyCallbackQuery(query, "submit", payload.String(0), "")
+	}},
+}
+
+// loadSignedCallbacksSince reads the time signed buttons were first sent, saving now on the
+// first start. If it can't be read, unsigned admin buttons are refused on every message.
+func (b *botManager) loadSignedCallbacksSince() {
+	now := time.Now()
+	state, err := b.db.GetBotState(b.ctx, signedCallbacksSinceKey)
+	if err != nil && !errors.Is(err, noRecordError) {
+		b.logger.Named("loadSignedCallbacksSince").Error("Error while getting signed buttons start", zap.Error(err))
+		return
+	}
+	if state != nil {
+		since, err := time.Parse(time.RFC3339, state.Value)
+		if err != nil {
+			b.logger.Named("loadSignedCallbacksSince").Error("Invalid signed buttons start", zap.String("value", state.Value), zap.Error(err))
+			return
+		}
+		b.signedCallbacksSince = since
+		return
+	}
+	err = b.db.SetBotState(b.ctx, signedCallbacksSinceKey, now.Format(time.RFC3339))
+	if err != nil {
+		b.logger.Named("loadSignedCallbacksSince").Error("Error while saving signed buttons start", zap.Error(err))
+		return
+	}
+	b.signedCallbacksSince = now
+}
+
+// decodeCallbackQuery returns nil if the data is invalid or forged. Unsigned legacy admin
+// buttons are accepted only on messages sent before signedCallbacksSince, newer ones are always signed.
+func (b *botManager) decodeCallbackQuery(query *tgbotapi.CallbackQuery) *callback.Payload {
+	// Anyone can send forged data, so it's logged at Info: Warn and above are forwarded to the admin
+	payload, err := b.callbacks.Decode(query.Data)
+	if err != nil {
+		b.logger.Named("decodeCallbackQuery").Info("Invalid callback data", zap.Error(err), zap.String("data", query.Data), zap.Int64("telegram_id", query.From.ID))
+		return nil
+	}
+	if payload.Legacy && payload.Action.Admin && !query.Message.Time().Before(b.signedCallbacksSince) {
+		b.logger.Named("decodeCallbackQuery").Info("Unsigned admin callback data on a new message", zap.String("data", query.Data), zap.Int64("telegram_id", query.From.ID))
+		return nil
+	}
+	return payload
+}
@@ -0,0 +1,147 @@
+package telegram
+
+import (
+	"beneburg/pkg/callback"
+	"beneburg/pkg/database/model"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewCallbackCodec(t *testing.T) {
+	codec := NewCallbackCodec([]byte("secret"))
+	b := &botManager{callbacks: codec}
+
+	t.Run("Buttons fit the limit", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			for i := 0; i <= len(applySteps); i++ {
+				b.applyStepKeyboard(i)
+			}
+			b.rejectionReasonsKeyboard(math.MaxUint32)
+			b.statusKeyboard(math.MinInt64)
+			b.membershipKeyboard(math.MinInt64)
+		})
+	})
+
+	t.Run("Every action has a handler", func(t *testing.T) {
+		for _, data := range []string{
+			codec.MustEncode(actionFormAccept, uint(1)),
+			codec.MustEncode(actionFormReason, uint(1), 2),
+			codec.MustEncode(actionStatusSet, int64(1), "active"),
+			codec.MustEncode(actionApplyChoice, "gender", "f"),
+		} {
+			payload, err := codec.Decode(data)
+			assert.NoError(t, err)
+			assert.Contains(t, callbackHandlers, payload.Action)
+		}
+	})
+
+	t.Run("Buttons sent before the versioned format are decoded", func(t *testing.T) {
+		payload, err := codec.Decode("admin:status:set:42:active")
+		assert.NoError(t, err)
+		assert.Equal(t, actionStatusSet, payload.Action)
+		assert.True(t, payload.Legacy)
+		assert.Equal(t, int64(42), payload.Int64(0))
+		assert.Equal(t, "active", payload.String(1))
+
+		payload, err = codec.Decode("admin:form:reason:7:1")
+		assert.NoError(t, err)
+		assert.Equal(t, actionFormReason, payload.Action)
+		assert.Equal(t, 1, payload.Int(1))
+	})
+
+	t.Run("Admin buttons of another secret are rejected", func(t *testing.T) {
+		_, err := NewCallbackCodec([]byte("another")).Decode(codec.MustEncode(actionWhois, int64(42)))
+		assert.ErrorIs(t, err, callback.ErrBadSignature)
+	})
+}
+
+func Test_processCallbackQuery(t *testing.T) {
+	query := func(data string) *tgbotapi.CallbackQuery {
+		return &tgbotapi.CallbackQuery{
+			ID:      "query",
+			From:    &tgbotapi.User{ID: 10},
+			Message: &tgbotapi.Message{MessageID: 1, Chat: &tgbotapi.Chat{ID: 10}},
+			Data:    data,
+		}
+	}
+	answer := func(t *testing.T, b *botManager) tgbotapi.CallbackConfig {
+		select {
+		case message := <-b.messagesChan:
+			return message.message.(tgbotapi.CallbackConfig)
+		default:
+			t.Fatal("Callback query is not answered")
+			return tgbotapi.CallbackConfig{}
+		}
+	}
+
+	t.Run("Invalid data is answered", func(t *testing.T) {
+		b, _ := newTestBot(t)
+		b.processCallbackQuery(query("1:form.accept:1:forged"))
+		got := answer(t, b)
+		assert.Equal(t, "query", got.CallbackQueryID)
+		assert.Equal(t, b.templator.CallbackInvalid(), got.Text)
+		assert.True(t, got.ShowAlert)
+	})
+	t.Run("Not allowed tap is answered", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetUserRole(gomock.Any(), int64(10)).Return(model.RoleMember, nil)
+		b.processCallbackQuery(query(b.callbacks.MustEncode(actionFormAccept, uint(1))))
+		got := answer(t, b)
+		assert.Equal(t, b.templator.CallbackNotAllowed(), got.Text)
+		assert.True(t, got.ShowAlert)
+	})
+	t.Run("Failed handler is answered", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetUserRole(gomock.Any(), int64(10)).Return(model.RoleAdmin, nil)
+		dbMock.EXPECT().GetUserByTelegramID(gomock.Any(), int64(42)).Return(nil, errors.New("connection refused"))
+		b.processCallbackQuery(query(b.callbacks.MustEncode(actionWhois, int64(42))))
+		got := answer(t, b)
+		assert.Equal(t, b.templator.CallbackFailed(), got.Text)
+		assert.True(t, got.ShowAlert)
+	})
+}
+
+func Test_decodeCallbackQuery(t *testing.T) {
+	cutoff := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	query := func(data string, sentAt time.Time) *tgbotapi.CallbackQuery {
+		return &tgbotapi.CallbackQuery{
+			From:    &tgbotapi.User{ID: 10},
+			Message: &tgbotapi.Message{Date: int(sentAt.Unix()), Chat: &tgbotapi.Chat{ID: 10}},
+			Data:    data,
+		}
+	}
+
+	t.Run("Legacy admin data is accepted only before the cutoff", func(t *testing.T) {
+		b, _ := newTestBot(t)
+		b.signedCallbacksSince = cutoff
+		payload := b.decodeCallbackQuery(query("admin:whois:42", cutoff.Add(-time.Hour)))
+		if assert.NotNil(t, payload) {
+			assert.True(t, payload.Legacy)
+		}
+		assert.Nil(t, b.decodeCallbackQuery(query("admin:whois:42", cutoff.Add(time.Hour))))
+	})
+	t.Run("Cutoff is kept across restarts", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetBotState(gomock.Any(), signedCallbacksSinceKey).Return(&model.BotState{Key: signedCallbacksSinceKey, Value: cutoff.Format(time.RFC3339)}, nil)
+		b.loadSignedCallbacksSince()
+		assert.True(t, cutoff.Equal(b.signedCallbacksSince))
+	})
+	t.Run("Cutoff is saved on the first start", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetBotState(gomock.Any(), signedCallbacksSinceKey).Return(nil, noRecordError)
+		dbMock.EXPECT().SetBotState(gomock.Any(), signedCallbacksSinceKey, gomock.Any()).Return(nil)
+		b.loadSignedCallbacksSince()
+		assert.WithinDuration(t, time.Now(), b.signedCallbacksSince, time.Minute)
+	})
+	t.Run("Unreadable cutoff refuses legacy admin data", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetBotState(gomock.Any(), signedCallbacksSinceKey).Return(nil, errors.New("connection refused"))
+		b.loadSignedCallbacksSince()
+		assert.Nil(t, b.decodeCallbackQuery(query("admin:whois:42", cutoff)))
+	})
+}
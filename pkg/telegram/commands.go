@@ -0,0 +1,288 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"strings"
+)
+
+// chatScope is a set of chat types where a command is available.
+type chatScope int
+
+const (
+	scopePrivate chatScope = 1 << iota
+	scopeGroup
+)
+
+type commandArg struct {
+	name     string
+	optional bool
+	// rest makes the argument consume everything left in the message, so it must be the last one.
+	rest bool
+}
+
+// commandArgs holds parsed command arguments by their names.
+type commandArgs map[string]string
+
+// commandHandler is a botManager method expression, so it runs on the bot bound to the update's context.
+type commandHandler func(b *botManager, message *tgbotapi.Message, args commandArgs)
+
+type command struct {
+	name        string
+	description string
+	scopes      chatScope
+	// permission required to run the command, empty if anyone can run it
+	permission string
+	args       []commandArg
+	handler    commandHandler
+}
+
+func (c *command) usage() string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString("/")
+	stringBuilder.WriteString(c.name)
+	for _, arg := range c.args {
+		if arg.optional {
+			stringBuilder.WriteString(fmt.Sprintf(" [%s]", arg.name))
+		} else {
+			stringBuilder.WriteString(fmt.Sprintf(" <%s>", arg.name))
+		}
+	}
+	return stringBuilder.String()
+}
+
+func (c *command) parseArgs(arguments string) (commandArgs, error) {
+	args := commandArgs{}
+	fields := strings.Fields(arguments)
+	for i, arg := range c.args {
+		if i >= len(fields) {
+			if !arg.optional {
+				return nil, fmt.Errorf("missing argument %q", arg.name)
+			}
+			continue
+		}
+		if arg.rest {
+			args[arg.name] = strings.Join(fields[i:], " ")
+			return args, nil
+		}
+		args[arg.name] = fields[i]
+	}
+	if len(fields) > len(c.args) {
+		return nil, fmt.Errorf("too many arguments")
+	}
+	return args, nil
+}
+
+type commandRegistry struct {
+	commands []*command
+	byName   map[string]*command
+}
+
+func newCommandRegistry() *commandRegistry {
+	return &commandRegistry{
+		byName: map[string]*command{},
+	}
+}
+
+func (r *commandRegistry) register(c *command) {
+	if _, ok := r.byName[c.name]; ok {
+		panic(fmt.Sprintf("command %q is already registered", c.name))
+	}
+	r.commands = append(r.commands, c)
+	r.byName[c.name] = c
+}
+
+func (r *commandRegistry) lookup(name string) (*command, bool) {
+	c, ok := r.byName[name]
+	return c, ok
+}
+
+func (c *command) allowedFor(role string) bool {
+	return c.permission == "" || model.HasPermission(role, c.permission)
+}
+
+// botCommands returns the menu entries of commands available in the scope for the role.
+func (r *commandRegistry) botCommands(scope chatScope, role string) []tgbotapi.BotCommand {
+	var botCommands []tgbotapi.BotCommand
+	for _, c := range r.commands {
+		if c.scopes&scope == 0 || !c.allowedFor(role) {
+			continue
+		}
+		botCommands = append(botCommands, tgbotapi.BotCommand{
+			Command:     c.name,
+			Description: c.description,
+		})
+	}
+	return botCommands
+}
+
+func (b *botManager) registerCommands() {
+	b.commands = newCommandRegistry()
+	b.commands.register(&command{
+		name:        "start",
+		description: "Начать",
+		scopes:      scopePrivate,
+		handler:     (*botManager).processStartCommand,
+	})
+	b.commands.register(&command{
+		name:        "login",
+		description: "Получить ссылку для входа на сайт",
+		scopes:      scopePrivate,
+		handler:     (*botManager).processLoginCommand,
+	})
+	b.commands.register(&command{
+		name:        "apply",
+		description: "Заполнить анкету прямо в боте",
+		scopes:      scopePrivate,
+		handler:     (*botManager).processApplyCommand,
+	})
+	b.commands.register(&command{
+		name:        "status",
+		description: "Где сейчас твоя анкета",
+		scopes:      scopePrivate,
+		handler:     (*botManager).processStatusCommand,
+	})
+	b.commands.register(&command{
+		name:        "invite",
+		description: "Личная ссылка для вступления в чат",
+		scopes:      scopePrivate,
+		handler:     (*botManager).processInviteCommand,
+	})
+	b.commands.register(&command{
+		name:        "birthday",
+		description: "Включить или выключить поздравление с днём рождения",
+		scopes:      scopePrivate,
+		args:        []commandArg{{name: "on|off", optional: true}},
+		handler:     (*botManager).processBirthdayCommand,
+	})
+	b.commands.register(&command{
+		name:        "info",
+		description: "Профиль участника (ответом на его сообщение)",
+		scopes:      scopeGroup,
+		handler:     (*botManager).processInfoCommand,
+	})
+	b.commands.register(&command{
+		name:        "grant",
+		description: "Выдать роль",
+		scopes:      scopePrivate,
+		permission:  model.PermissionManageRoles,
+		args:        []commandArg{{name: "@username|id"}, {name: "role"}},
+		handler:     (*botManager).processGrantCommand,
+	})
+	b.commands.register(&command{
+		name:        "revoke",
+		description: "Забрать роль",
+		scopes:      scopePrivate,
+		permission:  model.PermissionManageRoles,
+		args:        []commandArg{{name: "@username|id"}},
+		handler:     (*botManager).processRevokeCommand,
+	})
+	b.commands.register(&command{
+		name:        "roles",
+		description: "Список ролей",
+		scopes:      scopePrivate,
+		permission:  model.PermissionManageRoles,
+		handler:     (*botManager).processRolesCommand,
+	})
+	b.commands.register(&command{
+		name:        "polls",
+		description: "Открытые голосования за новых участников",
+		scopes:      scopePrivate,
+		permission:  model.PermissionDecideMembership,
+		handler:     (*botManager).processPollsCommand,
+	})
+	b.commands.register(&command{
+		name:        "reconcile",
+		description: "Сверить участников чата с базой",
+		scopes:      scopePrivate,
+		permission:  model.PermissionDecideMembership,
+		handler:     (*botManager).processReconcileCommand,
+	})
+	b.commands.register(&command{
+		name:        "digest",
+		description: "Предпросмотр дайджеста сообщества",
+		scopes:      scopePrivate,
+		permission:  model.PermissionDecideMembership,
+		handler:     (*botManager).processDigestCommand,
+	})
+	b.commands.register(&command{
+		name:        "whois",
+		description: "Найти пользователя и его анкету",
+		scopes:      scopePrivate,
+		permission:  model.PermissionReviewForms,
+		args:        []commandArg{{name: "@username|id|имя", rest: true}},
+		handler:     (*botManager).processWhoisCommand,
+	})
+	b.commands.register(&command{
+		name:        "ban",
+		description: "Заблокировать пользователя",
+		scopes:      scopePrivate | scopeGroup,
+		permission:  model.PermissionBan,
+		args:        []commandArg{{name: "@username|id", optional: true}, {name: "причина", optional: true, rest: true}},
+		handler:     (*botManager).processBanCommand,
+	})
+	b.commands.register(&command{
+		name:        "unban",
+		description: "Снять блокировку",
+		scopes:      scopePrivate | scopeGroup,
+		permission:  model.PermissionBan,
+		args:        []commandArg{{name: "@username|id", optional: true}},
+		handler:     (*botManager).processUnbanCommand,
+	})
+}
+
+func (b *botManager) processCommand(message *tgbotapi.Message, scope chatScope) {
+	b.logger.Named("processCommand").Debug("Processing command", zap.String("command", message.Command()))
+	c, ok := b.commands.lookup(message.Command())
+	if !ok || c.scopes&scope == 0 {
+		b.logger.Named("processCommand").Debug("Unknown command")
+		return
+	}
+	if c.permission != "" && !b.hasPermission(message.From, c.permission) {
+		b.logger.Named("processCommand").Info("Command is not allowed", zap.String("command", c.name), zap.Int64("chat_id", message.Chat.ID))
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.CommandNotAllowed()))
+		return
+	}
+	args, err := c.parseArgs(message.CommandArguments())
+	if err != nil {
+		b.logger.Named("processCommand").Debug("Invalid command arguments", zap.Error(err))
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.CommandUsage(c.usage())))
+		return
+	}
+	c.handler(b, message, args)
+}
+
+// syncCommands pushes the registered commands to Telegram so the client menu matches them.
+func (b *botManager) syncCommands() {
+	b.logger.Named("syncCommands").Debug("Syncing commands")
+	b.setMyCommands(tgbotapi.NewBotCommandScopeAllPrivateChats(), b.commands.botCommands(scopePrivate, model.RoleMember))
+	b.setMyCommands(tgbotapi.NewBotCommandScopeAllGroupChats(), b.commands.botCommands(scopeGroup, model.RoleMember))
+	roles, err := b.db.GetRoles(b.ctx)
+	if err != nil {
+		b.logger.Named("syncCommands").Error("Error while getting roles", zap.Error(err))
+		return
+	}
+	for _, role := range roles {
+		b.syncUserCommands(role.UserTelegramId, role.Role)
+	}
+}
+
+// syncUserCommands sets the menu of the user's private chat with the bot according to the role.
+func (b *botManager) syncUserCommands(telegramID int64, role string) {
+	scope := tgbotapi.NewBotCommandScopeChat(telegramID)
+	if role == model.RoleMember {
+		b.send(tgbotapi.NewDeleteMyCommandsWithScope(scope))
+		return
+	}
+	b.setMyCommands(scope, b.commands.botCommands(scopePrivate, role))
+}
+
+func (b *botManager) setMyCommands(scope tgbotapi.BotCommandScope, botCommands []tgbotapi.BotCommand) {
+	if len(botCommands) == 0 {
+		b.send(tgbotapi.NewDeleteMyCommandsWithScope(scope))
+		return
+	}
+	b.send(tgbotapi.NewSetMyCommandsWithScope(scope, botCommands...))
+}
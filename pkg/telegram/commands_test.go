@@ -0,0 +1,48 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_command(t *testing.T) {
+	c := &command{
+		name: "test",
+		args: []commandArg{
+			{name: "id"},
+			{name: "reason", optional: true, rest: true},
+		},
+	}
+	t.Run("Usage", func(t *testing.T) {
+		assert.Equal(t, "/test <id> [reason]", c.usage())
+	})
+	t.Run("Parse all arguments", func(t *testing.T) {
+		args, err := c.parseArgs("10 too  many messages")
+		assert.NoError(t, err)
+		assert.Equal(t, commandArgs{"id": "10", "reason": "too many messages"}, args)
+	})
+	t.Run("Parse without optional argument", func(t *testing.T) {
+		args, err := c.parseArgs("10")
+		assert.NoError(t, err)
+		assert.Equal(t, commandArgs{"id": "10"}, args)
+	})
+	t.Run("Missing required argument", func(t *testing.T) {
+		_, err := c.parseArgs("")
+		assert.Error(t, err)
+	})
+	t.Run("Too many arguments", func(t *testing.T) {
+		_, err := (&command{name: "test"}).parseArgs("10")
+		assert.Error(t, err)
+	})
+	t.Run("Bot commands by scope and role", func(t *testing.T) {
+		r := newCommandRegistry()
+		r.register(&command{name: "private", scopes: scopePrivate})
+		r.register(&command{name: "group", scopes: scopeGroup})
+		r.register(&command{name: "admin", scopes: scopePrivate, permission: model.PermissionManageRoles})
+		assert.Len(t, r.botCommands(scopePrivate, model.RoleMember), 1)
+		assert.Len(t, r.botCommands(scopePrivate, model.RoleModerator), 1)
+		assert.Len(t, r.botCommands(scopePrivate, model.RoleAdmin), 2)
+		assert.Len(t, r.botCommands(scopeGroup, model.RoleOwner), 1)
+	})
+}
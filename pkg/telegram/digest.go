@@ -0,0 +1,173 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	digestCheckInterval = time.Hour
+	// digestPostedKey keeps the end of the period of the last posted digest.
+	digestPostedKey = "community_digest_posted_until"
+	// digestMaxMembers caps each list of the digest, so it fits into one message.
+	digestMaxMembers = 15
+)
+
+// DigestRules tell when the community digest is posted to the group.
+type DigestRules struct {
+	// Interval is the period covered by one digest, 0 turns the digest off.
+	Interval time.Duration
+	// Hour is the local hour the digest is posted at.
+	Hour int
+}
+
+var DefaultDigestRules = DigestRules{
+	Interval: 7 * 24 * time.Hour,
+	Hour:     18,
+}
+
+func (b *botManager) SetDigestRules(rules DigestRules) {
+	b.digestRules = rules
+}
+
+// CommunityDigest is what happened in the community in [From, To).
+type CommunityDigest struct {
+	From time.Time
+	To   time.Time
+	// Joined are the members who joined the group, Form is nil if they have no accepted form.
+	Joined []DigestMember
+	// Updated are the accepted forms of members who were in the group before.
+	Updated  []*model.Form
+	Received int
+	Accepted int
+	Rejected int
+}
+
+type DigestMember struct {
+	User *model.User
+	Form *model.Form
+}
+
+// digestPeriodEnd returns the end of the period of the digest posted today, the posting time.
+func (r DigestRules) digestPeriodEnd(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), r.Hour, 0, 0, 0, now.Location())
+}
+
+func (b *botManager) buildDigest(from time.Time, to time.Time) (*CommunityDigest, error) {
+	digest := &CommunityDigest{From: from, To: to}
+	users, err := b.db.GetUsersJoinedBetween(b.ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	joined := map[int64]bool{}
+	for _, user := range users {
+		joined[user.TelegramID] = true
+		form, err := b.db.GetActualForm(b.ctx, user.TelegramID)
+		if err != nil && !errors.Is(err, noRecordError) {
+			return nil, err
+		}
+		digest.Joined = append(digest.Joined, DigestMember{User: user, Form: form})
+	}
+	forms, err := b.db.GetFormsDecidedBetween(b.ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	// Only the last update of a member is shown, the forms are sorted by the decision time
+	updated := map[int64]int{}
+	for _, form := range forms {
+		switch form.Status {
+		case model.FormStatusAccepted:
+			digest.Accepted++
+		case model.FormStatusRejected:
+			digest.Rejected++
+			continue
+		default:
+			continue
+		}
+		if form.User.Status != model.UserStatusActive || joined[form.UserTelegramId] {
+			continue
+		}
+		if i, ok := updated[form.UserTelegramId]; ok {
+			digest.Updated[i] = form
+			continue
+		}
+		updated[form.UserTelegramId] = len(digest.Updated)
+		digest.Updated = append(digest.Updated, form)
+	}
+	received, err := b.db.CountFormsCreatedBetween(b.ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	digest.Received = int(received)
+	return digest, nil
+}
+
+// postDigest posts the digest for the period ending today at the digest hour, once per Interval.
+func (b *botManager) postDigest() {
+	if b.digestRules.Interval <= 0 {
+		return
+	}
+	now := time.Now()
+	to := b.digestRules.digestPeriodEnd(now)
+	if now.Before(to) {
+		return
+	}
+	if !b.claimPeriod(digestPostedKey, to, b.digestRules.Interval) {
+		return
+	}
+	from := to.Add(-b.digestRules.Interval)
+	digest, err := b.buildDigest(from, to)
+	if err != nil {
+		b.logger.Named("postDigest").Error("Error while building digest", zap.Error(err))
+		return
+	}
+	b.logger.Named("postDigest").Info("Posting digest", zap.Time("from", from), zap.Time("to", to), zap.Int("joined", len(digest.Joined)), zap.Int("updated", len(digest.Updated)))
+	msg := tgbotapi.NewMessage(b.groupID, b.templator.CommunityDigest(digest))
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.DisableWebPagePreview = true
+	b.send(msg)
+}
+
+// nextDigestPeriod returns the period of the next digest post: today's if it's not posted yet,
+// otherwise the first one ending at least Interval after the last posted period.
+func (b *botManager) nextDigestPeriod(now time.Time, interval time.Duration) (time.Time, time.Time) {
+	to := b.digestRules.digestPeriodEnd(now)
+	state, err := b.db.GetBotState(b.ctx, digestPostedKey)
+	if err != nil {
+		if !errors.Is(err, noRecordError) {
+			b.logger.Named("nextDigestPeriod").Error("Error while getting last digest period", zap.Error(err))
+		}
+		return to.Add(-interval), to
+	}
+	postedUntil, err := time.Parse(time.RFC3339, state.Value)
+	if err != nil {
+		b.logger.Named("nextDigestPeriod").Info("Invalid last digest period", zap.String("value", state.Value), zap.Error(err))
+		return to.Add(-interval), to
+	}
+	for to.Sub(postedUntil) < interval {
+		to = to.AddDate(0, 0, 1)
+	}
+	return to.Add(-interval), to
+}
+
+// processDigestCommand shows the next digest as it would be posted if nothing changed until then.
+func (b *botManager) processDigestCommand(message *tgbotapi.Message, _ commandArgs) {
+	b.logger.Named("processDigestCommand").Debug("Processing digest command")
+	interval := b.digestRules.Interval
+	if interval <= 0 {
+		interval = DefaultDigestRules.Interval
+	}
+	from, to := b.nextDigestPeriod(time.Now(), interval)
+	digest, err := b.buildDigest(from, to)
+	if err != nil {
+		b.logger.Named("processDigestCommand").Error("Error while building digest", zap.Error(err))
+		return
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.templator.DigestPreview(b.digestRules.Interval > 0)+"\n\n"+b.templator.CommunityDigest(digest))
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.DisableWebPagePreview = true
+	b.send(msg)
+}
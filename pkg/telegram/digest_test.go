@@ -0,0 +1,78 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"beneburg/pkg/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_buildDigest(t *testing.T) {
+	b, dbMock := newTestBot(t)
+	to := time.Date(2026, time.October, 18, 18, 0, 0, 0, time.UTC)
+	from := to.Add(-DefaultDigestRules.Interval)
+
+	newcomer := &model.User{TelegramID: 10, Status: model.UserStatusActive}
+	newcomerForm := &model.Form{UserTelegramId: 10, User: *newcomer, Status: model.FormStatusAccepted, Name: "Новый"}
+	member := model.User{TelegramID: 11, Status: model.UserStatusActive}
+	firstUpdate := &model.Form{UserTelegramId: 11, User: member, Status: model.FormStatusAccepted, Name: "Старое имя"}
+	lastUpdate := &model.Form{UserTelegramId: 11, User: member, Status: model.FormStatusAccepted, Name: "Новое имя"}
+	notJoined := &model.Form{UserTelegramId: 12, User: model.User{TelegramID: 12, Status: model.UserStatusAccepted}, Status: model.FormStatusAccepted}
+	rejected := &model.Form{UserTelegramId: 13, User: model.User{TelegramID: 13, Status: model.UserStatusNew}, Status: model.FormStatusRejected}
+
+	dbMock.EXPECT().GetUsersJoinedBetween(gomock.Any(), from, to).Return([]*model.User{newcomer}, nil)
+	dbMock.EXPECT().GetActualForm(gomock.Any(), int64(10)).Return(newcomerForm, nil)
+	dbMock.EXPECT().GetFormsDecidedBetween(gomock.Any(), from, to).Return([]*model.Form{newcomerForm, firstUpdate, notJoined, rejected, lastUpdate}, nil)
+	dbMock.EXPECT().CountFormsCreatedBetween(gomock.Any(), from, to).Return(int64(5), nil)
+
+	digest, err := b.buildDigest(from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []DigestMember{{User: newcomer, Form: newcomerForm}}, digest.Joined)
+	assert.Equal(t, []*model.Form{lastUpdate}, digest.Updated)
+	assert.Equal(t, 5, digest.Received)
+	assert.Equal(t, 4, digest.Accepted)
+	assert.Equal(t, 1, digest.Rejected)
+}
+
+func Test_postDigest(t *testing.T) {
+	t.Run("Posted period is not repeated", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		b.digestRules = DigestRules{Interval: 7 * 24 * time.Hour, Hour: 0}
+		to := b.digestRules.digestPeriodEnd(time.Now())
+		posted := &model.BotState{Value: to.Add(-24 * time.Hour).Format(time.RFC3339)}
+		dbMock.EXPECT().GetBotState(gomock.Any(), digestPostedKey).Return(posted, nil)
+		b.postDigest()
+	})
+}
+
+func Test_nextDigestPeriod(t *testing.T) {
+	rules := DigestRules{Interval: 7 * 24 * time.Hour, Hour: 18}
+	now := time.Date(2026, time.October, 18, 20, 0, 0, 0, time.UTC)
+	today := time.Date(2026, time.October, 18, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		posted *time.Time
+		wantTo time.Time
+	}{
+		{name: "Never posted", wantTo: today},
+		{name: "Today's is posted", posted: &today, wantTo: today.AddDate(0, 0, 7)},
+		{name: "Posted a few days ago", posted: utils.GetAddress(today.AddDate(0, 0, -5)), wantTo: today.AddDate(0, 0, 2)},
+		{name: "Posted long ago", posted: utils.GetAddress(today.AddDate(0, 0, -30)), wantTo: today},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, dbMock := newTestBot(t)
+			b.digestRules = rules
+			if tt.posted != nil {
+				dbMock.EXPECT().GetBotState(gomock.Any(), digestPostedKey).Return(&model.BotState{Value: tt.posted.Format(time.RFC3339)}, nil)
+			} else {
+				dbMock.EXPECT().GetBotState(gomock.Any(), digestPostedKey).Return(nil, noRecordError)
+			}
+			from, to := b.nextDigestPeriod(now, rules.Interval)
+			assert.Equal(t, tt.wantTo, to)
+			assert.Equal(t, tt.wantTo.Add(-rules.Interval), from)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package telegram
+
+import (
+	"beneburg/pkg/callback"
+	"beneburg/pkg/database"
+	"beneburg/pkg/database/model"
+	"context"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// SubmitForm saves the new form, tells the user it is received and sends it to everyone
+// who can review forms. It's shared by the website and the form filled out in the bot.
+func SubmitForm(ctx context.Context, db database.Database, send TelegramBotSendFunc, templator Templator, callbacks *callback.Codec, user *model.User, form *model.Form) error {
+	form.UserTelegramId = user.TelegramID
+	_, err := db.CreateForm(ctx, form)
+	if err != nil {
+		return err
+	}
+	send(tgbotapi.NewMessage(user.TelegramID, templator.FormReceived()))
+
+	reviewers, err := db.GetUserIDsWithPermission(ctx, model.PermissionReviewForms)
+	if err != nil {
+		return err
+	}
+	for _, reviewer := range reviewers {
+		reviewMessage := tgbotapi.NewMessage(reviewer, templator.NewFormReview(user, form))
+		reviewMessage.ParseMode = tgbotapi.ModeHTML
+		reviewMessage.ReplyMarkup = FormReviewKeyboard(callbacks, form.ID)
+		send(reviewMessage)
+	}
+	return nil
+}
+
+// stampFormReview replaces the buttons of the review message with the decision, so everyone
+// looking at the chat later can see who decided and when.
+func (b *botManager) stampFormReview(chatID int64, messageID int, user *model.User, form *model.Form) {
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, b.templator.NewFormReview(user, form)+b.templator.FormDecisionStamp(form, b.formDecider(form)))
+	edit.ParseMode = tgbotapi.ModeHTML
+	b.send(edit)
+}
+
+// refuseFormDecision stamps the review message with the decision made before and returns
+// the alert telling the reviewer about it.
+func (b *botManager) refuseFormDecision(chatID int64, messageID int, formID uint) callbackAnswer {
+	form, err := b.db.GetFormByID(b.ctx, formID)
+	if err != nil {
+		b.logger.Named("refuseFormDecision").Error("Error while getting form", zap.Error(err))
+		return callbackAnswer{text: b.templator.FormAlreadyReviewed(), alert: true}
+	}
+	if form.Status == model.FormStatusNew {
+		return callbackAnswer{text: b.templator.FormAlreadyReviewed(), alert: true}
+	}
+	user, err := b.db.GetUserByTelegramID(b.ctx, form.UserTelegramId)
+	if err != nil {
+		b.logger.Named("refuseFormDecision").Error("Error while getting user", zap.Error(err))
+		return callbackAnswer{text: b.templator.FormAlreadyReviewed(), alert: true}
+	}
+	decider := b.formDecider(form)
+	b.stampFormReview(chatID, messageID, user, form)
+	return callbackAnswer{text: b.templator.FormAlreadyDecided(form, decider), alert: true}
+}
+
+// formDecider returns nil if the reviewer is unknown, e.g. for forms decided before it was recorded.
+func (b *botManager) formDecider(form *model.Form) *model.User {
+	if form.DecidedBy == nil {
+		return nil
+	}
+	decider, err := b.db.GetUserByTelegramID(b.ctx, *form.DecidedBy)
+	if err != nil {
+		if !errors.Is(err, noRecordError) {
+			b.logger.Named("formDecider").Error("Error while getting reviewer", zap.Error(err))
+		}
+		return nil
+	}
+	return decider
+}
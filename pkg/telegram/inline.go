@@ -0,0 +1,84 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+)
+
+const (
+	// inlineResultsLimit is the page size, Telegram accepts at most 50 results.
+	inlineResultsLimit = 20
+	// inlineCacheTime is in seconds, results are personal, so the cache is per member.
+	inlineCacheTime = 60
+	// inlineMembersOnlyParameter is the /start parameter of the button shown to non-members.
+	inlineMembersOnlyParameter = "inline"
+)
+
+// processInlineQuery searches the forms of active members for "@bot <name or hobby>".
+// Other users get no results and a button to the bot.
+func (b *botManager) processInlineQuery(inlineQuery *tgbotapi.InlineQuery) {
+	b.logger.Named("processInlineQuery").Debug("Processing inline query", zap.String("query", inlineQuery.Query), zap.String("offset", inlineQuery.Offset))
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: inlineQuery.ID,
+		Results:       []interface{}{},
+		CacheTime:     inlineCacheTime,
+		IsPersonal:    true,
+	}
+	if inlineQuery.From == nil || !b.isActiveMember(inlineQuery.From.ID) {
+		answer.SwitchPMText = b.templator.InlineMembersOnly()
+		answer.SwitchPMParameter = inlineMembersOnlyParameter
+		b.send(answer)
+		return
+	}
+
+	offset, err := strconv.Atoi(inlineQuery.Offset)
+	if err != nil {
+		offset = 0
+	}
+	forms, err := b.db.SearchAcceptedForms(b.ctx, strings.TrimSpace(inlineQuery.Query), offset, inlineResultsLimit)
+	if err != nil {
+		b.logger.Named("processInlineQuery").Error("Error while searching forms", zap.Error(err))
+		return
+	}
+	for _, form := range forms {
+		answer.Results = append(answer.Results, b.inlineFormResult(form))
+	}
+	if len(forms) == inlineResultsLimit {
+		answer.NextOffset = strconv.Itoa(offset + inlineResultsLimit)
+	}
+	b.send(answer)
+}
+
+func (b *botManager) isActiveMember(telegramID int64) bool {
+	user, err := b.db.GetUserByTelegramID(b.ctx, telegramID)
+	if err != nil {
+		if !errors.Is(err, noRecordError) {
+			b.logger.Named("isActiveMember").Error("Error while getting user", zap.Error(err))
+		}
+		return false
+	}
+	return user.Status == model.UserStatusActive
+}
+
+func (b *botManager) inlineFormResult(form *model.Form) tgbotapi.InlineQueryResultArticle {
+	link := b.templator.UserPageLink(&form.User)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonURL("Профиль на сайте", link)))
+	return tgbotapi.InlineQueryResultArticle{
+		Type:  "article",
+		ID:    strconv.FormatUint(uint64(form.ID), 10),
+		Title: form.Name,
+		InputMessageContent: tgbotapi.InputTextMessageContent{
+			Text:                  b.templator.InlineFormMessage(form),
+			ParseMode:             tgbotapi.ModeHTML,
+			DisableWebPagePreview: true,
+		},
+		ReplyMarkup: &keyboard,
+		URL:         link,
+		HideURL:     true,
+		Description: b.templator.InlineFormDescription(form),
+	}
+}
@@ -0,0 +1,49 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func Test_processInlineQuery(t *testing.T) {
+	answer := func(t *testing.T, b *botManager) tgbotapi.InlineConfig {
+		select {
+		case message := <-b.messagesChan:
+			return message.message.(tgbotapi.InlineConfig)
+		default:
+			t.Fatal("Inline query is not answered")
+			return tgbotapi.InlineConfig{}
+		}
+	}
+	inlineQuery := &tgbotapi.InlineQuery{ID: "query", From: &tgbotapi.User{ID: 10}, Query: " chess ", Offset: "20"}
+
+	t.Run("Only members can search", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetUserByTelegramID(gomock.Any(), int64(10)).Return(&model.User{TelegramID: 10, Status: model.UserStatusNotActive}, nil)
+		b.processInlineQuery(inlineQuery)
+		got := answer(t, b)
+		assert.Empty(t, got.Results)
+		assert.True(t, got.IsPersonal)
+		assert.Equal(t, b.templator.InlineMembersOnly(), got.SwitchPMText)
+	})
+	t.Run("Forms are found page by page", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetUserByTelegramID(gomock.Any(), int64(10)).Return(&model.User{TelegramID: 10, Status: model.UserStatusActive}, nil)
+		forms := make([]*model.Form, inlineResultsLimit)
+		for i := range forms {
+			forms[i] = &model.Form{Model: gorm.Model{ID: uint(i + 1)}, Name: "Аня", User: model.User{TelegramID: int64(100 + i)}}
+		}
+		dbMock.EXPECT().SearchAcceptedForms(gomock.Any(), "chess", 20, inlineResultsLimit).Return(forms, nil)
+		b.processInlineQuery(inlineQuery)
+		got := answer(t, b)
+		assert.Len(t, got.Results, inlineResultsLimit)
+		assert.Equal(t, "40", got.NextOffset)
+		article := got.Results[0].(tgbotapi.InlineQueryResultArticle)
+		assert.Equal(t, "1", article.ID)
+		assert.Equal(t, "https://example.com/user/100", article.URL)
+	})
+}
@@ -0,0 +1,180 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	inviteLinkTTL = 7 * 24 * time.Hour
+	// inviteLinkMinTTL is how long an existing link must still be valid to be sent again.
+	inviteLinkMinTTL        = time.Hour
+	inviteLinkCheckInterval = time.Hour
+)
+
+// personalInviteLink returns the user's active link or creates a new one. The link creates
+// a join request, so processChatJoinRequest can check who it belongs to.
+func (b *botManager) personalInviteLink(user *model.User) (*model.InviteLink, error) {
+	link, err := b.db.GetActiveInviteLink(b.ctx, user.TelegramID, time.Now().Add(inviteLinkMinTTL))
+	if err == nil {
+		return link, nil
+	}
+	if !errors.Is(err, noRecordError) {
+		return nil, err
+	}
+
+	expireAt := time.Now().Add(inviteLinkTTL)
+	// The link is needed right away, so the request doesn't go through the outbox
+	response, err := b.bot.Request(tgbotapi.CreateChatInviteLinkConfig{
+		ChatConfig:         tgbotapi.ChatConfig{ChatID: b.groupID},
+		Name:               fmt.Sprintf("user %d", user.TelegramID),
+		ExpireDate:         int(expireAt.Unix()),
+		CreatesJoinRequest: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var chatInviteLink tgbotapi.ChatInviteLink
+	err = json.Unmarshal(response.Result, &chatInviteLink)
+	if err != nil {
+		return nil, err
+	}
+	link, err = b.db.CreateInviteLink(b.ctx, &model.InviteLink{
+		UserTelegramId: user.TelegramID,
+		Link:           chatInviteLink.InviteLink,
+		ExpireAt:       expireAt,
+		Status:         model.InviteLinkStatusActive,
+	})
+	if err != nil {
+		// A link nobody knows about must not stay valid
+		b.send(tgbotapi.RevokeChatInviteLinkConfig{
+			ChatConfig: tgbotapi.ChatConfig{ChatID: b.groupID},
+			InviteLink: chatInviteLink.InviteLink,
+		})
+		return nil, err
+	}
+	b.logger.Named("personalInviteLink").Info("Invite link created", zap.Int64("telegram_id", user.TelegramID), zap.Uint("linkID", link.ID))
+	return link, nil
+}
+
+// sendAcceptedInvite congratulates the accepted user and sends the personal invite link.
+func (b *botManager) sendAcceptedInvite(user *model.User) {
+	link, err := b.personalInviteLink(user)
+	if err != nil {
+		b.logger.Named("sendAcceptedInvite").Error("Error while creating invite link", zap.Error(err), zap.Int64("telegram_id", user.TelegramID))
+		b.send(tgbotapi.NewMessage(user.TelegramID, b.templator.InviteLinkFailed()))
+		return
+	}
+	msg := tgbotapi.NewMessage(user.TelegramID, b.templator.AcceptUserReply(link.Link))
+	msg.ParseMode = tgbotapi.ModeHTML
+	b.send(msg)
+}
+
+// processInviteCommand sends the personal link again, e.g. when the first one expired
+// or the user left the group and wants to come back.
+func (b *botManager) processInviteCommand(message *tgbotapi.Message, _ commandArgs) {
+	b.logger.Named("processInviteCommand").Debug("Processing invite command")
+	user, err := b.db.GetUserByTelegramID(b.ctx, message.From.ID)
+	if err != nil {
+		b.logger.Named("processInviteCommand").Error("Error while getting user", zap.Error(err))
+		return
+	}
+	switch user.Status {
+	case model.UserStatusAccepted, model.UserStatusNotActive:
+	case model.UserStatusActive:
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.InviteAlreadyMember()))
+		return
+	default:
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.InviteNotAllowed()))
+		return
+	}
+	link, err := b.personalInviteLink(user)
+	if err != nil {
+		b.logger.Named("processInviteCommand").Error("Error while creating invite link", zap.Error(err), zap.Int64("telegram_id", user.TelegramID))
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.InviteLinkFailed()))
+		return
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.templator.InviteLinkReply(link))
+	msg.ParseMode = tgbotapi.ModeHTML
+	b.send(msg)
+}
+
+// checkJoinRequestLink tells whether the request came through the requester's own active link.
+// It returns the link to mark it used, and the reason for the staff if the link doesn't fit.
+func (b *botManager) checkJoinRequestLink(request *tgbotapi.ChatJoinRequest) (*model.InviteLink, string) {
+	if request.InviteLink == nil {
+		return nil, "заявка подана не по личной ссылке"
+	}
+	link, err := b.db.GetInviteLinkByLink(b.ctx, request.InviteLink.InviteLink)
+	if err != nil {
+		if !errors.Is(err, noRecordError) {
+			b.logger.Named("checkJoinRequestLink").Error("Error while getting invite link", zap.Error(err))
+		}
+		return nil, "заявка подана не по личной ссылке"
+	}
+	if link.UserTelegramId != request.From.ID {
+		return nil, fmt.Sprintf("заявка подана по чужой ссылке, её выдали пользователю %d", link.UserTelegramId)
+	}
+	if link.Status != model.InviteLinkStatusActive || link.ExpireAt.Before(time.Now()) {
+		return nil, "ссылка уже использована или отозвана"
+	}
+	return link, ""
+}
+
+// useInviteLink marks the link used and revokes it, so it can't be used twice.
+func (b *botManager) useInviteLink(link *model.InviteLink) {
+	result, err := b.db.MarkInviteLinkUsed(b.ctx, link.ID)
+	if err != nil {
+		b.logger.Named("useInviteLink").Error("Error while marking invite link used", zap.Error(err), zap.Uint("linkID", link.ID))
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+	b.send(tgbotapi.RevokeChatInviteLinkConfig{
+		ChatConfig: tgbotapi.ChatConfig{ChatID: b.groupID},
+		InviteLink: link.Link,
+	})
+}
+
+func (b *botManager) revokeInviteLinks(links []*model.InviteLink) {
+	for _, link := range links {
+		result, err := b.db.RevokeInviteLink(b.ctx, link.ID)
+		if err != nil {
+			b.logger.Named("revokeInviteLinks").Error("Error while revoking invite link", zap.Error(err), zap.Uint("linkID", link.ID))
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		b.logger.Named("revokeInviteLinks").Info("Invite link revoked", zap.Uint("linkID", link.ID), zap.Int64("telegram_id", link.UserTelegramId))
+		b.send(tgbotapi.RevokeChatInviteLinkConfig{
+			ChatConfig: tgbotapi.ChatConfig{ChatID: b.groupID},
+			InviteLink: link.Link,
+		})
+	}
+}
+
+// revokeUserInviteLinks revokes the links of a rejected or banned user.
+func (b *botManager) revokeUserInviteLinks(telegramID int64) {
+	links, err := b.db.GetActiveInviteLinksByUser(b.ctx, telegramID)
+	if err != nil {
+		b.logger.Named("revokeUserInviteLinks").Error("Error while getting invite links", zap.Error(err), zap.Int64("telegram_id", telegramID))
+		return
+	}
+	b.revokeInviteLinks(links)
+}
+
+func (b *botManager) revokeExpiredInviteLinks() {
+	links, err := b.db.GetExpiredInviteLinks(b.ctx, time.Now())
+	if err != nil {
+		b.logger.Named("revokeExpiredInviteLinks").Error("Error while getting expired invite links", zap.Error(err))
+		return
+	}
+	b.revokeInviteLinks(links)
+}
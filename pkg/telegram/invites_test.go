@@ -0,0 +1,54 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_checkJoinRequestLink(t *testing.T) {
+	request := func(link string) *tgbotapi.ChatJoinRequest {
+		return &tgbotapi.ChatJoinRequest{
+			From:       tgbotapi.User{ID: 10},
+			InviteLink: &tgbotapi.ChatInviteLink{InviteLink: link},
+		}
+	}
+
+	t.Run("Own link", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		link := &model.InviteLink{UserTelegramId: 10, Link: "own", Status: model.InviteLinkStatusActive, ExpireAt: time.Now().Add(time.Hour)}
+		dbMock.EXPECT().GetInviteLinkByLink(gomock.Any(), "own").Return(link, nil)
+		got, reason := b.checkJoinRequestLink(request("own"))
+		assert.Equal(t, link, got)
+		assert.Empty(t, reason)
+	})
+	t.Run("Someone else's link", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		link := &model.InviteLink{UserTelegramId: 11, Link: "other", Status: model.InviteLinkStatusActive, ExpireAt: time.Now().Add(time.Hour)}
+		dbMock.EXPECT().GetInviteLinkByLink(gomock.Any(), "other").Return(link, nil)
+		got, reason := b.checkJoinRequestLink(request("other"))
+		assert.Nil(t, got)
+		assert.NotEmpty(t, reason)
+	})
+	t.Run("Used link", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		link := &model.InviteLink{UserTelegramId: 10, Link: "used", Status: model.InviteLinkStatusUsed, ExpireAt: time.Now().Add(time.Hour)}
+		dbMock.EXPECT().GetInviteLinkByLink(gomock.Any(), "used").Return(link, nil)
+		got, _ := b.checkJoinRequestLink(request("used"))
+		assert.Nil(t, got)
+	})
+	t.Run("Unknown link", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetInviteLinkByLink(gomock.Any(), "shared").Return(nil, noRecordError)
+		got, _ := b.checkJoinRequestLink(request("shared"))
+		assert.Nil(t, got)
+	})
+	t.Run("No link", func(t *testing.T) {
+		b, _ := newTestBot(t)
+		got, _ := b.checkJoinRequestLink(&tgbotapi.ChatJoinRequest{From: tgbotapi.User{ID: 10}})
+		assert.Nil(t, got)
+	})
+}
@@ -0,0 +1,72 @@
+package telegram
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+// startJob runs job in the background on a bot whose context is canceled as soon as the bot
+// starts stopping. Stop waits for the started jobs.
+func (b *botManager) startJob(job func(jobs *botManager)) {
+	jobs := b.withContext(b.jobsCtx)
+	b.jobs.Add(1)
+	go func() {
+		defer b.jobs.Done()
+		job(jobs)
+	}()
+}
+
+// runPeriodically starts a job calling run every interval until the bot is stopped. A panic
+// in run is logged and doesn't stop the next runs.
+func (b *botManager) runPeriodically(name string, interval time.Duration, run func(jobs *botManager)) {
+	b.startJob(func(jobs *botManager) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-jobs.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			jobs.runJob(name, func() {
+				run(jobs)
+			})
+		}
+	})
+}
+
+func (b *botManager) runJob(name string, job func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Named("runJob").Error("Panic in job", zap.String("job", name), zap.Any("panic", r))
+		}
+	}()
+	b.logger.Named("runJob").Debug("Running job", zap.String("job", name))
+	job()
+}
+
+// claimPeriod saves period under key as done and returns true, unless a period less than
+// interval before it is saved already. The period is saved before the job does its work,
+// a lost run is better than a repeated one. It returns false if the state can't be read or saved.
+func (b *botManager) claimPeriod(key string, period time.Time, interval time.Duration) bool {
+	state, err := b.db.GetBotState(b.ctx, key)
+	if err != nil && !errors.Is(err, noRecordError) {
+		b.logger.Named("claimPeriod").Error("Error while getting last period", zap.String("key", key), zap.Error(err))
+		return false
+	}
+	if state != nil {
+		claimed, err := time.Parse(time.RFC3339, state.Value)
+		if err != nil {
+			b.logger.Named("claimPeriod").Warn("Invalid last period", zap.String("key", key), zap.String("value", state.Value), zap.Error(err))
+		} else if period.Sub(claimed) < interval {
+			return false
+		}
+	}
+	err = b.db.SetBotState(b.ctx, key, period.Format(time.RFC3339))
+	if err != nil {
+		b.logger.Named("claimPeriod").Error("Error while saving period", zap.String("key", key), zap.Error(err))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_startJob(t *testing.T) {
+	t.Run("Jobs are stopped before the bot", func(t *testing.T) {
+		b, _ := newTestBot(t)
+		b.jobsCtx, b.stopJobs = context.WithCancel(b.ctx)
+		b.jobs = &sync.WaitGroup{}
+		stopped := false
+		b.startJob(func(jobs *botManager) {
+			<-jobs.ctx.Done()
+			stopped = true
+		})
+		b.stopJobs()
+		b.jobs.Wait()
+		assert.True(t, stopped)
+		assert.NoError(t, b.ctx.Err())
+	})
+}
+
+func Test_claimPeriod(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	saved := func(at time.Time) *model.BotState {
+		return &model.BotState{Key: "key", Value: at.Format(time.RFC3339)}
+	}
+
+	t.Run("Never claimed", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetBotState(gomock.Any(), "key").Return(nil, noRecordError)
+		dbMock.EXPECT().SetBotState(gomock.Any(), "key", now.Format(time.RFC3339)).Return(nil)
+		assert.True(t, b.claimPeriod("key", now, time.Hour))
+	})
+	t.Run("Claimed recently", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetBotState(gomock.Any(), "key").Return(saved(now.Add(-time.Minute)), nil)
+		assert.False(t, b.claimPeriod("key", now, time.Hour))
+	})
+	t.Run("Interval is over", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetBotState(gomock.Any(), "key").Return(saved(now.Add(-time.Hour)), nil)
+		dbMock.EXPECT().SetBotState(gomock.Any(), "key", now.Format(time.RFC3339)).Return(nil)
+		assert.True(t, b.claimPeriod("key", now, time.Hour))
+	})
+	t.Run("Not claimed if it can't be saved", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetBotState(gomock.Any(), "key").Return(nil, noRecordError)
+		dbMock.EXPECT().SetBotState(gomock.Any(), "key", gomock.Any()).Return(errors.New("connection lost"))
+		assert.False(t, b.claimPeriod("key", now, time.Hour))
+	})
+}
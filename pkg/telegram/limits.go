@@ -0,0 +1,127 @@
+package telegram
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"golang.org/x/time/rate"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Telegram limits, see https://core.telegram.org/bots/faq#my-bot-is-hitting-limits-how-do-i-avoid-this
+var (
+	globalRateLimit  = rate.Limit(30)
+	globalRateBurst  = 30
+	privateRateLimit = rate.Limit(1)
+	privateRateBurst = 3
+	groupRateLimit   = rate.Every(3 * time.Second)
+	groupRateBurst   = 3
+	// reconcileRateLimit keeps most of the global limit for the sender
+	reconcileRateLimit = rate.Limit(5)
+)
+
+const (
+	delayReasonChatRateLimit = "chat_rate_limit"
+	delayReasonChatParked    = "chat_parked"
+	delayReasonRetryAfter    = "retry_after"
+)
+
+var senderMetrics = new(expvar.Map).Init()
+
+func init() {
+	expvar.Publish("telegram_sender", senderMetrics)
+}
+
+// sendScheduler keeps the global limiter and a limiter per chat. A chat that got
+// 429 Too Many Requests is parked for retry_after without affecting other chats.
+type sendScheduler struct {
+	global *rate.Limiter
+
+	mu    sync.Mutex
+	chats map[int64]*chatLimiter
+}
+
+type chatLimiter struct {
+	limiter     *rate.Limiter
+	parkedUntil time.Time
+}
+
+func newSendScheduler() *sendScheduler {
+	s := &sendScheduler{
+		global: rate.NewLimiter(globalRateLimit, globalRateBurst),
+		chats:  map[int64]*chatLimiter{},
+	}
+	senderMetrics.Set("parked_chats", expvar.Func(func() any {
+		return s.parkedChats()
+	}))
+	return s
+}
+
+// wait blocks until the global limit allows one more request.
+func (s *sendScheduler) wait(ctx context.Context) error {
+	return s.global.Wait(ctx)
+}
+
+// reserve takes a slot for a request to the chat. If the chat can't take the request now,
+// it returns how long the request has to be delayed and why.
+func (s *sendScheduler) reserve(chatID int64) (time.Duration, string) {
+	if chatID == 0 {
+		return 0, ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	chat := s.chat(chatID)
+	if chat.parkedUntil.After(now) {
+		return chat.parkedUntil.Sub(now), delayReasonChatParked
+	}
+	reservation := chat.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return delay, delayReasonChatRateLimit
+	}
+	return 0, ""
+}
+
+// park stops requests to the chat for the duration Telegram asked for.
+func (s *sendScheduler) park(chatID int64, duration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chat := s.chat(chatID)
+	until := time.Now().Add(duration)
+	if until.After(chat.parkedUntil) {
+		chat.parkedUntil = until
+	}
+}
+
+func (s *sendScheduler) chat(chatID int64) *chatLimiter {
+	chat, ok := s.chats[chatID]
+	if !ok {
+		limiter := rate.NewLimiter(privateRateLimit, privateRateBurst)
+		if chatID < 0 {
+			limiter = rate.NewLimiter(groupRateLimit, groupRateBurst)
+		}
+		chat = &chatLimiter{limiter: limiter}
+		s.chats[chatID] = chat
+	}
+	return chat
+}
+
+func (s *sendScheduler) parkedChats() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	parked := map[string]string{}
+	for chatID, chat := range s.chats {
+		if chat.parkedUntil.After(now) {
+			parked[strconv.FormatInt(chatID, 10)] = chat.parkedUntil.Format(time.RFC3339)
+		}
+	}
+	return parked
+}
+
+func delayReasonDescription(reason string, delay time.Duration) string {
+	return fmt.Sprintf("%s: %s", reason, delay.Round(time.Millisecond))
+}
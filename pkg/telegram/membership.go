@@ -0,0 +1,141 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// allowedUpdates are requested explicitly, because Telegram doesn't send chat_member by default.
+var allowedUpdates = []string{
+	"message",
+	"callback_query",
+	"inline_query",
+	"chat_join_request",
+	"poll",
+	"poll_answer",
+	"chat_member",
+	"my_chat_member",
+}
+
+// Transitions of a chat member, they are only logged.
+const (
+	memberJoined     = "joined"
+	memberLeft       = "left"
+	memberKicked     = "kicked"
+	memberRestricted = "restricted"
+	memberPromoted   = "promoted"
+	memberDemoted    = "demoted"
+	memberUnchanged  = "unchanged"
+)
+
+func memberTransition(oldMember tgbotapi.ChatMember, newMember tgbotapi.ChatMember) string {
+	wasMember, isMember := isGroupMember(oldMember), isGroupMember(newMember)
+	wasAdmin := oldMember.IsAdministrator() || oldMember.IsCreator()
+	isAdmin := newMember.IsAdministrator() || newMember.IsCreator()
+	switch {
+	case newMember.WasKicked():
+		return memberKicked
+	case wasMember && !isMember:
+		return memberLeft
+	case !wasMember && isMember:
+		return memberJoined
+	case !wasAdmin && isAdmin:
+		return memberPromoted
+	case wasAdmin && !isAdmin:
+		return memberDemoted
+	case newMember.Status == "restricted":
+		return memberRestricted
+	default:
+		return memberUnchanged
+	}
+}
+
+// userFromTelegram returns the user to pass to UpdateOrCreateUser.
+func userFromTelegram(from *tgbotapi.User, status string) *model.User {
+	user := &model.User{
+		TelegramID: from.ID,
+		FirstName:  from.FirstName,
+		Status:     status,
+	}
+	if from.LastName != "" {
+		user.LastName = &from.LastName
+	}
+	if from.UserName != "" {
+		user.Username = &from.UserName
+	}
+	return user
+}
+
+// processChatMember keeps the status of group members. Unlike the service messages, chat_member
+// updates come for every change, even in large supergroups.
+func (b *botManager) processChatMember(update *tgbotapi.ChatMemberUpdated) {
+	if update.Chat.ID != b.groupID || update.NewChatMember.User == nil || update.NewChatMember.User.IsBot {
+		return
+	}
+	from := update.NewChatMember.User
+	transition := memberTransition(update.OldChatMember, update.NewChatMember)
+	b.logger.Named("processChatMember").Info("Chat member changed", zap.Int64("telegram_id", from.ID), zap.String("transition", transition), zap.String("old_status", update.OldChatMember.Status), zap.String("new_status", update.NewChatMember.Status), zap.Int64("by", update.From.ID))
+	status := model.UserStatusNotActive
+	if isGroupMember(update.NewChatMember) {
+		status = model.UserStatusActive
+	}
+	_, err := b.db.UpdateOrCreateUser(b.ctx, userFromTelegram(from, status))
+	if err != nil {
+		b.logger.Named("processChatMember").Error("Error while updating user", zap.Error(err))
+	}
+}
+
+// botRight is an admin right the bot needs in the group.
+type botRight struct {
+	description string
+	has         func(member tgbotapi.ChatMember) bool
+}
+
+var botRights = []botRight{
+	{
+		description: "блокировать участников",
+		has:         func(member tgbotapi.ChatMember) bool { return member.CanRestrictMembers },
+	},
+	{
+		description: "приглашать пользователей и принимать заявки",
+		has:         func(member tgbotapi.ChatMember) bool { return member.CanInviteUsers },
+	},
+}
+
+// lostBotRights returns the descriptions of the rights the bot had before the change and doesn't have now.
+func lostBotRights(oldMember tgbotapi.ChatMember, newMember tgbotapi.ChatMember) []string {
+	hasRight := func(member tgbotapi.ChatMember, right botRight) bool {
+		return member.IsCreator() || (member.IsAdministrator() && right.has(member))
+	}
+	var lost []string
+	for _, right := range botRights {
+		if hasRight(oldMember, right) && !hasRight(newMember, right) {
+			lost = append(lost, right.description)
+		}
+	}
+	return lost
+}
+
+// processMyChatMember alerts the admins when the bot is removed from the group or loses its rights there.
+func (b *botManager) processMyChatMember(update *tgbotapi.ChatMemberUpdated) {
+	if update.Chat.ID != b.groupID {
+		return
+	}
+	b.logger.Named("processMyChatMember").Info("Bot's chat member changed", zap.String("old_status", update.OldChatMember.Status), zap.String("new_status", update.NewChatMember.Status), zap.Int64("by", update.From.ID))
+	by := userFromTelegram(&update.From, "")
+	var text string
+	if !isGroupMember(update.NewChatMember) {
+		text = b.templator.BotRemovedFromGroup(by)
+	} else if lost := lostBotRights(update.OldChatMember, update.NewChatMember); len(lost) > 0 {
+		text = b.templator.BotLostRights(by, lost)
+	} else {
+		return
+	}
+	b.logger.Named("processMyChatMember").Warn("Bot can't manage the group anymore", zap.String("new_status", update.NewChatMember.Status))
+	b.notifyStaff(model.PermissionManageRoles, func(chatID int64) tgbotapi.Chattable {
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = tgbotapi.ModeHTML
+		return msg
+	})
+}
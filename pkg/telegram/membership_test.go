@@ -0,0 +1,46 @@
+package telegram
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_memberTransition(t *testing.T) {
+	left := tgbotapi.ChatMember{Status: "left"}
+	member := tgbotapi.ChatMember{Status: "member"}
+	tests := []struct {
+		name      string
+		oldMember tgbotapi.ChatMember
+		newMember tgbotapi.ChatMember
+		want      string
+	}{
+		{name: "Joined", oldMember: left, newMember: member, want: memberJoined},
+		{name: "Left", oldMember: member, newMember: left, want: memberLeft},
+		{name: "Kicked", oldMember: member, newMember: tgbotapi.ChatMember{Status: "kicked"}, want: memberKicked},
+		{name: "Restricted", oldMember: member, newMember: tgbotapi.ChatMember{Status: "restricted", IsMember: true}, want: memberRestricted},
+		{name: "Restricted and left", oldMember: member, newMember: tgbotapi.ChatMember{Status: "restricted"}, want: memberLeft},
+		{name: "Promoted", oldMember: member, newMember: tgbotapi.ChatMember{Status: "administrator"}, want: memberPromoted},
+		{name: "Demoted", oldMember: tgbotapi.ChatMember{Status: "administrator"}, newMember: member, want: memberDemoted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, memberTransition(tt.oldMember, tt.newMember))
+		})
+	}
+}
+
+func Test_lostBotRights(t *testing.T) {
+	admin := tgbotapi.ChatMember{Status: "administrator", CanRestrictMembers: true, CanInviteUsers: true}
+	t.Run("Demoted", func(t *testing.T) {
+		assert.Len(t, lostBotRights(admin, tgbotapi.ChatMember{Status: "member"}), len(botRights))
+	})
+	t.Run("One right taken", func(t *testing.T) {
+		newMember := admin
+		newMember.CanInviteUsers = false
+		assert.Equal(t, []string{botRights[1].description}, lostBotRights(admin, newMember))
+	})
+	t.Run("Promoted", func(t *testing.T) {
+		assert.Empty(t, lostBotRights(tgbotapi.ChatMember{Status: "member"}, admin))
+	})
+}
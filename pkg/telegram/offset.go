@@ -0,0 +1,178 @@
+package telegram
+
+import (
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	updateOffsetKey = "telegram_update_offset"
+	// Telegram chooses the next update ID at random after a week without updates,
+	// so an older offset could hide all the new updates.
+	updateOffsetMaxAge           = 7 * 24 * time.Hour
+	processedUpdatesRetention    = 7 * 24 * time.Hour
+	processedUpdatesCleanupEvery = time.Hour
+	// inFlightUpdatesDelay is how long to wait before asking for updates again when
+	// Telegram returned only the updates that are still being processed.
+	inFlightUpdatesDelay = time.Second
+)
+
+// offsetTracker follows the updates that were received but not processed yet. The committed
+// offset is the first update that is not processed, so Telegram keeps the unfinished updates
+// and they are received again after a restart.
+type offsetTracker struct {
+	mu       sync.Mutex
+	inFlight map[int]struct{}
+	// received is the highest update ID received so far.
+	received  int
+	committed int
+	// progress is signaled when the committed offset moves.
+	progress chan struct{}
+
+	saveMu sync.Mutex
+	saved  int
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{
+		inFlight: map[int]struct{}{},
+		progress: make(chan struct{}, 1),
+	}
+}
+
+// reset starts tracking from the offset loaded from the database.
+func (t *offsetTracker) reset(offset int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inFlight = map[int]struct{}{}
+	t.received = offset - 1
+	t.committed = offset
+	t.saved = offset
+}
+
+// receive returns false if the update was already received.
+func (t *offsetTracker) receive(updateID int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if updateID <= t.received {
+		return false
+	}
+	t.received = updateID
+	t.inFlight[updateID] = struct{}{}
+	return true
+}
+
+// finish returns the committed offset and whether it has moved.
+func (t *offsetTracker) finish(updateID int) (int, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.inFlight, updateID)
+	committed := t.received + 1
+	for id := range t.inFlight {
+		if id < committed {
+			committed = id
+		}
+	}
+	if committed <= t.committed {
+		return t.committed, false
+	}
+	t.committed = committed
+	select {
+	case t.progress <- struct{}{}:
+	default:
+	}
+	return committed, true
+}
+
+func (t *offsetTracker) offset() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.committed
+}
+
+// save stores the offset unless a greater one is already stored.
+func (t *offsetTracker) save(offset int, store func(offset int) error) error {
+	t.saveMu.Lock()
+	defer t.saveMu.Unlock()
+	if offset <= t.saved {
+		return nil
+	}
+	err := store(offset)
+	if err != nil {
+		return err
+	}
+	t.saved = offset
+	return nil
+}
+
+func (b *botManager) loadUpdateOffset() int {
+	state, err := b.db.GetBotState(b.ctx, updateOffsetKey)
+	if err != nil {
+		if errors.Is(err, noRecordError) {
+			return 0
+		}
+		b.logger.Named("loadUpdateOffset").Error("Error while loading update offset", zap.Error(err))
+		return 0
+	}
+	if time.Since(state.UpdatedAt) > updateOffsetMaxAge {
+		b.logger.Named("loadUpdateOffset").Info("Update offset is too old, ignoring it", zap.Time("updated_at", state.UpdatedAt))
+		return 0
+	}
+	offset, err := strconv.Atoi(state.Value)
+	if err != nil {
+		b.logger.Named("loadUpdateOffset").Error("Error while parsing update offset", zap.Error(err), zap.String("value", state.Value))
+		return 0
+	}
+	b.logger.Named("loadUpdateOffset").Info("Update offset loaded", zap.Int("offset", offset))
+	return offset
+}
+
+// finishUpdate commits the offset after the update is processed. It's called on the bot
+// itself rather than a handler's copy, so the offset is saved even if the handler timed out.
+// In webhook mode Telegram tracks the delivered updates itself.
+func (b *botManager) finishUpdate(updateID int) {
+	if b.UpdatesMode() != UpdatesModePolling {
+		return
+	}
+	offset, moved := b.offsets.finish(updateID)
+	if !moved {
+		return
+	}
+	err := b.offsets.save(offset, func(offset int) error {
+		return b.db.SetBotState(b.ctx, updateOffsetKey, strconv.Itoa(offset))
+	})
+	if err != nil {
+		b.logger.Named("finishUpdate").Error("Error while saving update offset", zap.Error(err), zap.Int("offset", offset))
+	}
+}
+
+// processUpdateOnce skips updates that were processed before a restart. If the check
+// fails, the update is processed anyway: processing twice is better than losing it.
+func (b *botManager) processUpdateOnce(update tgbotapi.Update) {
+	processed, err := b.db.IsUpdateProcessed(b.ctx, update.UpdateID)
+	if err != nil {
+		b.logger.Named("processUpdateOnce").Error("Error while checking if update is processed", zap.Error(err), zap.Int("update_id", update.UpdateID))
+	}
+	if processed {
+		b.logger.Named("processUpdateOnce").Info("Update is already processed, skipping", zap.Int("update_id", update.UpdateID))
+		return
+	}
+	b.processUpdate(update)
+	err = b.db.MarkUpdateProcessed(b.ctx, update.UpdateID)
+	if err != nil {
+		b.logger.Named("processUpdateOnce").Error("Error while marking update processed", zap.Error(err), zap.Int("update_id", update.UpdateID))
+	}
+}
+
+func (b *botManager) deleteProcessedUpdates() {
+	result, err := b.db.DeleteProcessedUpdates(b.ctx, time.Now().Add(-processedUpdatesRetention))
+	if err != nil {
+		b.logger.Named("deleteProcessedUpdates").Error("Error while deleting processed updates", zap.Error(err))
+		return
+	}
+	b.logger.Named("deleteProcessedUpdates").Debug("Processed updates deleted", zap.Int64("count", result.RowsAffected))
+}
@@ -0,0 +1,51 @@
+package telegram
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_offsetTracker(t *testing.T) {
+	t.Run("Commits only processed updates", func(t *testing.T) {
+		tracker := newOffsetTracker()
+		tracker.reset(10)
+		assert.True(t, tracker.receive(10))
+		assert.True(t, tracker.receive(11))
+		assert.True(t, tracker.receive(12))
+
+		offset, moved := tracker.finish(11)
+		assert.False(t, moved)
+		assert.Equal(t, 10, offset)
+
+		offset, moved = tracker.finish(10)
+		assert.True(t, moved)
+		assert.Equal(t, 12, offset)
+
+		offset, moved = tracker.finish(12)
+		assert.True(t, moved)
+		assert.Equal(t, 13, offset)
+		assert.Equal(t, 13, tracker.offset())
+	})
+
+	t.Run("Skips received updates", func(t *testing.T) {
+		tracker := newOffsetTracker()
+		tracker.reset(10)
+		assert.False(t, tracker.receive(9))
+		assert.True(t, tracker.receive(10))
+		assert.False(t, tracker.receive(10))
+	})
+
+	t.Run("Saves only greater offsets", func(t *testing.T) {
+		tracker := newOffsetTracker()
+		tracker.reset(10)
+		var stored []int
+		store := func(offset int) error {
+			stored = append(stored, offset)
+			return nil
+		}
+		assert.NoError(t, tracker.save(12, store))
+		assert.NoError(t, tracker.save(11, store))
+		assert.NoError(t, tracker.save(13, store))
+		assert.Equal(t, []int{12, 13}, stored)
+	})
+}
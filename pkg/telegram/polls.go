@@ -0,0 +1,236 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"gorm.io/gen"
+	"time"
+)
+
+const pollCheckInterval = time.Minute
+
+// PollRules decide on a membership poll when its voting time is over.
+type PollRules struct {
+	Duration time.Duration
+	// Quorum is the minimal number of votes for an automatic decision.
+	Quorum int
+	// ApprovalThreshold is the share of votes, from 0 to 1, an option needs to win.
+	ApprovalThreshold float64
+}
+
+var DefaultPollRules = PollRules{
+	Duration:          24 * time.Hour,
+	Quorum:            3,
+	ApprovalThreshold: 0.6,
+}
+
+// evaluate returns the decision by the votes, or an empty string if the result is inconclusive.
+// Accepting is checked first, so with a threshold of 0.5 or less a tie accepts the user.
+func (r PollRules) evaluate(acceptVotes int, rejectVotes int) string {
+	total := acceptVotes + rejectVotes
+	if total == 0 || total < r.Quorum {
+		return ""
+	}
+	switch {
+	case float64(acceptVotes)/float64(total) >= r.ApprovalThreshold:
+		return model.PollDecisionAccepted
+	case float64(rejectVotes)/float64(total) >= r.ApprovalThreshold:
+		return model.PollDecisionRejected
+	default:
+		return ""
+	}
+}
+
+func (b *botManager) SetPollRules(rules PollRules) {
+	b.pollRules = rules
+}
+
+// sendMembershipPoll posts the poll about accepting the user and links it to the form.
+// The poll is not anonymous, so every vote comes as a poll_answer update.
+func (b *botManager) sendMembershipPoll(user *model.User, form *model.Form, replyTo int) {
+	options := make([]string, 2)
+	options[model.PollOptionAccept] = "Принимаем"
+	options[model.PollOptionReject] = "Отклоняем"
+	poll := tgbotapi.NewPoll(b.groupID, b.templator.NewFormPoll(), options...)
+	poll.IsAnonymous = false
+	poll.ReplyToMessageID = replyTo
+	poll.ReplyMarkup = b.membershipKeyboard(user.TelegramID)
+
+	// The poll ID is only known from the sent message, so the poll doesn't go through the outbox
+	sentPoll, err := b.sendNow(poll)
+	if err != nil {
+		b.logger.Named("sendMembershipPoll").Error("Error while sending poll", zap.Error(err))
+		b.notifyPollNotSent(user)
+		return
+	}
+	if sentPoll.Poll == nil {
+		b.logger.Named("sendMembershipPoll").Error("Sent message has no poll", zap.Int("messageID", sentPoll.MessageID))
+		b.notifyPollNotSent(user)
+		return
+	}
+	_, err = b.db.CreateMembershipPoll(b.ctx, &model.MembershipPoll{
+		PollID:         sentPoll.Poll.ID,
+		ChatID:         sentPoll.Chat.ID,
+		MessageID:      sentPoll.MessageID,
+		FormID:         form.ID,
+		UserTelegramId: user.TelegramID,
+		Status:         model.MembershipPollStatusOpen,
+	})
+	if err != nil {
+		b.logger.Named("sendMembershipPoll").Error("Error while saving poll", zap.Error(err), zap.String("pollID", sentPoll.Poll.ID))
+	}
+}
+
+// notifyPollNotSent gives the decision on the user to the staff when there is no poll
+// in the group, neither closeDuePolls nor a button under the poll would decide on them.
+func (b *botManager) notifyPollNotSent(user *model.User) {
+	b.notifyStaff(model.PermissionDecideMembership, func(chatID int64) tgbotapi.Chattable {
+		msg := tgbotapi.NewMessage(chatID, b.templator.MembershipPollNotSent(user))
+		msg.ParseMode = tgbotapi.ModeHTML
+		msg.ReplyMarkup = b.membershipKeyboard(user.TelegramID)
+		return msg
+	})
+}
+
+func (b *botManager) membershipKeyboard(userTelegramID int64) tgbotapi.InlineKeyboardMarkup {
+	acceptUser := tgbotapi.NewInlineKeyboardButtonData("Принять (для админов)", b.callbacks.MustEncode(actionUserAccept, userTelegramID))
+	rejectUser := tgbotapi.NewInlineKeyboardButtonData("Отклонить (для админов)", b.callbacks.MustEncode(actionUserReject, userTelegramID))
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(acceptUser, rejectUser))
+}
+
+// processPoll stores the tallies Telegram sends on every change of the poll.
+func (b *botManager) processPoll(poll *tgbotapi.Poll) {
+	b.logger.Named("processPoll").Debug("Processing poll", zap.String("pollID", poll.ID), zap.Int("total_voter_count", poll.TotalVoterCount))
+	if len(poll.Options) <= model.PollOptionReject {
+		b.logger.Named("processPoll").Info("Poll is not a membership poll", zap.String("pollID", poll.ID))
+		return
+	}
+	err := b.db.UpdateMembershipPollTally(b.ctx, poll.ID, poll.Options[model.PollOptionAccept].VoterCount, poll.Options[model.PollOptionReject].VoterCount)
+	if err != nil {
+		b.logger.Named("processPoll").Error("Error while updating poll tally", zap.Error(err), zap.String("pollID", poll.ID))
+	}
+}
+
+// processPollAnswer stores the voter's answer, an empty answer means the vote was retracted.
+func (b *botManager) processPollAnswer(answer *tgbotapi.PollAnswer) {
+	b.logger.Named("processPollAnswer").Debug("Processing poll answer", zap.String("pollID", answer.PollID), zap.Int64("voter", answer.User.ID), zap.Ints("options", answer.OptionIDs))
+	poll, err := b.db.GetMembershipPollByPollID(b.ctx, answer.PollID)
+	if err != nil {
+		if errors.Is(err, noRecordError) {
+			b.logger.Named("processPollAnswer").Info("Poll is not a membership poll", zap.String("pollID", answer.PollID))
+			return
+		}
+		b.logger.Named("processPollAnswer").Error("Error while getting poll", zap.Error(err), zap.String("pollID", answer.PollID))
+		return
+	}
+	if len(answer.OptionIDs) == 0 {
+		err = b.db.DeletePollVote(b.ctx, poll.ID, answer.User.ID)
+	} else {
+		err = b.db.SavePollVote(b.ctx, &model.PollVote{
+			MembershipPollID: poll.ID,
+			VoterTelegramId:  answer.User.ID,
+			Option:           answer.OptionIDs[0],
+		})
+	}
+	if err != nil {
+		b.logger.Named("processPollAnswer").Error("Error while saving vote", zap.Error(err), zap.String("pollID", answer.PollID))
+	}
+}
+
+// closeMembershipPoll stores the decision with the final tally and accepts or rejects the
+// user. It returns false if the decision must not be announced: the poll is already closed,
+// so the decision was applied before, or the database failed. A decision on a message
+// without a stored poll, e.g. sent before polls were stored, only changes the user and the
+// returned poll is nil.
+func (b *botManager) closeMembershipPoll(chatID int64, messageID int, user *model.User, decision string, decidedBy *int64) (*model.MembershipPoll, bool, error) {
+	poll, err := b.db.GetMembershipPollByMessage(b.ctx, chatID, messageID)
+	if errors.Is(err, noRecordError) {
+		var result *gen.ResultInfo
+		if decision == model.PollDecisionAccepted {
+			result, err = b.db.AcceptUser(b.ctx, user.ID)
+		} else {
+			result, err = b.db.RejectUser(b.ctx, user.ID)
+		}
+		if err != nil {
+			b.logger.Named("closeMembershipPoll").Error("Error while deciding on user", zap.Error(err), zap.Int64("userTelegramID", user.TelegramID))
+			return nil, false, err
+		}
+		if result.RowsAffected == 0 {
+			b.logger.Named("closeMembershipPoll").Info("User is banned", zap.Int64("userTelegramID", user.TelegramID))
+			return nil, false, nil
+		}
+		return nil, true, nil
+	}
+	if err != nil {
+		b.logger.Named("closeMembershipPoll").Error("Error while getting poll", zap.Error(err), zap.Int64("chatID", chatID), zap.Int("messageID", messageID))
+		return nil, false, err
+	}
+	result, err := b.db.CloseMembershipPoll(b.ctx, poll.ID, user.ID, decision, decidedBy)
+	if err != nil {
+		b.logger.Named("closeMembershipPoll").Error("Error while closing poll", zap.Error(err), zap.Uint("pollID", poll.ID))
+		return poll, false, err
+	}
+	if result.RowsAffected == 0 {
+		b.logger.Named("closeMembershipPoll").Info("Poll is already closed or the user is banned", zap.Uint("pollID", poll.ID), zap.Stringp("decision", poll.Decision))
+		return poll, false, nil
+	}
+	b.logger.Named("closeMembershipPoll").Info("Poll closed", zap.Uint("pollID", poll.ID), zap.String("decision", decision), zap.Int("accept_votes", poll.AcceptVotes), zap.Int("reject_votes", poll.RejectVotes))
+	return poll, true, nil
+}
+
+// closeDuePolls decides on the polls whose voting time is over. Polls without a clear
+// result are stopped and left to the admins.
+func (b *botManager) closeDuePolls() {
+	polls, err := b.db.GetDueMembershipPolls(b.ctx, time.Now().Add(-b.pollRules.Duration))
+	if err != nil {
+		b.logger.Named("closeDuePolls").Error("Error while getting due polls", zap.Error(err))
+		return
+	}
+	for _, poll := range polls {
+		decision := b.pollRules.evaluate(poll.AcceptVotes, poll.RejectVotes)
+		b.logger.Named("closeDuePolls").Info("Voting time is over", zap.Uint("pollID", poll.ID), zap.Int("accept_votes", poll.AcceptVotes), zap.Int("reject_votes", poll.RejectVotes), zap.String("decision", decision))
+		if decision == "" {
+			b.escalateMembershipPoll(poll)
+			continue
+		}
+		b.decideMembership(poll.ChatID, poll.MessageID, &poll.User, decision, nil)
+	}
+}
+
+// escalateMembershipPoll stops the poll but keeps the admin buttons on it.
+func (b *botManager) escalateMembershipPoll(poll *model.MembershipPoll) {
+	result, err := b.db.EscalateMembershipPoll(b.ctx, poll.ID)
+	if err != nil {
+		b.logger.Named("escalateMembershipPoll").Error("Error while escalating poll", zap.Error(err), zap.Uint("pollID", poll.ID))
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+	stopPoll := tgbotapi.NewStopPoll(poll.ChatID, poll.MessageID)
+	keyboard := b.membershipKeyboard(poll.UserTelegramId)
+	stopPoll.ReplyMarkup = &keyboard
+	b.send(stopPoll)
+	groupMsg := tgbotapi.NewMessage(poll.ChatID, b.templator.PollInconclusiveGroupReply())
+	groupMsg.ReplyToMessageID = poll.MessageID
+	b.send(groupMsg)
+	b.notifyStaff(model.PermissionDecideMembership, func(chatID int64) tgbotapi.Chattable {
+		msg := tgbotapi.NewMessage(chatID, b.templator.PollInconclusive(&poll.User, poll))
+		msg.ParseMode = tgbotapi.ModeHTML
+		return msg
+	})
+}
+
+func (b *botManager) processPollsCommand(message *tgbotapi.Message, _ commandArgs) {
+	b.logger.Named("processPollsCommand").Debug("Processing polls command")
+	polls, err := b.db.GetOpenMembershipPolls(b.ctx)
+	if err != nil {
+		b.logger.Named("processPollsCommand").Error("Error while getting polls", zap.Error(err))
+		return
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.templator.PollsList(polls))
+	msg.ParseMode = tgbotapi.ModeHTML
+	b.send(msg)
+}
@@ -0,0 +1,117 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"context"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gen"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestPollRules_evaluate(t *testing.T) {
+	rules := PollRules{
+		Duration:          time.Hour,
+		Quorum:            3,
+		ApprovalThreshold: 0.6,
+	}
+	tests := []struct {
+		name        string
+		acceptVotes int
+		rejectVotes int
+		want        string
+	}{
+		{name: "No votes", want: ""},
+		{name: "No quorum", acceptVotes: 2, want: ""},
+		{name: "Accepted", acceptVotes: 3, rejectVotes: 2, want: model.PollDecisionAccepted},
+		{name: "Rejected", acceptVotes: 1, rejectVotes: 3, want: model.PollDecisionRejected},
+		{name: "Inconclusive", acceptVotes: 2, rejectVotes: 2, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rules.evaluate(tt.acceptVotes, tt.rejectVotes))
+		})
+	}
+
+	t.Run("Tie accepts with half threshold", func(t *testing.T) {
+		rules := PollRules{ApprovalThreshold: 0.5}
+		assert.Equal(t, model.PollDecisionAccepted, rules.evaluate(1, 1))
+	})
+}
+
+func Test_closeMembershipPoll(t *testing.T) {
+	user := &model.User{Model: gorm.Model{ID: 5}, TelegramID: 10}
+	poll := &model.MembershipPoll{Model: gorm.Model{ID: 3}, UserTelegramId: 10, Status: model.MembershipPollStatusOpen}
+
+	t.Run("Poll is closed with the user", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetMembershipPollByMessage(gomock.Any(), int64(-1), 7).Return(poll, nil)
+		dbMock.EXPECT().CloseMembershipPoll(gomock.Any(), uint(3), uint(5), model.PollDecisionAccepted, nil).Return(&gen.ResultInfo{RowsAffected: 1}, nil)
+		got, ok, err := b.closeMembershipPoll(-1, 7, user, model.PollDecisionAccepted, nil)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, poll, got)
+	})
+	t.Run("Poll is already closed", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetMembershipPollByMessage(gomock.Any(), int64(-1), 7).Return(poll, nil)
+		dbMock.EXPECT().CloseMembershipPoll(gomock.Any(), uint(3), uint(5), model.PollDecisionRejected, nil).Return(&gen.ResultInfo{}, nil)
+		_, ok, err := b.closeMembershipPoll(-1, 7, user, model.PollDecisionRejected, nil)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+	t.Run("Message without a stored poll", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetMembershipPollByMessage(gomock.Any(), int64(-1), 7).Return(nil, gorm.ErrRecordNotFound)
+		dbMock.EXPECT().RejectUser(gomock.Any(), uint(5)).Return(&gen.ResultInfo{RowsAffected: 1}, nil)
+		got, ok, err := b.closeMembershipPoll(-1, 7, user, model.PollDecisionRejected, nil)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Nil(t, got)
+	})
+	t.Run("Banned user is not accepted", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetMembershipPollByMessage(gomock.Any(), int64(-1), 7).Return(nil, gorm.ErrRecordNotFound)
+		dbMock.EXPECT().AcceptUser(gomock.Any(), uint(5)).Return(&gen.ResultInfo{}, nil)
+		got, ok, err := b.closeMembershipPoll(-1, 7, user, model.PollDecisionAccepted, nil)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.NotEmpty(t, b.templator.MembershipAlreadyDecided(got))
+	})
+	t.Run("Database errors are not applied", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetMembershipPollByMessage(gomock.Any(), int64(-1), 7).Return(nil, errors.New("connection lost"))
+		_, ok, err := b.closeMembershipPoll(-1, 7, user, model.PollDecisionAccepted, nil)
+		assert.Error(t, err)
+		assert.False(t, ok)
+
+		dbMock.EXPECT().GetMembershipPollByMessage(gomock.Any(), int64(-1), 7).Return(poll, nil)
+		dbMock.EXPECT().CloseMembershipPoll(gomock.Any(), uint(3), uint(5), model.PollDecisionAccepted, nil).Return(nil, errors.New("deadlock"))
+		_, ok, err = b.closeMembershipPoll(-1, 7, user, model.PollDecisionAccepted, nil)
+		assert.Error(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func Test_sendMembershipPoll(t *testing.T) {
+	t.Run("Staff decide if the poll is not sent", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		b.scheduler = newSendScheduler()
+		b.groupID = -100
+		b.bot = fakeBotAPI{send: func(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+			return tgbotapi.Message{}, &tgbotapi.Error{Code: 400, Message: "Bad Request: not enough rights to send polls"}
+		}}
+		user := &model.User{TelegramID: 10}
+		dbMock.EXPECT().GetUserIDsWithPermission(gomock.Any(), model.PermissionDecideMembership).Return([]int64{1}, nil)
+		dbMock.EXPECT().CreateOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, message *model.OutboxMessage) (*model.OutboxMessage, error) {
+			assert.Equal(t, int64(1), message.ChatID)
+			assert.Contains(t, message.Payload, "inline_keyboard")
+			return message, nil
+		})
+		b.sendMembershipPoll(user, &model.Form{Model: gorm.Model{ID: 3}}, 5)
+	})
+}
@@ -0,0 +1,192 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const membershipReconcileInterval = 24 * time.Hour
+
+// MembershipChange is a user whose status didn't match the group. From equals To if
+// the status is left as is, e.g. a banned user who is still in the group.
+type MembershipChange struct {
+	User *model.User
+	From string
+	To   string
+}
+
+// MembershipReport is the result of one reconciliation run.
+type MembershipReport struct {
+	Checked int
+	Failed  int
+	Changes []MembershipChange
+}
+
+// isGroupMember tells whether the member is in the group at the moment.
+func isGroupMember(member tgbotapi.ChatMember) bool {
+	switch member.Status {
+	case "creator", "administrator", "member":
+		return true
+	case "restricted":
+		return member.IsMember
+	default:
+		return false
+	}
+}
+
+// reconciledStatus returns the status the user must have according to the group.
+func reconciledStatus(status string, inGroup bool) string {
+	switch {
+	case status == model.UserStatusBot, status == model.UserStatusBanned:
+		// Bots are never changed, and only /unban lifts a ban
+		return status
+	case inGroup:
+		return model.UserStatusActive
+	case status == model.UserStatusActive:
+		return model.UserStatusNotActive
+	default:
+		return status
+	}
+}
+
+// startReconcilingMembership runs the reconciliation every membershipReconcileInterval
+// and on demand. Runs never overlap, a request during a run waits for the next one.
+func (b *botManager) startReconcilingMembership() {
+	ticker := time.NewTicker(membershipReconcileInterval)
+	defer ticker.Stop()
+	for {
+		var requestedBy int64
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+		case requestedBy = <-b.reconcileRequests:
+		}
+		b.runJob("reconcileMembership", func() {
+			report := b.reconcileMembership()
+			b.reportMembership(report, requestedBy)
+		})
+	}
+}
+
+// reconcileMembership checks every known user with getChatMember and fixes their status.
+func (b *botManager) reconcileMembership() *MembershipReport {
+	report := &MembershipReport{}
+	users, err := b.db.GetAllUsers(b.ctx)
+	if err != nil {
+		b.logger.Named("reconcileMembership").Error("Error while getting users", zap.Error(err))
+		return report
+	}
+	limiter := rate.NewLimiter(reconcileRateLimit, 1)
+	for _, user := range users {
+		if user.Status == model.UserStatusBot {
+			continue
+		}
+		member, err := b.getGroupMember(limiter, user.TelegramID)
+		if err != nil {
+			if b.ctx.Err() != nil {
+				return report
+			}
+			b.logger.Named("reconcileMembership").Info("Error while getting chat member", zap.Error(err), zap.Int64("telegram_id", user.TelegramID))
+			report.Failed++
+			continue
+		}
+		report.Checked++
+		inGroup := isGroupMember(member)
+		status := reconciledStatus(user.Status, inGroup)
+		if status == user.Status {
+			if user.Status == model.UserStatusBanned && inGroup {
+				report.Changes = append(report.Changes, MembershipChange{User: user, From: user.Status, To: user.Status})
+			}
+			continue
+		}
+		result, err := b.db.ReplaceUserStatus(b.ctx, user.ID, user.Status, status)
+		if err != nil {
+			b.logger.Named("reconcileMembership").Error("Error while updating user status", zap.Error(err), zap.Int64("telegram_id", user.TelegramID))
+			report.Failed++
+			continue
+		}
+		if result.RowsAffected == 0 {
+			// The status was changed by an update while the job was running
+			continue
+		}
+		b.logger.Named("reconcileMembership").Info("User status reconciled", zap.Int64("telegram_id", user.TelegramID), zap.String("from", user.Status), zap.String("to", status))
+		report.Changes = append(report.Changes, MembershipChange{User: user, From: user.Status, To: status})
+	}
+	b.logger.Named("reconcileMembership").Info("Membership reconciled", zap.Int("checked", report.Checked), zap.Int("failed", report.Failed), zap.Int("changes", len(report.Changes)))
+	return report
+}
+
+// getGroupMember requests the user's membership within the reconciliation's own limit
+// and the global limit shared with the sender. A user Telegram doesn't know is not a member.
+func (b *botManager) getGroupMember(limiter *rate.Limiter, telegramID int64) (tgbotapi.ChatMember, error) {
+	for {
+		err := limiter.Wait(b.ctx)
+		if err != nil {
+			return tgbotapi.ChatMember{}, err
+		}
+		err = b.scheduler.wait(b.ctx)
+		if err != nil {
+			return tgbotapi.ChatMember{}, err
+		}
+		member, err := b.bot.GetChatMember(tgbotapi.GetChatMemberConfig{
+			ChatConfigWithUser: tgbotapi.ChatConfigWithUser{
+				ChatID: b.groupID,
+				UserID: telegramID,
+			},
+		})
+		var apiErr *tgbotapi.Error
+		if !errors.As(err, &apiErr) {
+			return member, err
+		}
+		switch {
+		case apiErr.Code == http.StatusTooManyRequests:
+			retryAfter, _ := classifySendError(err)
+			b.logger.Named("getGroupMember").Info("Too many requests, waiting", zap.Duration("retry_after", retryAfter))
+			select {
+			case <-b.ctx.Done():
+				return member, b.ctx.Err()
+			case <-time.After(retryAfter):
+			}
+		case apiErr.Code == http.StatusBadRequest && strings.Contains(strings.ToLower(apiErr.Message), "user not found"):
+			return tgbotapi.ChatMember{Status: "left"}, nil
+		default:
+			return member, err
+		}
+	}
+}
+
+// reportMembership sends the report to the user who asked for it, or to the staff if
+// a scheduled run found something.
+func (b *botManager) reportMembership(report *MembershipReport, requestedBy int64) {
+	if requestedBy != 0 {
+		msg := tgbotapi.NewMessage(requestedBy, b.templator.MembershipReconciled(report))
+		msg.ParseMode = tgbotapi.ModeHTML
+		b.send(msg)
+		return
+	}
+	if len(report.Changes) == 0 && report.Failed == 0 {
+		return
+	}
+	b.notifyStaff(model.PermissionDecideMembership, func(chatID int64) tgbotapi.Chattable {
+		msg := tgbotapi.NewMessage(chatID, b.templator.MembershipReconciled(report))
+		msg.ParseMode = tgbotapi.ModeHTML
+		return msg
+	})
+}
+
+func (b *botManager) processReconcileCommand(message *tgbotapi.Message, _ commandArgs) {
+	b.logger.Named("processReconcileCommand").Debug("Processing reconcile command")
+	select {
+	case b.reconcileRequests <- message.Chat.ID:
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.ReconcileStarted()))
+	default:
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.ReconcileAlreadyRequested()))
+	}
+}
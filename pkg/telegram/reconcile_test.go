@@ -0,0 +1,36 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_reconciledStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		inGroup bool
+		want    string
+	}{
+		{name: "Silent member", status: model.UserStatusNotActive, inGroup: true, want: model.UserStatusActive},
+		{name: "Accepted and joined", status: model.UserStatusAccepted, inGroup: true, want: model.UserStatusActive},
+		{name: "Left", status: model.UserStatusActive, inGroup: false, want: model.UserStatusNotActive},
+		{name: "Accepted, not joined yet", status: model.UserStatusAccepted, inGroup: false, want: model.UserStatusAccepted},
+		{name: "Banned in group", status: model.UserStatusBanned, inGroup: true, want: model.UserStatusBanned},
+		{name: "Bot", status: model.UserStatusBot, inGroup: true, want: model.UserStatusBot},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, reconciledStatus(tt.status, tt.inGroup))
+		})
+	}
+}
+
+func Test_isGroupMember(t *testing.T) {
+	assert.True(t, isGroupMember(tgbotapi.ChatMember{Status: "administrator"}))
+	assert.True(t, isGroupMember(tgbotapi.ChatMember{Status: "restricted", IsMember: true}))
+	assert.False(t, isGroupMember(tgbotapi.ChatMember{Status: "restricted"}))
+	assert.False(t, isGroupMember(tgbotapi.ChatMember{Status: "kicked"}))
+}
@@ -0,0 +1,193 @@
+package telegram
+
+import (
+	"beneburg/pkg/callback"
+	"beneburg/pkg/database/model"
+	"errors"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// rejectionReasonPresets are offered under the form, callbacks refer to them by index,
+// so new presets go to the end.
+var rejectionReasonPresets = []string{
+	"Анкета заполнена не полностью",
+	"Слишком мало о себе",
+	"Не указана причина, почему хочешь к нам",
+	"Не подходишь под правила сообщества",
+}
+
+// FormReviewKeyboard is the keyboard of the message a reviewer gets about a new form.
+func FormReviewKeyboard(callbacks *callback.Codec, formID uint) tgbotapi.InlineKeyboardMarkup {
+	acceptButton := tgbotapi.NewInlineKeyboardButtonData("Принять", callbacks.MustEncode(actionFormAccept, formID))
+	rejectButton := tgbotapi.NewInlineKeyboardButtonData("Отклонить", callbacks.MustEncode(actionFormReject, formID))
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(acceptButton, rejectButton))
+}
+
+func (b *botManager) rejectionReasonsKeyboard(formID uint) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, preset := range rejectionReasonPresets {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(preset, b.callbacks.MustEncode(actionFormReason, formID, i))))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Отмена", b.callbacks.MustEncode(actionFormCancel, formID))))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// rejectionPrompt is the pending question about the rejection reason in a reviewer's chat.
+// It's kept in the bot state, one per chat, so a reply can be matched after a restart.
+type rejectionPrompt struct {
+	formID          uint
+	formMessageID   int
+	promptMessageID int
+}
+
+func rejectionPromptKey(chatID int64) string {
+	return fmt.Sprintf("rejection_prompt:%d", chatID)
+}
+
+func (p rejectionPrompt) String() string {
+	return fmt.Sprintf("%d:%d:%d", p.formID, p.formMessageID, p.promptMessageID)
+}
+
+func parseRejectionPrompt(value string) (rejectionPrompt, error) {
+	var prompt rejectionPrompt
+	_, err := fmt.Sscanf(value, "%d:%d:%d", &prompt.formID, &prompt.formMessageID, &prompt.promptMessageID)
+	return prompt, err
+}
+
+// promptRejectionReason replaces the form's buttons with the preset reasons and asks
+// the reviewer to reply with a reason of their own. It returns the answer for the reviewer.
+func (b *botManager) promptRejectionReason(chatID int64, messageID int, formID uint) callbackAnswer {
+	form, err := b.db.GetFormByID(b.ctx, formID)
+	if err != nil {
+		b.logger.Named("promptRejectionReason").Error("Error while getting form", zap.Error(err))
+		return b.callbackFailed()
+	}
+	if form.Status != model.FormStatusNew {
+		b.logger.Named("promptRejectionReason").Info("Form is already reviewed", zap.Uint("formID", formID), zap.String("status", form.Status))
+		return b.refuseFormDecision(chatID, messageID, formID)
+	}
+
+	b.send(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, b.rejectionReasonsKeyboard(formID)))
+	msg := tgbotapi.NewMessage(chatID, b.templator.RejectionReasonPrompt())
+	msg.ReplyToMessageID = messageID
+	msg.ReplyMarkup = tgbotapi.ForceReply{
+		ForceReply:            true,
+		InputFieldPlaceholder: "Причина отказа",
+	}
+	// The prompt's ID is needed to recognize the reply, so the prompt doesn't go through the outbox
+	sentPrompt, err := b.sendNow(msg)
+	if err != nil {
+		b.logger.Named("promptRejectionReason").Error("Error while sending prompt", zap.Error(err))
+		return b.callbackFailed()
+	}
+	prompt := rejectionPrompt{
+		formID:          formID,
+		formMessageID:   messageID,
+		promptMessageID: sentPrompt.MessageID,
+	}
+	err = b.db.SetBotState(b.ctx, rejectionPromptKey(chatID), prompt.String())
+	if err != nil {
+		b.logger.Named("promptRejectionReason").Error("Error while saving prompt", zap.Error(err))
+		return b.callbackFailed()
+	}
+	return callbackAnswer{}
+}
+
+// processRejectionReason handles a reply to the rejection prompt. It returns false if
+// the message is not such a reply.
+func (b *botManager) processRejectionReason(message *tgbotapi.Message) bool {
+	state, err := b.db.GetBotState(b.ctx, rejectionPromptKey(message.Chat.ID))
+	if err != nil {
+		if !errors.Is(err, noRecordError) {
+			b.logger.Named("processRejectionReason").Error("Error while getting prompt", zap.Error(err))
+		}
+		return false
+	}
+	prompt, err := parseRejectionPrompt(state.Value)
+	if err != nil {
+		b.logger.Named("processRejectionReason").Error("Error while parsing prompt", zap.Error(err), zap.String("value", state.Value))
+		return false
+	}
+	if message.ReplyToMessage.MessageID != prompt.promptMessageID {
+		return false
+	}
+	if !b.hasPermission(message.From, model.PermissionReviewForms) {
+		b.logger.Named("processRejectionReason").Info("User is not allowed to review forms", zap.Int64("chat_id", message.Chat.ID))
+		return true
+	}
+	reason := strings.TrimSpace(message.Text)
+	if reason == "" {
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.RejectionReasonPrompt()))
+		return true
+	}
+	answer := b.rejectForm(message.Chat.ID, prompt.formMessageID, prompt.formID, reason, message.From.ID)
+	b.send(tgbotapi.NewMessage(message.Chat.ID, answer.text))
+	return true
+}
+
+// cancelRejection brings back the accept and reject buttons.
+func (b *botManager) cancelRejection(chatID int64, messageID int, formID uint) callbackAnswer {
+	b.forgetRejectionPrompt(chatID, formID)
+	b.send(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, FormReviewKeyboard(b.callbacks, formID)))
+	return b.callbackDone()
+}
+
+// rejectForm returns the answer for the reviewer.
+func (b *botManager) rejectForm(chatID int64, messageID int, formID uint, reason string, decidedBy int64) callbackAnswer {
+	b.forgetRejectionPrompt(chatID, formID)
+	form, err := b.db.GetFormByID(b.ctx, formID)
+	if err != nil {
+		b.logger.Named("rejectForm").Error("Error while getting form", zap.Error(err))
+		return b.callbackFailed()
+	}
+	user, err := b.db.GetUserByTelegramID(b.ctx, form.UserTelegramId)
+	if err != nil {
+		b.logger.Named("rejectForm").Error("Error while getting user", zap.Error(err))
+		return b.callbackFailed()
+	}
+	result, err := b.db.RejectForm(b.ctx, formID, reason, decidedBy)
+	if err != nil {
+		b.logger.Named("rejectForm").Error("Error while rejecting form", zap.Error(err))
+		return b.callbackFailed()
+	}
+	if result.RowsAffected == 0 {
+		// Another reviewer was first or the update is processed again
+		b.logger.Named("rejectForm").Info("Form is already reviewed", zap.Uint("formID", formID), zap.String("status", form.Status))
+		return b.refuseFormDecision(chatID, messageID, formID)
+	}
+
+	rejectMsg := tgbotapi.NewMessage(user.TelegramID, b.templator.RejectFormReply(user.Status, reason))
+	rejectMsg.ParseMode = tgbotapi.ModeHTML
+	b.send(rejectMsg)
+	now := time.Now()
+	form.Status = model.FormStatusRejected
+	form.RejectionReason = &reason
+	form.DecidedBy = &decidedBy
+	form.DecidedAt = &now
+	b.stampFormReview(chatID, messageID, user, form)
+	return callbackAnswer{text: b.templator.FormRejected(reason)}
+}
+
+// forgetRejectionPrompt deletes the chat's prompt if it's about the form.
+func (b *botManager) forgetRejectionPrompt(chatID int64, formID uint) {
+	key := rejectionPromptKey(chatID)
+	state, err := b.db.GetBotState(b.ctx, key)
+	if err != nil {
+		if !errors.Is(err, noRecordError) {
+			b.logger.Named("forgetRejectionPrompt").Error("Error while getting prompt", zap.Error(err))
+		}
+		return
+	}
+	prompt, err := parseRejectionPrompt(state.Value)
+	if err == nil && prompt.formID != formID {
+		return
+	}
+	err = b.db.DeleteBotState(b.ctx, key)
+	if err != nil {
+		b.logger.Named("forgetRejectionPrompt").Error("Error while deleting prompt", zap.Error(err), zap.String("key", key))
+	}
+}
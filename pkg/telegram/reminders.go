@@ -0,0 +1,134 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	reminderCheckInterval = time.Hour
+	// reviewDigestSentKey keeps the time of the last review digest, so a restart doesn't repeat it.
+	reviewDigestSentKey = "review_digest_sent_at"
+	// applyRemindersSinceKey keeps the time reminders to apply were first checked. Only users
+	// who came after it are reminded, so the users who never applied before aren't all messaged at once.
+	applyRemindersSinceKey = "apply_reminders_since"
+	// reviewDigestMaxForms is the number of forms resent with review buttons in one digest.
+	reviewDigestMaxForms = 10
+)
+
+// ReminderRules tell when reviewers are reminded of pending forms and new users of the form.
+type ReminderRules struct {
+	// ReviewAfter is the time a form waits for review before it gets into the digest.
+	ReviewAfter time.Duration
+	// ReviewDigestInterval is the minimal time between two review digests.
+	ReviewDigestInterval time.Duration
+	// ApplyAfter is the time after joining, and then between reminders, a new user without
+	// a form waits for the next reminder.
+	ApplyAfter time.Duration
+	// ApplyMaxReminders caps the reminders sent to a user, 0 turns them off.
+	ApplyMaxReminders int
+}
+
+var DefaultReminderRules = ReminderRules{
+	ReviewAfter:          24 * time.Hour,
+	ReviewDigestInterval: 24 * time.Hour,
+	ApplyAfter:           3 * 24 * time.Hour,
+	ApplyMaxReminders:    2,
+}
+
+func (b *botManager) SetReminderRules(rules ReminderRules) {
+	b.reminders = rules
+}
+
+// remindPendingReviews sends the reviewers the forms waiting for review longer than
+// ReviewAfter, each with the review buttons.
+func (b *botManager) remindPendingReviews() {
+	now := time.Now()
+	forms, err := b.db.GetPendingForms(b.ctx, now.Add(-b.reminders.ReviewAfter))
+	if err != nil {
+		b.logger.Named("remindPendingReviews").Error("Error while getting pending forms", zap.Error(err))
+		return
+	}
+	// Checks without pending forms don't count, the digest is sent as soon as there are some
+	if len(forms) == 0 || !b.claimPeriod(reviewDigestSentKey, now, b.reminders.ReviewDigestInterval) {
+		return
+	}
+	b.logger.Named("remindPendingReviews").Info("Sending review digest", zap.Int("count", len(forms)))
+	shown := forms
+	if len(shown) > reviewDigestMaxForms {
+		shown = shown[:reviewDigestMaxForms]
+	}
+	b.notifyStaff(model.PermissionReviewForms, func(chatID int64) tgbotapi.Chattable {
+		msg := tgbotapi.NewMessage(chatID, b.templator.ReviewDigest(forms, len(shown)))
+		msg.ParseMode = tgbotapi.ModeHTML
+		return msg
+	})
+	for _, form := range shown {
+		form := form
+		b.notifyStaff(model.PermissionReviewForms, func(chatID int64) tgbotapi.Chattable {
+			msg := tgbotapi.NewMessage(chatID, b.templator.NewFormReview(&form.User, form))
+			msg.ParseMode = tgbotapi.ModeHTML
+			msg.ReplyMarkup = FormReviewKeyboard(b.callbacks, form.ID)
+			return msg
+		})
+	}
+}
+
+// applyRemindersSince returns the time reminders to apply were first checked, saving now on
+// the first check. It returns false if the time can't be read or saved.
+func (b *botManager) applyRemindersSince(now time.Time) (time.Time, bool) {
+	state, err := b.db.GetBotState(b.ctx, applyRemindersSinceKey)
+	if err != nil && !errors.Is(err, noRecordError) {
+		b.logger.Named("applyRemindersSince").Error("Error while getting reminders start", zap.Error(err))
+		return time.Time{}, false
+	}
+	if state != nil {
+		since, err := time.Parse(time.RFC3339, state.Value)
+		if err == nil {
+			return since, true
+		}
+		b.logger.Named("applyRemindersSince").Warn("Invalid reminders start, starting now", zap.String("value", state.Value), zap.Error(err))
+	}
+	err = b.db.SetBotState(b.ctx, applyRemindersSinceKey, now.Format(time.RFC3339))
+	if err != nil {
+		b.logger.Named("applyRemindersSince").Error("Error while saving reminders start", zap.Error(err))
+		return time.Time{}, false
+	}
+	return now, true
+}
+
+// remindToApply nudges new users who haven't submitted a form, at most ApplyMaxReminders
+// times each.
+func (b *botManager) remindToApply() {
+	if b.reminders.ApplyMaxReminders <= 0 {
+		return
+	}
+	now := time.Now()
+	start, ok := b.applyRemindersSince(now)
+	if !ok {
+		return
+	}
+	since := now.Add(-b.reminders.ApplyAfter)
+	users, err := b.db.GetUsersToRemind(b.ctx, start, since, since, b.reminders.ApplyMaxReminders)
+	if err != nil {
+		b.logger.Named("remindToApply").Error("Error while getting users to remind", zap.Error(err))
+		return
+	}
+	for _, user := range users {
+		// The reminder is counted first, so it's not repeated if another run got the user too
+		result, err := b.db.MarkUserReminded(b.ctx, user.TelegramID, user.ApplyReminders)
+		if err != nil {
+			b.logger.Named("remindToApply").Error("Error while counting reminder", zap.Error(err), zap.Int64("telegram_id", user.TelegramID))
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		last := int(user.ApplyReminders)+1 == b.reminders.ApplyMaxReminders
+		b.logger.Named("remindToApply").Info("Reminding to apply", zap.Int64("telegram_id", user.TelegramID), zap.Int32("reminders", user.ApplyReminders+1))
+		b.send(tgbotapi.NewMessage(user.TelegramID, b.templator.ApplyReminder(last)))
+	}
+}
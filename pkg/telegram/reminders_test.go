@@ -0,0 +1,66 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gen"
+	"testing"
+	"time"
+)
+
+func Test_remindPendingReviews(t *testing.T) {
+	t.Run("No pending forms don't delay the next digest", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetPendingForms(gomock.Any(), gomock.Any()).Return(nil, nil)
+		b.remindPendingReviews()
+	})
+	t.Run("Digest sent recently is not repeated", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetPendingForms(gomock.Any(), gomock.Any()).Return([]*model.Form{{}}, nil)
+		state := &model.BotState{Value: time.Now().Add(-time.Hour).Format(time.RFC3339)}
+		dbMock.EXPECT().GetBotState(gomock.Any(), reviewDigestSentKey).Return(state, nil)
+		b.remindPendingReviews()
+	})
+}
+
+func Test_remindToApply(t *testing.T) {
+
+	t.Run("Reminds users counted by this run only", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		users := []*model.User{{TelegramID: 10}, {TelegramID: 11, ApplyReminders: 1}}
+		start := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+		dbMock.EXPECT().GetBotState(gomock.Any(), applyRemindersSinceKey).Return(&model.BotState{Key: applyRemindersSinceKey, Value: start.Format(time.RFC3339)}, nil)
+		dbMock.EXPECT().GetUsersToRemind(gomock.Any(), start, gomock.Any(), gomock.Any(), DefaultReminderRules.ApplyMaxReminders).Return(users, nil)
+		dbMock.EXPECT().MarkUserReminded(gomock.Any(), int64(10), int32(0)).Return(&gen.ResultInfo{RowsAffected: 0}, nil)
+		dbMock.EXPECT().MarkUserReminded(gomock.Any(), int64(11), int32(1)).Return(&gen.ResultInfo{RowsAffected: 1}, nil)
+		dbMock.EXPECT().CreateOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, message *model.OutboxMessage) (*model.OutboxMessage, error) {
+			assert.Equal(t, int64(11), message.ChatID)
+			return message, nil
+		})
+		b.remindToApply()
+	})
+	t.Run("First run skips users who came before", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		var saved string
+		dbMock.EXPECT().GetBotState(gomock.Any(), applyRemindersSinceKey).Return(nil, noRecordError)
+		dbMock.EXPECT().SetBotState(gomock.Any(), applyRemindersSinceKey, gomock.Any()).DoAndReturn(func(_ context.Context, _ string, value string) error {
+			saved = value
+			return nil
+		})
+		dbMock.EXPECT().GetUsersToRemind(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), DefaultReminderRules.ApplyMaxReminders).DoAndReturn(func(_ context.Context, createdAfter time.Time, createdBefore time.Time, _ time.Time, _ int) ([]*model.User, error) {
+			assert.Equal(t, saved, createdAfter.Format(time.RFC3339))
+			assert.True(t, createdBefore.Before(createdAfter))
+			return nil, nil
+		})
+		b.remindToApply()
+	})
+	t.Run("Turned off", func(t *testing.T) {
+		rules := DefaultReminderRules
+		rules.ApplyMaxReminders = 0
+		b, _ := newTestBot(t)
+		b.reminders = rules
+		b.remindToApply()
+	})
+}
@@ -0,0 +1,118 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+)
+
+// bootstrapOwner makes adminID the first owner, so the roles can be managed from the bot.
+func (b *botManager) bootstrapOwner() {
+	created, err := b.db.EnsureOwner(b.ctx, b.adminID)
+	if err != nil {
+		b.logger.Named("bootstrapOwner").Error("Error while ensuring owner", zap.Error(err))
+		return
+	}
+	if created {
+		b.logger.Named("bootstrapOwner").Info("Owner created", zap.Int64("telegram_id", b.adminID))
+	}
+}
+
+func (b *botManager) userRole(telegramID int64) string {
+	role, err := b.db.GetUserRole(b.ctx, telegramID)
+	if err != nil {
+		b.logger.Named("userRole").Error("Error while getting user role", zap.Error(err), zap.Int64("telegram_id", telegramID))
+		return model.RoleMember
+	}
+	return role
+}
+
+func (b *botManager) hasPermission(from *tgbotapi.User, permission string) bool {
+	if from == nil {
+		return false
+	}
+	return model.HasPermission(b.userRole(from.ID), permission)
+}
+
+// notifyStaff sends a message to every user whose role has the permission.
+func (b *botManager) notifyStaff(permission string, newMessage func(chatID int64) tgbotapi.Chattable) {
+	ids, err := b.db.GetUserIDsWithPermission(b.ctx, permission)
+	if err != nil {
+		b.logger.Named("notifyStaff").Error("Error while getting staff", zap.Error(err), zap.String("permission", permission))
+		return
+	}
+	for _, id := range ids {
+		b.send(newMessage(id))
+	}
+}
+
+// findUser looks a user up by "@username" or Telegram ID.
+func (b *botManager) findUser(ref string) (*model.User, error) {
+	if strings.HasPrefix(ref, "@") {
+		return b.db.GetUserByUsername(b.ctx, strings.TrimPrefix(ref, "@"))
+	}
+	telegramID, err := strconv.ParseInt(ref, 10, 64)
+	if err != nil {
+		return nil, noRecordError
+	}
+	return b.db.GetUserByTelegramID(b.ctx, telegramID)
+}
+
+func (b *botManager) processGrantCommand(message *tgbotapi.Message, args commandArgs) {
+	b.logger.Named("processGrantCommand").Debug("Processing grant command")
+	role := args["role"]
+	if !model.IsValidRole(role) {
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.UnknownRole(model.Roles)))
+		return
+	}
+	b.changeRole(message, args["@username|id"], role)
+}
+
+func (b *botManager) processRevokeCommand(message *tgbotapi.Message, args commandArgs) {
+	b.logger.Named("processRevokeCommand").Debug("Processing revoke command")
+	b.changeRole(message, args["@username|id"], model.RoleMember)
+}
+
+func (b *botManager) changeRole(message *tgbotapi.Message, ref string, role string) {
+	user, err := b.findUser(ref)
+	if err != nil {
+		if errors.Is(err, noRecordError) {
+			b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.InfoCommandNoUser()))
+			return
+		}
+		b.logger.Named("changeRole").Error("Error while getting user", zap.Error(err))
+		return
+	}
+	granterRole := b.userRole(message.From.ID)
+	currentRole := b.userRole(user.TelegramID)
+	if user.TelegramID == message.From.ID || !model.CanManageRole(granterRole, role) || !model.CanManageRole(granterRole, currentRole) {
+		b.logger.Named("changeRole").Info("Role change is not allowed", zap.Int64("telegram_id", user.TelegramID), zap.String("role", role))
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.CommandNotAllowed()))
+		return
+	}
+	err = b.db.SetUserRole(b.ctx, user.TelegramID, role, message.From.ID)
+	if err != nil {
+		b.logger.Named("changeRole").Error("Error while setting user role", zap.Error(err))
+		return
+	}
+	b.logger.Named("changeRole").Info("Role changed", zap.Int64("telegram_id", user.TelegramID), zap.String("role", role), zap.Int64("granted_by", message.From.ID))
+	b.syncUserCommands(user.TelegramID, role)
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.templator.RoleChanged(user, role))
+	msg.ParseMode = tgbotapi.ModeHTML
+	b.send(msg)
+}
+
+func (b *botManager) processRolesCommand(message *tgbotapi.Message, _ commandArgs) {
+	b.logger.Named("processRolesCommand").Debug("Processing roles command")
+	roles, err := b.db.GetRoles(b.ctx)
+	if err != nil {
+		b.logger.Named("processRolesCommand").Error("Error while getting roles", zap.Error(err))
+		return
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.templator.RolesList(roles))
+	msg.ParseMode = tgbotapi.ModeHTML
+	b.send(msg)
+}
@@ -0,0 +1,351 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+const (
+	outboxBatchSize      = 30
+	outboxPollInterval   = time.Second
+	outboxLease          = time.Minute
+	outboxMaxAttempts    = 10
+	outboxBaseBackoff    = 2 * time.Second
+	outboxMaxBackoff     = time.Hour
+	outboxSentRetention  = 7 * 24 * time.Hour
+	outboxCleanupEvery   = time.Hour
+	tooManyRequestsDelay = time.Second
+	// sendNowMaxAttempts caps the requests of sendNow when Telegram asks to wait.
+	sendNowMaxAttempts = 3
+)
+
+// outgoingMessage is a request waiting for the sender.
+type outgoingMessage struct {
+	message tgbotapi.Chattable
+	// outbox is the stored copy of the message, nil if the message is kept only in memory.
+	outbox *model.OutboxMessage
+}
+
+// outboxTypes are the requests that are stored in the outbox before sending. Other requests,
+// such as callback query answers, make no sense after a restart and are kept only in memory.
+var outboxTypes = map[string]reflect.Type{}
+
+func registerOutboxTypes(prototypes ...tgbotapi.Chattable) {
+	for _, prototype := range prototypes {
+		t := reflect.TypeOf(prototype)
+		outboxTypes[t.Name()] = t
+	}
+}
+
+func init() {
+	registerOutboxTypes(
+		tgbotapi.MessageConfig{},
+		tgbotapi.EditMessageTextConfig{},
+		tgbotapi.EditMessageReplyMarkupConfig{},
+		tgbotapi.StopPollConfig{},
+		tgbotapi.ApproveChatJoinRequestConfig{},
+		tgbotapi.DeclineChatJoinRequest{},
+		tgbotapi.BanChatMemberConfig{},
+		tgbotapi.UnbanChatMemberConfig{},
+		tgbotapi.RevokeChatInviteLinkConfig{},
+	)
+}
+
+// encodeOutboxMessage returns nil if the message type is not stored in the outbox.
+func encodeOutboxMessage(message tgbotapi.Chattable) (*model.OutboxMessage, error) {
+	t := reflect.TypeOf(message)
+	if _, ok := outboxTypes[t.Name()]; !ok || t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	return &model.OutboxMessage{
+		ChatID:        chatIDOf(message),
+		Type:          t.Name(),
+		Payload:       string(payload),
+		Status:        model.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}, nil
+}
+
+func decodeOutboxMessage(outbox *model.OutboxMessage) (tgbotapi.Chattable, error) {
+	t, ok := outboxTypes[outbox.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown outbox message type %q", outbox.Type)
+	}
+	message := reflect.New(t)
+	err := json.Unmarshal([]byte(outbox.Payload), message.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return message.Elem().Interface().(tgbotapi.Chattable), nil
+}
+
+// chatIDOf returns the chat the request is addressed to, 0 if the request has no chat.
+func chatIDOf(message tgbotapi.Chattable) int64 {
+	v := reflect.ValueOf(message)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return 0
+	}
+	chatID := v.FieldByName("ChatID")
+	if !chatID.IsValid() || chatID.Kind() != reflect.Int64 {
+		return 0
+	}
+	return chatID.Int()
+}
+
+func (b *botManager) send(message tgbotapi.Chattable) {
+	outbox, err := encodeOutboxMessage(message)
+	if err == nil && outbox != nil {
+		_, err = b.db.CreateOutboxMessage(b.ctx, outbox)
+	}
+	if err != nil {
+		// Not an error level, errors are sent to the admin through this function
+		b.logger.Named("send").Info("Error while storing message in outbox, sending from memory", zap.Error(err))
+	}
+	if err != nil || outbox == nil {
+		b.enqueue(outgoingMessage{message: message})
+		return
+	}
+	select {
+	case b.outboxWakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (b *botManager) enqueue(message outgoingMessage) {
+	select {
+	case b.messagesChan <- message:
+	case <-b.ctx.Done():
+	}
+}
+
+// startProcessingOutbox feeds due outbox messages to the sender.
+func (b *botManager) startProcessingOutbox() {
+	defer close(b.outboxDone)
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+	lastCleanup := time.Time{}
+	for stopping := false; !stopping; {
+		select {
+		case <-b.stopOutbox:
+			// Claim the messages that are due once more, so they are flushed before stopping
+			stopping = true
+		case <-ticker.C:
+		case <-b.outboxWakeup:
+		}
+		messages, err := b.db.ClaimDueOutboxMessages(b.ctx, outboxBatchSize, time.Now().Add(outboxLease))
+		if err != nil {
+			b.logger.Named("startProcessingOutbox").Error("Error while claiming outbox messages", zap.Error(err))
+			continue
+		}
+		for _, outbox := range messages {
+			message, err := decodeOutboxMessage(outbox)
+			if err != nil {
+				b.logger.Named("startProcessingOutbox").Error("Error while decoding outbox message", zap.Error(err), zap.Uint("outbox_id", outbox.ID))
+				b.markOutboxMessageDead(outbox, err)
+				continue
+			}
+			b.enqueue(outgoingMessage{message: message, outbox: outbox})
+		}
+		if time.Since(lastCleanup) > outboxCleanupEvery {
+			lastCleanup = time.Now()
+			result, err := b.db.DeleteSentOutboxMessages(b.ctx, time.Now().Add(-outboxSentRetention))
+			if err != nil {
+				b.logger.Named("startProcessingOutbox").Error("Error while deleting sent outbox messages", zap.Error(err))
+				continue
+			}
+			b.logger.Named("startProcessingOutbox").Debug("Sent outbox messages deleted", zap.Int64("count", result.RowsAffected))
+		}
+	}
+}
+
+func (b *botManager) startProcessingMessages() {
+	defer close(b.sendingDone)
+	for {
+		select {
+		case <-b.stopSending:
+			// Flush the queue, messages delayed by limits stay in the outbox until restart
+			for {
+				select {
+				case message := <-b.messagesChan:
+					b.processOutgoingMessage(message)
+				default:
+					return
+				}
+			}
+		case message := <-b.messagesChan:
+			b.processOutgoingMessage(message)
+		}
+	}
+}
+
+func (b *botManager) processOutgoingMessage(message outgoingMessage) {
+	chatID := chatIDOf(message.message)
+	if delay, reason := b.scheduler.reserve(chatID); delay > 0 {
+		b.delay(message, delay, reason)
+		return
+	}
+	err := b.scheduler.wait(b.ctx)
+	if err != nil {
+		b.logger.Named("processOutgoingMessage").Info("Message is not sent", zap.Error(err))
+		return
+	}
+	_, err = b.bot.Request(message.message)
+	b.handleSendResult(message, chatID, err)
+}
+
+// sendNow sends the message right away for the callers that need the sent message, like the
+// poll whose ID is stored. It keeps the limits of the sender and waits out 429 Too Many Requests
+// up to sendNowMaxAttempts times, so it blocks the caller.
+func (b *botManager) sendNow(message tgbotapi.Chattable) (tgbotapi.Message, error) {
+	chatID := chatIDOf(message)
+	for attempt := 1; ; attempt++ {
+		for {
+			delay, _ := b.scheduler.reserve(chatID)
+			if delay == 0 {
+				break
+			}
+			select {
+			case <-b.ctx.Done():
+				return tgbotapi.Message{}, b.ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+		err := b.scheduler.wait(b.ctx)
+		if err != nil {
+			return tgbotapi.Message{}, err
+		}
+		sent, err := b.bot.Send(message)
+		retryAfter, _ := classifySendError(err)
+		if retryAfter == 0 || attempt >= sendNowMaxAttempts {
+			return sent, err
+		}
+		b.logger.Named("sendNow").Info("Too many requests, parking chat", zap.Int64("chat_id", chatID), zap.Duration("retry_after", retryAfter), zap.Int("attempt", attempt))
+		b.scheduler.park(chatID, retryAfter)
+	}
+}
+
+// delay puts the message aside without blocking messages to other chats.
+func (b *botManager) delay(message outgoingMessage, delay time.Duration, reason string) {
+	senderMetrics.Add("delayed_"+reason, 1)
+	description := delayReasonDescription(reason, delay)
+	if message.outbox == nil {
+		b.logger.Named("delay").Debug("Message delayed", zap.String("reason", description))
+		time.AfterFunc(delay, func() {
+			b.enqueue(message)
+		})
+		return
+	}
+	b.logger.Named("delay").Debug("Outbox message delayed", zap.Uint("outbox_id", message.outbox.ID), zap.String("reason", description))
+	err := b.db.DelayOutboxMessage(b.ctx, message.outbox.ID, time.Now().Add(delay), description)
+	if err != nil {
+		b.logger.Named("delay").Error("Error while delaying outbox message", zap.Error(err), zap.Uint("outbox_id", message.outbox.ID))
+	}
+}
+
+func (b *botManager) handleSendResult(message outgoingMessage, chatID int64, err error) {
+	if err == nil {
+		senderMetrics.Add("sent", 1)
+		if message.outbox != nil {
+			err = b.db.MarkOutboxMessageSent(b.ctx, message.outbox.ID)
+			if err != nil {
+				b.logger.Named("handleSendResult").Error("Error while marking outbox message sent", zap.Error(err), zap.Uint("outbox_id", message.outbox.ID))
+			}
+		}
+		return
+	}
+
+	retryAfter, permanent := classifySendError(err)
+	if retryAfter > 0 {
+		b.logger.Named("handleSendResult").Info("Too many requests, parking chat", zap.Int64("chat_id", chatID), zap.Duration("retry_after", retryAfter))
+		b.scheduler.park(chatID, retryAfter)
+		b.delay(message, retryAfter, delayReasonRetryAfter)
+		return
+	}
+	senderMetrics.Add("failed", 1)
+	if message.outbox == nil {
+		b.logSendFailure(chatID, zapcore.ErrorLevel, "Error while sending message", zap.Error(err), zap.Any("message", message.message))
+		return
+	}
+
+	attempts := message.outbox.Attempts + 1
+	if permanent || attempts >= outboxMaxAttempts {
+		b.logSendFailure(message.outbox.ChatID, zapcore.WarnLevel, "Outbox message is dead", zap.Error(err), zap.Uint("outbox_id", message.outbox.ID), zap.Int64("chat_id", message.outbox.ChatID), zap.Int("attempts", attempts))
+		message.outbox.Attempts = attempts
+		b.markOutboxMessageDead(message.outbox, err)
+		return
+	}
+	backoff := outboxBackoff(attempts)
+	b.logger.Named("handleSendResult").Info("Error while sending outbox message, retrying", zap.Error(err), zap.Uint("outbox_id", message.outbox.ID), zap.Int("attempts", attempts), zap.Duration("backoff", backoff))
+	err = b.db.RescheduleOutboxMessage(b.ctx, message.outbox.ID, attempts, time.Now().Add(backoff), err.Error())
+	if err != nil {
+		b.logger.Named("handleSendResult").Error("Error while rescheduling outbox message", zap.Error(err), zap.Uint("outbox_id", message.outbox.ID))
+	}
+}
+
+// logSendFailure logs a message that couldn't be sent. Failures of messages to the admin
+// are logged at info level: warnings are sent to the admin, so a bot blocked by the admin
+// would report its own reports forever.
+func (b *botManager) logSendFailure(chatID int64, level zapcore.Level, msg string, fields ...zap.Field) {
+	if chatID == b.adminID {
+		level = zapcore.InfoLevel
+	}
+	if entry := b.logger.Named("handleSendResult").Check(level, msg); entry != nil {
+		entry.Write(fields...)
+	}
+}
+
+func (b *botManager) markOutboxMessageDead(outbox *model.OutboxMessage, cause error) {
+	err := b.db.MarkOutboxMessageDead(b.ctx, outbox.ID, outbox.Attempts, cause.Error())
+	if err != nil {
+		b.logger.Named("markOutboxMessageDead").Error("Error while marking outbox message dead", zap.Error(err), zap.Uint("outbox_id", outbox.ID))
+	}
+}
+
+// classifySendError tells whether a failed request can never succeed, for example because
+// the user blocked the bot, and how long to wait before retrying when Telegram asks for it
+// with 429 Too Many Requests.
+func classifySendError(err error) (retryAfter time.Duration, permanent bool) {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		// Network errors and the like
+		return 0, false
+	}
+	switch {
+	case apiErr.Code == http.StatusTooManyRequests:
+		if apiErr.RetryAfter > 0 {
+			return time.Duration(apiErr.RetryAfter) * time.Second, false
+		}
+		return tooManyRequestsDelay, false
+	case apiErr.Code == http.StatusBadRequest, apiErr.Code == http.StatusUnauthorized, apiErr.Code == http.StatusForbidden, apiErr.Code == http.StatusNotFound:
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// outboxBackoff returns the exponential delay before the next attempt.
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,138 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"encoding/json"
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+	"time"
+)
+
+func Test_outbox(t *testing.T) {
+	t.Run("Encode and decode message", func(t *testing.T) {
+		msg := tgbotapi.NewMessage(10, "test")
+		msg.ParseMode = tgbotapi.ModeHTML
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("button", "data")))
+
+		outbox, err := encodeOutboxMessage(msg)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), outbox.ChatID)
+		assert.Equal(t, "MessageConfig", outbox.Type)
+
+		decoded, err := decodeOutboxMessage(outbox)
+		assert.NoError(t, err)
+		decodedMsg, ok := decoded.(tgbotapi.MessageConfig)
+		assert.True(t, ok)
+		assert.Equal(t, msg.Text, decodedMsg.Text)
+		assert.Equal(t, msg.ParseMode, decodedMsg.ParseMode)
+
+		// Reply markup is decoded as a map, but it must be sent to Telegram the same way
+		markup, err := json.Marshal(decodedMsg.ReplyMarkup)
+		assert.NoError(t, err)
+		expectedMarkup, err := json.Marshal(msg.ReplyMarkup)
+		assert.NoError(t, err)
+		assert.JSONEq(t, string(expectedMarkup), string(markup))
+	})
+	t.Run("Not stored types", func(t *testing.T) {
+		outbox, err := encodeOutboxMessage(tgbotapi.NewCallback("id", "text"))
+		assert.NoError(t, err)
+		assert.Nil(t, outbox)
+	})
+	t.Run("Classify errors", func(t *testing.T) {
+		_, permanent := classifySendError(&tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"})
+		assert.True(t, permanent)
+		retryAfter, permanent := classifySendError(&tgbotapi.Error{Code: 429})
+		assert.False(t, permanent)
+		assert.Equal(t, tooManyRequestsDelay, retryAfter)
+		retryAfter, _ = classifySendError(&tgbotapi.Error{Code: 429, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 17}})
+		assert.Equal(t, 17*time.Second, retryAfter)
+		_, permanent = classifySendError(errors.New("connection reset"))
+		assert.False(t, permanent)
+	})
+	t.Run("Backoff", func(t *testing.T) {
+		assert.Equal(t, outboxBaseBackoff, outboxBackoff(1))
+		assert.Equal(t, 4*outboxBaseBackoff, outboxBackoff(3))
+		assert.Equal(t, outboxMaxBackoff, outboxBackoff(100))
+	})
+}
+
+func Test_sendScheduler(t *testing.T) {
+	s := newSendScheduler()
+	t.Run("Private chat burst", func(t *testing.T) {
+		for i := 0; i < privateRateBurst; i++ {
+			delay, _ := s.reserve(10)
+			assert.Zero(t, delay)
+		}
+		delay, reason := s.reserve(10)
+		assert.Positive(t, delay)
+		assert.Equal(t, delayReasonChatRateLimit, reason)
+	})
+	t.Run("Parked chat doesn't affect others", func(t *testing.T) {
+		s.park(-20, time.Minute)
+		delay, reason := s.reserve(-20)
+		assert.Greater(t, delay, 50*time.Second)
+		assert.Equal(t, delayReasonChatParked, reason)
+		delay, _ = s.reserve(-30)
+		assert.Zero(t, delay)
+		assert.Contains(t, s.parkedChats(), "-20")
+	})
+	t.Run("Requests without chat", func(t *testing.T) {
+		delay, _ := s.reserve(0)
+		assert.Zero(t, delay)
+	})
+}
+
+func Test_sendNow(t *testing.T) {
+	t.Run("Too many requests are waited out", func(t *testing.T) {
+		b, _ := newTestBot(t)
+		b.scheduler = newSendScheduler()
+		attempts := 0
+		b.bot = fakeBotAPI{send: func(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+			attempts++
+			if attempts == 1 {
+				return tgbotapi.Message{}, &tgbotapi.Error{Code: 429, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 1}}
+			}
+			return tgbotapi.Message{MessageID: 7}, nil
+		}}
+		started := time.Now()
+		sent, err := b.sendNow(tgbotapi.NewMessage(-100, "test"))
+		assert.NoError(t, err)
+		assert.Equal(t, 7, sent.MessageID)
+		assert.Equal(t, 2, attempts)
+		assert.GreaterOrEqual(t, time.Since(started), time.Second)
+	})
+	t.Run("Other errors are returned at once", func(t *testing.T) {
+		b, _ := newTestBot(t)
+		b.scheduler = newSendScheduler()
+		attempts := 0
+		b.bot = fakeBotAPI{send: func(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+			attempts++
+			return tgbotapi.Message{}, &tgbotapi.Error{Code: 400, Message: "Bad Request: chat not found"}
+		}}
+		_, err := b.sendNow(tgbotapi.NewMessage(-100, "test"))
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func Test_handleSendResult(t *testing.T) {
+	t.Run("Dead message to the admin is not reported to the admin", func(t *testing.T) {
+		b, db := newTestBot(t)
+		b.adminID = 42
+		core, logs := observer.New(zapcore.InfoLevel)
+		b.logger = zap.New(core)
+		db.EXPECT().MarkOutboxMessageDead(gomock.Any(), uint(3), 1, gomock.Any()).Return(nil)
+		blocked := &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}
+		outbox := &model.OutboxMessage{ChatID: 42}
+		outbox.ID = 3
+		b.handleSendResult(outgoingMessage{outbox: outbox}, 42, blocked)
+		assert.Equal(t, 1, logs.Len())
+		assert.Equal(t, 0, logs.FilterLevelExact(zapcore.WarnLevel).Len())
+	})
+}
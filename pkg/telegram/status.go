@@ -0,0 +1,38 @@
+package telegram
+
+import (
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// processStatusCommand shows the user where their application stands.
+func (b *botManager) processStatusCommand(message *tgbotapi.Message, _ commandArgs) {
+	b.logger.Named("processStatusCommand").Debug("Processing status command")
+	if message.From == nil {
+		b.logger.Named("processStatusCommand").Error("Message's From is nil")
+		return
+	}
+	user, err := b.db.GetUserByTelegramID(b.ctx, message.From.ID)
+	if err != nil {
+		b.logger.Named("processStatusCommand").Error("Error while getting user", zap.Error(err))
+		return
+	}
+	forms, err := b.db.GetAllUserForms(b.ctx, user.TelegramID)
+	if err != nil {
+		b.logger.Named("processStatusCommand").Error("Error while getting forms", zap.Error(err))
+		return
+	}
+	poll, err := b.db.GetOpenMembershipPollByUser(b.ctx, user.TelegramID)
+	if err != nil && !errors.Is(err, noRecordError) {
+		b.logger.Named("processStatusCommand").Error("Error while getting poll", zap.Error(err))
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.templator.ApplicationStatus(user, forms, poll))
+	msg.ParseMode = tgbotapi.ModeHTML
+	// No token in the link: a new one would log the user out of the site and skip the ban check of /login
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonURL("Открыть профиль", b.templator.ProfileURL()),
+	))
+	b.send(msg)
+}
@@ -0,0 +1,79 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func Test_processStatusCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		forms    []*model.Form
+		poll     *model.MembershipPoll
+		nextStep string
+	}{
+		{
+			name:     "Status decides before the forms",
+			status:   model.UserStatusBanned,
+			forms:    []*model.Form{{Status: model.FormStatusNew}},
+			nextStep: "доступ закрыт",
+		},
+		{
+			name:     "No forms",
+			status:   model.UserStatusNew,
+			nextStep: "заполни анкету",
+		},
+		{
+			name:     "Newest form is reviewed",
+			status:   model.UserStatusNew,
+			forms:    []*model.Form{{Status: model.FormStatusNew}, {Status: model.FormStatusRejected}},
+			nextStep: "анкету проверяет администратор",
+		},
+		{
+			name:     "Newest form is rejected",
+			status:   model.UserStatusNew,
+			forms:    []*model.Form{{Status: model.FormStatusRejected}, {Status: model.FormStatusAccepted}},
+			nextStep: "исправь анкету",
+		},
+		{
+			name:     "Poll is running",
+			status:   model.UserStatusNew,
+			forms:    []*model.Form{{Status: model.FormStatusAccepted}},
+			poll:     &model.MembershipPoll{AcceptVotes: 2},
+			nextStep: "в чате идёт голосование",
+		},
+		{
+			name:     "Admins decide",
+			status:   model.UserStatusNew,
+			forms:    []*model.Form{{Status: model.FormStatusAccepted}},
+			nextStep: "ждём решения админов",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, dbMock := newTestBot(t)
+			dbMock.EXPECT().GetUserByTelegramID(gomock.Any(), int64(10)).Return(&model.User{TelegramID: 10, Status: tt.status}, nil)
+			dbMock.EXPECT().GetAllUserForms(gomock.Any(), int64(10)).Return(tt.forms, nil)
+			if tt.poll != nil {
+				dbMock.EXPECT().GetOpenMembershipPollByUser(gomock.Any(), int64(10)).Return(tt.poll, nil)
+			} else {
+				dbMock.EXPECT().GetOpenMembershipPollByUser(gomock.Any(), int64(10)).Return(nil, gorm.ErrRecordNotFound)
+			}
+			var text string
+			dbMock.EXPECT().CreateOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, message *model.OutboxMessage) (*model.OutboxMessage, error) {
+				decoded, err := decodeOutboxMessage(message)
+				assert.NoError(t, err)
+				text = decoded.(tgbotapi.MessageConfig).Text
+				return message, nil
+			})
+			b.processStatusCommand(&tgbotapi.Message{From: &tgbotapi.User{ID: 10}, Chat: &tgbotapi.Chat{ID: 10}}, nil)
+			assert.Contains(t, text, "Что дальше:</b> "+tt.nextStep)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package telegram
 
 import (
 	"beneburg/pkg/database/model"
+	"errors"
 	"fmt"
 	"html"
 	"strings"
@@ -13,18 +14,85 @@ type Templator interface {
 	FormInfo(form *model.Form) string
 	UserIdWithHref(user *model.User) string
 	InfoCommandReply(user *model.User, form *model.Form) string
+	InlineFormMessage(form *model.Form) string
+	InlineFormDescription(form *model.Form) string
+	InlineMembersOnly() string
+	BirthdayGreeting(forms []*model.Form) string
+	BirthdayGreetingsState(optOut bool) string
+	BirthdayGreetingsChanged(optOut bool) string
+	CommunityDigest(digest *CommunityDigest) string
+	DigestPreview(scheduled bool) string
 	LoginCommandReply(token *model.Token) string
 	StartCommandReply() string
 	NewFormMessage(user *model.User, form *model.Form) string
 	NewFormPoll() string
 	AcceptFormReply(userStatus string) string
 	FormReceived() string
-	RejectFormReply(userStatus string) string
+	RejectFormReply(userStatus string, reason string) string
 	RejectUserReply() string
 	AcceptUserReply(link string) string
 	AcceptUserGroupReply() string
 	RejectUserGroupReply() string
 	NewChatMember() string
+	CommandNotAllowed() string
+	CommandUsage(usage string) string
+	UserDisplayName(user *model.User) string
+	UnknownRole(roles []string) string
+	RoleChanged(user *model.User, role string) string
+	RolesList(roles []*model.Role) string
+	PollsList(polls []*model.MembershipPoll) string
+	PollInconclusive(user *model.User, poll *model.MembershipPoll) string
+	PollInconclusiveGroupReply() string
+	MembershipPollNotSent(user *model.User) string
+	RejectionReasonPrompt() string
+	FormRejected(reason string) string
+	FormAlreadyReviewed() string
+	FormAccepted() string
+	FormAlreadyDecided(form *model.Form, decider *model.User) string
+	FormDecisionStamp(form *model.Form, decider *model.User) string
+	CallbackInvalid() string
+	CallbackDone() string
+	CallbackFailed() string
+	CallbackNotAllowed() string
+	MembershipAlreadyDecided(poll *model.MembershipPoll) string
+	ApplyStepAnswered() string
+	NewFormReview(user *model.User, form *model.Form) string
+	ReviewDigest(forms []*model.Form, shown int) string
+	ApplyReminder(last bool) string
+	ApplyStarted() string
+	ApplyResumed() string
+	ApplyFormOnReview() string
+	ApplyUseButtons() string
+	ApplyAnswerInvalid(err error) string
+	ApplyCancelled() string
+	ApplyConfirm(form *model.Form) string
+	ApplicationStatus(user *model.User, forms []*model.Form, poll *model.MembershipPoll) string
+	ProfileURL() string
+	UserNotFound() string
+	UsersFound(count int) string
+	UserCard(user *model.User, role string, forms []*model.Form, polls []*model.MembershipPoll) string
+	UserStatusChanged(user *model.User, status string) string
+	UserStatusChangedMeanwhile() string
+	UserPageLink(user *model.User) string
+	BanCommandNoTarget() string
+	UserBanned(user *model.User, reason *string) string
+	UserAlreadyBanned(user *model.User) string
+	UserUnbanned(user *model.User) string
+	UserNotBanned(user *model.User) string
+	BannedReply(reason *string) string
+	UnbannedReply() string
+	LoginBanned() string
+	ReconcileStarted() string
+	ReconcileAlreadyRequested() string
+	MembershipReconciled(report *MembershipReport) string
+	BotRemovedFromGroup(by *model.User) string
+	BotLostRights(by *model.User, rights []string) string
+	InviteLinkReply(link *model.InviteLink) string
+	InviteLinkFailed() string
+	InviteAlreadyMember() string
+	InviteNotAllowed() string
+	JoinRequestWrongLink() string
+	JoinRequestDeclined(user *model.User, reason string) string
 }
 
 var _ Templator = templator{}
@@ -33,6 +101,71 @@ type templator struct {
 	domain string
 }
 
+func (t templator) CommandNotAllowed() string {
+	return "Эта команда тебе недоступна."
+}
+
+func (t templator) CommandUsage(usage string) string {
+	return fmt.Sprintf("Использование: %s", usage)
+}
+
+func (t templator) UserDisplayName(user *model.User) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString(html.EscapeString(user.FirstName))
+	if user.LastName != nil {
+		stringBuilder.WriteString(" ")
+		stringBuilder.WriteString(html.EscapeString(*user.LastName))
+	}
+	if user.Username != nil {
+		stringBuilder.WriteString(fmt.Sprintf(" (@%s)", html.EscapeString(*user.Username)))
+	}
+	return stringBuilder.String()
+}
+
+func (t templator) UnknownRole(roles []string) string {
+	return fmt.Sprintf("Неизвестная роль. Доступные роли: %s", strings.Join(roles, ", "))
+}
+
+func (t templator) RoleChanged(user *model.User, role string) string {
+	return fmt.Sprintf("%s теперь %s.\n%s", t.UserDisplayName(user), model.RuRole(role), t.UserIdWithHref(user))
+}
+
+func (t templator) RolesList(roles []*model.Role) string {
+	if len(roles) == 0 {
+		return "Ролей пока нет."
+	}
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString("<b>Роли:</b>\n")
+	for _, role := range roles {
+		stringBuilder.WriteString(fmt.Sprintf("\n%s — %s, <code>%d</code>", t.UserDisplayName(&role.User), model.RuRole(role.Role), role.UserTelegramId))
+	}
+	return stringBuilder.String()
+}
+
+func (t templator) PollsList(polls []*model.MembershipPoll) string {
+	if len(polls) == 0 {
+		return "Открытых голосований нет."
+	}
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString("<b>Открытые голосования:</b>\n")
+	for _, poll := range polls {
+		stringBuilder.WriteString(fmt.Sprintf("\n%s — за: %d, против: %d (с %s)", t.UserDisplayName(&poll.User), poll.AcceptVotes, poll.RejectVotes, poll.CreatedAt.Format("02.01.2006")))
+	}
+	return stringBuilder.String()
+}
+
+func (t templator) PollInconclusive(user *model.User, poll *model.MembershipPoll) string {
+	return fmt.Sprintf("Голосование по %s закончилось без решения: за %d, против %d. Прими решение кнопками под опросом в группе.\n%s", t.UserDisplayName(user), poll.AcceptVotes, poll.RejectVotes, t.UserIdWithHref(user))
+}
+
+func (t templator) MembershipPollNotSent(user *model.User) string {
+	return fmt.Sprintf("Не получилось отправить в группу голосование по %s. Прими решение кнопками ниже.\n%s", t.UserDisplayName(user), t.UserIdWithHref(user))
+}
+
+func (t templator) PollInconclusiveGroupReply() string {
+	return "Голосование закончилось без решения, решат админы."
+}
+
 func (t templator) NewChatMember() string {
 	return "Привет! Добро пожаловать! 🎉"
 }
@@ -50,19 +183,401 @@ func (t templator) AcceptUserReply(link string) string {
 	stringBuilder.WriteString("Ура, твоя анкета была успешно одобрена и мы рады пргласить тебя к нам! 🎉\n")
 	stringBuilder.WriteString("Теперь нажми ")
 	stringBuilder.WriteString(fmt.Sprintf("<a href=\"%s\">сюда</a>", link))
-	stringBuilder.WriteString(" и подай заявку на вступление.")
+	stringBuilder.WriteString(" и подай заявку на вступление.\n")
+	stringBuilder.WriteString("Ссылка личная и работает только для тебя. Если она истечёт, напиши /invite.")
+	return stringBuilder.String()
+}
+
+func (t templator) InviteLinkReply(link *model.InviteLink) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString(fmt.Sprintf("Вот твоя личная <a href=\"%s\">ссылка</a> в чат, по ней нужно подать заявку на вступление.\n", html.EscapeString(link.Link)))
+	stringBuilder.WriteString(fmt.Sprintf("Ссылка работает только для тебя до %s.", link.ExpireAt.Format("02.01.2006 15:04")))
 	return stringBuilder.String()
 }
 
+func (t templator) InviteLinkFailed() string {
+	return "Не получилось создать ссылку в чат, напиши /invite чуть позже."
+}
+
+func (t templator) InviteAlreadyMember() string {
+	return "Ты уже в чате."
+}
+
+func (t templator) InviteNotAllowed() string {
+	return "Ссылку в чат можно получить, только когда анкету одобрят. Узнать, где сейчас анкета, можно командой /status."
+}
+
+func (t templator) JoinRequestWrongLink() string {
+	return "Заявку в чат нужно подавать по своей личной ссылке. Получить её можно командой /invite."
+}
+
+func (t templator) JoinRequestDeclined(user *model.User, reason string) string {
+	return fmt.Sprintf("Отклонил заявку в чат от %s: %s.\n%s", t.UserDisplayName(user), reason, t.UserIdWithHref(user))
+}
+
 func (t templator) RejectUserReply() string {
 	return "Извини, но сейчас мы не готовы принять тебя в чатик. Надеемся, что тебя это не очень расстроило 😥"
 }
 
-func (t templator) RejectFormReply(userStatus string) string {
+func (t templator) RejectFormReply(userStatus string, reason string) string {
+	stringBuilder := strings.Builder{}
 	if userStatus == model.UserStatusActive {
-		return "Анкетку отклонили."
+		stringBuilder.WriteString("Анкетку отклонили.")
+	} else {
+		stringBuilder.WriteString("Извини, но сейчас мы не готовы принять тебя в чатик. Надеемся, что тебя это не очень расстроило 😥")
 	}
-	return "Извини, но сейчас мы не готовы принять тебя в чатик. Надеемся, что тебя это не очень расстроило 😥"
+	if reason != "" {
+		stringBuilder.WriteString(fmt.Sprintf("\n\n<b>Причина:</b> %s", html.EscapeString(reason)))
+		stringBuilder.WriteString(fmt.Sprintf("\n\nМожешь исправить анкету и отправить её снова: %s", t.ProfileURL()))
+	}
+	return stringBuilder.String()
+}
+
+func (t templator) RejectionReasonPrompt() string {
+	return "Почему отклоняем? Напиши причину ответом на это сообщение или выбери готовую под анкетой. Её увидит автор анкеты."
+}
+
+func (t templator) FormRejected(reason string) string {
+	return fmt.Sprintf("Анкета отклонена. Причина: %s", reason)
+}
+
+func (t templator) NewFormReview(user *model.User, form *model.Form) string {
+	return fmt.Sprintf("Новая анкета:\n\n%s\n\n%s", t.FormInfo(form), t.UserIdWithHref(user))
+}
+
+func (t templator) ReviewDigest(forms []*model.Form, shown int) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString(fmt.Sprintf("<b>Анкеты ждут проверки: %d</b>, самая старая — с %s.", len(forms), forms[0].CreatedAt.Format("02.01.2006")))
+	if shown < len(forms) {
+		stringBuilder.WriteString(fmt.Sprintf("\nНиже первые %d, остальные — на сайте: %s", shown, t.domain+"/admin"))
+	}
+	return stringBuilder.String()
+}
+
+func (t templator) ApplyReminder(last bool) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString("Привет! Ты так и не заполнил анкету для вступления в чат. ")
+	stringBuilder.WriteString("Это можно сделать прямо здесь командой /apply или на сайте по ссылке из /login.")
+	if last {
+		stringBuilder.WriteString("\n\nБольше напоминать не буду.")
+	}
+	return stringBuilder.String()
+}
+
+func (t templator) ApplyStarted() string {
+	return "Заполним анкету! Отвечай на вопросы по одному, необязательные можно пропустить."
+}
+
+func (t templator) ApplyResumed() string {
+	return "Продолжим заполнять анкету с того места, где остановились."
+}
+
+func (t templator) ApplyFormOnReview() string {
+	return "Твоя анкета уже на проверке, дождись ответа."
+}
+
+func (t templator) ApplyUseButtons() string {
+	return "Выбери ответ кнопкой под вопросом."
+}
+
+// ApplyAnswerInvalid explains why the answer to a form question was refused.
+func (t templator) ApplyAnswerInvalid(err error) string {
+	switch {
+	case errors.Is(err, errApplyEmptyAnswer):
+		return "Это обязательный вопрос, напиши ответ текстом."
+	case errors.Is(err, errApplyUnknownChoice):
+		return "Выбери один из вариантов кнопкой под вопросом."
+	case errors.Is(err, errBirthdateFormat):
+		return "Напиши дату рождения как ДД.ММ.ГГГГ или только ДД.ММ, например 18.10.1995 или 18.10 без года."
+	case errors.Is(err, errBirthdateAge):
+		return fmt.Sprintf("Возраст должен быть от %d до %d лет.", applyMinAge, applyMaxAge)
+	default:
+		return "Не получилось сохранить ответ, попробуй ещё раз."
+	}
+}
+
+func (t templator) ApplyCancelled() string {
+	return "Заполнение анкеты отменено. Начать заново можно командой /apply."
+}
+
+func (t templator) ApplyConfirm(form *model.Form) string {
+	return fmt.Sprintf("Проверь анкету:\n\n%s\n\nВсё верно?", t.FormInfo(form))
+}
+
+// ApplicationStatus lists the user's forms from the newest, poll is nil if no poll is running.
+func (t templator) ApplicationStatus(user *model.User, forms []*model.Form, poll *model.MembershipPoll) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString(fmt.Sprintf("<b>Статус:</b> %s", model.RuUserStatus(user.Status)))
+	AddDelimiter(&stringBuilder)
+	if len(forms) == 0 {
+		stringBuilder.WriteString("<b>Анкеты:</b> ещё нет")
+	} else {
+		stringBuilder.WriteString("<b>Анкеты:</b>")
+		for _, form := range forms {
+			stringBuilder.WriteString(fmt.Sprintf("\n• %s — %s", form.CreatedAt.Format("02.01.2006 15:04"), model.RuFormStatus(form.Status)))
+			if form.RejectionReason != nil {
+				stringBuilder.WriteString(fmt.Sprintf(" (%s)", html.EscapeString(*form.RejectionReason)))
+			}
+		}
+	}
+	if poll != nil {
+		AddDelimiter(&stringBuilder)
+		stringBuilder.WriteString(fmt.Sprintf("<b>Голосование в чате:</b> за %d, против %d", poll.AcceptVotes, poll.RejectVotes))
+	}
+	AddDelimiter(&stringBuilder)
+	stringBuilder.WriteString(fmt.Sprintf("<b>Что дальше:</b> %s", t.nextStep(user, forms, poll)))
+	return stringBuilder.String()
+}
+
+func (t templator) nextStep(user *model.User, forms []*model.Form, poll *model.MembershipPoll) string {
+	switch user.Status {
+	case model.UserStatusBanned:
+		return "доступ закрыт."
+	case model.UserStatusActive:
+		return "ты уже в чате. Анкету можно обновить в профиле."
+	case model.UserStatusNotActive:
+		return "ты вышел из чата. Вернуться можно по ссылке-приглашению."
+	case model.UserStatusAccepted:
+		return "тебя приняли! Вступай в чат по ссылке из сообщения о принятии."
+	case model.UserStatusRejected:
+		return "к сожалению, тебя не приняли в чат."
+	}
+	switch {
+	case len(forms) == 0:
+		return "заполни анкету командой /apply или на сайте."
+	case forms[0].Status == model.FormStatusNew:
+		return "анкету проверяет администратор, ответ придёт сюда."
+	case forms[0].Status == model.FormStatusRejected:
+		return "исправь анкету и отправь её снова командой /apply или на сайте."
+	case poll != nil:
+		return "в чате идёт голосование, результат придёт сюда."
+	default:
+		return "ждём решения админов, ответ придёт сюда."
+	}
+}
+
+func (t templator) ProfileURL() string {
+	return t.domain + "/profile"
+}
+
+func (t templator) UserNotFound() string {
+	return "Никого не нашёл."
+}
+
+func (t templator) UsersFound(count int) string {
+	return fmt.Sprintf("Нашёл несколько пользователей (%d), выбери нужного:", count)
+}
+
+// UserCard shows the latest form, forms are ordered from the newest as well as polls.
+func (t templator) UserCard(user *model.User, role string, forms []*model.Form, polls []*model.MembershipPoll) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString(fmt.Sprintf("<b>%s</b>", t.UserDisplayName(user)))
+	stringBuilder.WriteString(fmt.Sprintf("\n%s", t.UserIdWithHref(user)))
+	stringBuilder.WriteString(fmt.Sprintf("\n<b>Статус:</b> %s", model.RuUserStatus(user.Status)))
+	if role != model.RoleMember {
+		stringBuilder.WriteString(fmt.Sprintf("\n<b>Роль:</b> %s", model.RuRole(role)))
+	}
+	if len(forms) > 0 {
+		AddDelimiter(&stringBuilder)
+		stringBuilder.WriteString(t.FormInfo(forms[0]))
+	}
+	AddDelimiter(&stringBuilder)
+	stringBuilder.WriteString("<b>История:</b>")
+	if len(forms) == 0 && len(polls) == 0 {
+		stringBuilder.WriteString(" пусто")
+	}
+	for _, form := range forms {
+		stringBuilder.WriteString(fmt.Sprintf("\n• %s — анкета %s", form.CreatedAt.Format("02.01.2006 15:04"), model.RuFormStatus(form.Status)))
+		if form.RejectionReason != nil {
+			stringBuilder.WriteString(fmt.Sprintf(" (%s)", html.EscapeString(*form.RejectionReason)))
+		}
+	}
+	for _, poll := range polls {
+		stringBuilder.WriteString(fmt.Sprintf("\n• %s — голосование: за %d, против %d", poll.CreatedAt.Format("02.01.2006 15:04"), poll.AcceptVotes, poll.RejectVotes))
+		if poll.Decision != nil {
+			stringBuilder.WriteString(fmt.Sprintf(", %s", model.RuPollDecision(*poll.Decision)))
+		}
+	}
+	return stringBuilder.String()
+}
+
+func (t templator) UserStatusChanged(user *model.User, status string) string {
+	return fmt.Sprintf("Статус %s: %s.", t.UserDisplayName(user), model.RuUserStatus(status))
+}
+
+// UserStatusChangedMeanwhile is a callback query toast, so it's plain text.
+func (t templator) UserStatusChangedMeanwhile() string {
+	return "Статус уже изменился, открой карточку пользователя заново."
+}
+
+func (t templator) BanCommandNoTarget() string {
+	return "Ответь командой на сообщение пользователя или укажи его @username или ID."
+}
+
+func (t templator) UserBanned(user *model.User, reason *string) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString(fmt.Sprintf("%s заблокирован.\n%s", t.UserDisplayName(user), t.UserIdWithHref(user)))
+	if reason != nil {
+		stringBuilder.WriteString(fmt.Sprintf("\n<b>Причина:</b> %s", html.EscapeString(*reason)))
+	}
+	return stringBuilder.String()
+}
+
+func (t templator) UserAlreadyBanned(user *model.User) string {
+	return fmt.Sprintf("%s уже заблокирован, снять блокировку можно командой /unban.", t.UserDisplayName(user))
+}
+
+func (t templator) UserUnbanned(user *model.User) string {
+	return fmt.Sprintf("%s разблокирован.\n%s", t.UserDisplayName(user), t.UserIdWithHref(user))
+}
+
+func (t templator) UserNotBanned(user *model.User) string {
+	return fmt.Sprintf("%s не заблокирован.", t.UserDisplayName(user))
+}
+
+func (t templator) BannedReply(reason *string) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString("Тебя заблокировали в чате, вход на сайт тоже закрыт.")
+	if reason != nil {
+		stringBuilder.WriteString(fmt.Sprintf("\n<b>Причина:</b> %s", html.EscapeString(*reason)))
+	}
+	stringBuilder.WriteString(fmt.Sprintf("\n\nПодробнее: %s", html.EscapeString(t.BanPageURL())))
+	return stringBuilder.String()
+}
+
+func (t templator) UnbannedReply() string {
+	return "Блокировку сняли, можешь снова пользоваться ботом и сайтом."
+}
+
+func (t templator) LoginBanned() string {
+	return fmt.Sprintf("Ты заблокирован, вход на сайт закрыт.\n\nПодробнее: %s", html.EscapeString(t.BanPageURL()))
+}
+
+func (t templator) BanPageURL() string {
+	return fmt.Sprintf("%s/ban", t.domain)
+}
+
+const membershipReportMaxChanges = 40
+
+func (t templator) ReconcileStarted() string {
+	return "Сверяю участников чата с базой, пришлю отчёт, когда закончу."
+}
+
+func (t templator) ReconcileAlreadyRequested() string {
+	return "Сверка уже запрошена, дождись отчёта."
+}
+
+func (t templator) MembershipReconciled(report *MembershipReport) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString(fmt.Sprintf("<b>Сверка участников чата</b>\nПроверено: %d", report.Checked))
+	if report.Failed > 0 {
+		stringBuilder.WriteString(fmt.Sprintf(", с ошибкой: %d", report.Failed))
+	}
+	if len(report.Changes) == 0 {
+		stringBuilder.WriteString("\n\nРасхождений нет.")
+		return stringBuilder.String()
+	}
+	stringBuilder.WriteString("\n\n<b>Расхождения:</b>")
+	for i, change := range report.Changes {
+		// Keeps the message within Telegram's length limit
+		if i == membershipReportMaxChanges {
+			stringBuilder.WriteString(fmt.Sprintf("\n…и ещё %d", len(report.Changes)-i))
+			break
+		}
+		stringBuilder.WriteString(fmt.Sprintf("\n• %s, <code>%d</code>: ", t.UserDisplayName(change.User), change.User.TelegramID))
+		if change.From == change.To {
+			stringBuilder.WriteString(fmt.Sprintf("%s, но в чате", model.RuUserStatus(change.From)))
+			continue
+		}
+		stringBuilder.WriteString(fmt.Sprintf("%s → %s", model.RuUserStatus(change.From), model.RuUserStatus(change.To)))
+	}
+	return stringBuilder.String()
+}
+
+func (t templator) BotRemovedFromGroup(by *model.User) string {
+	return fmt.Sprintf("⚠️ %s убрал бота из чата. Пока бот не вернётся админом, он не сможет принимать заявки и блокировать участников.", t.UserDisplayName(by))
+}
+
+func (t templator) BotLostRights(by *model.User, rights []string) string {
+	return fmt.Sprintf("⚠️ %s забрал у бота права в чате. Бот больше не может: %s.", t.UserDisplayName(by), strings.Join(rights, ", "))
+}
+
+func (t templator) FormAccepted() string {
+	return "Анкета принята."
+}
+
+// FormAlreadyDecided is a callback query toast, so it's plain text.
+func (t templator) FormAlreadyDecided(form *model.Form, decider *model.User) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString(fmt.Sprintf("Анкету уже проверили: %s", model.RuFormStatus(form.Status)))
+	if decider != nil {
+		stringBuilder.WriteString(fmt.Sprintf(", %s", html.UnescapeString(t.UserDisplayName(decider))))
+	}
+	if form.DecidedAt != nil {
+		stringBuilder.WriteString(fmt.Sprintf(", %s", form.DecidedAt.Format("02.01.2006 15:04")))
+	}
+	stringBuilder.WriteString(".")
+	return stringBuilder.String()
+}
+
+// CallbackInvalid is a callback query toast for a button the bot can't read.
+func (t templator) CallbackInvalid() string {
+	return "Эта кнопка устарела или повреждена, открой сообщение заново."
+}
+
+func (t templator) CallbackDone() string {
+	return "Готово."
+}
+
+func (t templator) CallbackFailed() string {
+	return "Не получилось, попробуй ещё раз. Админ уже знает об ошибке."
+}
+
+func (t templator) CallbackNotAllowed() string {
+	return "Эта кнопка не для тебя."
+}
+
+// MembershipAlreadyDecided is a callback query toast, so it's plain text.
+func (t templator) MembershipAlreadyDecided(poll *model.MembershipPoll) string {
+	if poll == nil || poll.Decision == nil {
+		return "Решение уже принято."
+	}
+	return fmt.Sprintf("Решение уже принято: %s.", model.RuPollDecision(*poll.Decision))
+}
+
+func (t templator) ApplyStepAnswered() string {
+	return "Этот вопрос уже пройден."
+}
+
+// FormDecisionStamp is appended to the review message once the form is decided.
+func (t templator) FormDecisionStamp(form *model.Form, decider *model.User) string {
+	stringBuilder := strings.Builder{}
+	AddDelimiter(&stringBuilder)
+	switch form.Status {
+	case model.FormStatusAccepted:
+		stringBuilder.WriteString("✅ <b>Принята</b>")
+	case model.FormStatusRejected:
+		stringBuilder.WriteString("❌ <b>Отклонена</b>")
+	default:
+		stringBuilder.WriteString(fmt.Sprintf("<b>%s</b>", model.RuFormStatus(form.Status)))
+	}
+	switch {
+	case decider != nil:
+		stringBuilder.WriteString(fmt.Sprintf("\n<b>Кто:</b> %s", t.UserDisplayName(decider)))
+	case form.DecidedBy != nil:
+		stringBuilder.WriteString(fmt.Sprintf("\n<b>Кто:</b> <code>%d</code>", *form.DecidedBy))
+	}
+	if form.DecidedAt != nil {
+		stringBuilder.WriteString(fmt.Sprintf("\n<b>Когда:</b> %s", form.DecidedAt.Format("02.01.2006 15:04")))
+	}
+	if form.Status == model.FormStatusRejected && form.RejectionReason != nil {
+		stringBuilder.WriteString(fmt.Sprintf("\n<b>Причина:</b> %s", html.EscapeString(*form.RejectionReason)))
+	}
+	return stringBuilder.String()
+}
+
+func (t templator) FormAlreadyReviewed() string {
+	return "Эту анкету уже проверили."
 }
 
 func (t templator) FormReceived() string {
@@ -130,6 +645,118 @@ func (t templator) InfoCommandReply(user *model.User, form *model.Form) string {
 	return stringBuilder.String()
 }
 
+// InlineFormMessage is the message sent to a chat from the inline search results.
+func (t templator) InlineFormMessage(form *model.Form) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString(t.FormInfo(form))
+	AddDelimiter(&stringBuilder)
+	stringBuilder.WriteString(fmt.Sprintf("<b>Профиль участника:</b>\n%s", html.EscapeString(t.UserPageLink(&form.User))))
+	return stringBuilder.String()
+}
+
+const inlineDescriptionMaxLength = 100
+
+// InlineFormDescription is shown under the name in the inline search results, so it's plain text.
+func (t templator) InlineFormDescription(form *model.Form) string {
+	description := ""
+	switch {
+	case form.Hobbies != nil && *form.Hobbies != "":
+		description = *form.Hobbies
+	case form.About != nil && *form.About != "":
+		description = *form.About
+	}
+	if runes := []rune(description); len(runes) > inlineDescriptionMaxLength {
+		description = string(runes[:inlineDescriptionMaxLength]) + "…"
+	}
+	return description
+}
+
+func (t templator) InlineMembersOnly() string {
+	return "Поиск доступен только участникам"
+}
+
+func (t templator) BirthdayGreeting(forms []*model.Form) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString("🎂 Сегодня день рождения у ")
+	for i, form := range forms {
+		if i > 0 {
+			stringBuilder.WriteString(", ")
+		}
+		stringBuilder.WriteString(fmt.Sprintf("<a href=\"tg://user?id=%d\">%s</a>", form.UserTelegramId, html.EscapeString(form.Name)))
+	}
+	stringBuilder.WriteString("! Поздравляем 🎉")
+	return stringBuilder.String()
+}
+
+func (t templator) CommunityDigest(digest *CommunityDigest) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString(fmt.Sprintf("<b>Дайджест сообщества с %s по %s</b>", digest.From.Format("02.01"), digest.To.Format("02.01")))
+	if len(digest.Joined) > 0 {
+		stringBuilder.WriteString("\n\n<b>Новые участники:</b>")
+		for i, member := range digest.Joined {
+			if i == digestMaxMembers {
+				stringBuilder.WriteString(fmt.Sprintf("\nи ещё %d", len(digest.Joined)-i))
+				break
+			}
+			if member.Form == nil {
+				stringBuilder.WriteString(fmt.Sprintf("\n• <a href=\"%s\">%s</a>", html.EscapeString(t.UserPageLink(member.User)), t.UserDisplayName(member.User)))
+				continue
+			}
+			stringBuilder.WriteString("\n• " + t.formExcerpt(member.Form))
+		}
+	}
+	if len(digest.Updated) > 0 {
+		stringBuilder.WriteString("\n\n<b>Обновили анкеты:</b>")
+		for i, form := range digest.Updated {
+			if i == digestMaxMembers {
+				stringBuilder.WriteString(fmt.Sprintf("\nи ещё %d", len(digest.Updated)-i))
+				break
+			}
+			stringBuilder.WriteString("\n• " + t.formExcerpt(form))
+		}
+	}
+	if len(digest.Joined) == 0 && len(digest.Updated) == 0 {
+		stringBuilder.WriteString("\n\nНовых участников и обновлённых анкет нет.")
+	}
+	stringBuilder.WriteString(fmt.Sprintf("\n\nАнкет получено: %d, одобрено: %d, отклонено: %d.", digest.Received, digest.Accepted, digest.Rejected))
+	return stringBuilder.String()
+}
+
+// formExcerpt is the name linked to the profile page with the age and the beginning of
+// the hobbies or about.
+func (t templator) formExcerpt(form *model.Form) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString(fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(t.UserPageLink(&form.User)), html.EscapeString(form.Name)))
+	if age := form.CurrentAge(); age != nil {
+		stringBuilder.WriteString(fmt.Sprintf(", %d", *age))
+	}
+	if description := t.InlineFormDescription(form); description != "" {
+		stringBuilder.WriteString(" — " + html.EscapeString(description))
+	}
+	return stringBuilder.String()
+}
+
+func (t templator) DigestPreview(scheduled bool) string {
+	if scheduled {
+		return "<i>Так будет выглядеть следующий дайджест, если до публикации ничего не изменится:</i>"
+	}
+	return "<i>Публикация дайджеста выключена, вот каким был бы следующий:</i>"
+}
+
+func (t templator) BirthdayGreetingsState(optOut bool) string {
+	if optOut {
+		return "Поздравление с днём рождения в чате выключено. Включить: /birthday on"
+	}
+	return "В день рождения бот поздравит тебя в чате. Выключить: /birthday off"
+}
+
+func (t templator) BirthdayGreetingsChanged(optOut bool) string {
+	if optOut {
+		return "Готово, бот не будет поздравлять тебя в чате."
+	}
+	return "Готово, в день рождения бот поздравит тебя в чате."
+}
+
 func (t templator) UserIdWithHref(user *model.User) string {
 	return fmt.Sprintf(
 		"<i>ID <a href=\"tg://user?id=%d\">пользователя</a>: </i><code>%d</code>",
@@ -145,9 +772,13 @@ func (t templator) UserPageLink(user *model.User) string {
 func (t templator) FormInfo(form *model.Form) string {
 	stringBuilder := strings.Builder{}
 	stringBuilder.WriteString(fmt.Sprintf("<b>%s</b>:\n%s", model.UserNameDescription, html.EscapeString(form.Name)))
-	if form.Age != nil {
+	if age := form.CurrentAge(); age != nil {
+		AddDelimiter(&stringBuilder)
+		stringBuilder.WriteString(fmt.Sprintf("<b>%s</b>:\n%d", model.UserAgeDescription, *age))
+	}
+	if birthdate := form.Birthdate(); birthdate != "" {
 		AddDelimiter(&stringBuilder)
-		stringBuilder.WriteString(fmt.Sprintf("<b>%s</b>:\n%d", model.UserAgeDescription, *form.Age))
+		stringBuilder.WriteString(fmt.Sprintf("<b>%s</b>:\n%s", model.UserBirthdateDescription, birthdate))
 	}
 	AddDelimiter(&stringBuilder)
 	stringBuilder.WriteString(fmt.Sprintf("<b>%s</b>:\n%s", model.UserGenderDescription, html.EscapeString(form.Gender)))
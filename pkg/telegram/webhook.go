@@ -0,0 +1,122 @@
+package telegram
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+const (
+	UpdatesModePolling = "polling"
+	UpdatesModeWebhook = "webhook"
+)
+
+// WebhookPath is the path on the gin router where Telegram delivers updates in webhook mode.
+const WebhookPath = "/telegram/webhook"
+
+// secretTokenHeader is set by Telegram on every webhook request to the secret_token passed to setWebhook.
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+type webhookConfig struct {
+	url         string
+	secretToken string
+}
+
+func (b *botManager) SetWebhook(url string, secretToken string) {
+	b.webhook = &webhookConfig{
+		url:         url,
+		secretToken: secretToken,
+	}
+}
+
+func (b *botManager) UpdatesMode() string {
+	if b.webhook != nil {
+		return UpdatesModeWebhook
+	}
+	return UpdatesModePolling
+}
+
+// RegisterWebhook mounts the update endpoint on the router. It does nothing in polling mode.
+func (b *botManager) RegisterWebhook(router gin.IRouter) {
+	if b.webhook == nil {
+		return
+	}
+	router.POST(WebhookPath, b.handleWebhook)
+}
+
+func (b *botManager) handleWebhook(g *gin.Context) {
+	secretToken := g.GetHeader(secretTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(secretToken), []byte(b.webhook.secretToken)) != 1 {
+		b.logger.Named("handleWebhook").Warn("Webhook request with invalid secret token", zap.String("ip", g.ClientIP()))
+		g.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	update, err := b.bot.HandleUpdate(g.Request)
+	if err != nil {
+		b.logger.Named("handleWebhook").Error("Error while parsing webhook update", zap.Error(err))
+		g.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	select {
+	case b.updatesChan <- *update:
+		g.Status(http.StatusOK)
+	case <-b.receiveCtx.Done():
+		// Telegram will redeliver the update to the next instance
+		g.AbortWithStatus(http.StatusServiceUnavailable)
+	}
+}
+
+func (b *botManager) startWebhook() {
+	err := b.setWebhook()
+	if err != nil {
+		b.logger.Named("startWebhook").Error("Error while setting webhook", zap.Error(err))
+		return
+	}
+	b.logger.Named("startWebhook").Info("Webhook set", zap.String("url", b.webhook.url))
+}
+
+func (b *botManager) stopWebhook() {
+	err := b.deleteWebhook()
+	if err != nil {
+		b.logger.Named("stopWebhook").Error("Error while deleting webhook", zap.Error(err))
+		return
+	}
+	b.logger.Named("stopWebhook").Info("Webhook deleted")
+}
+
+func (b *botManager) setWebhook() error {
+	// tgbotapi.WebhookConfig has no secret_token field, so the request is made by hand
+	params := tgbotapi.Params{}
+	params["url"] = b.webhook.url
+	params.AddNonEmpty("secret_token", b.webhook.secretToken)
+	err := params.AddInterface("allowed_updates", allowedUpdates)
+	if err != nil {
+		return err
+	}
+	_, err = b.bot.MakeRequest("setWebhook", params)
+	return err
+}
+
+func (b *botManager) deleteWebhook() error {
+	_, err := b.bot.Request(tgbotapi.DeleteWebhookConfig{})
+	return err
+}
+
+// dropWebhookIfSet removes a webhook left by a previous run, otherwise getUpdates is rejected by Telegram.
+func (b *botManager) dropWebhookIfSet() {
+	info, err := b.bot.GetWebhookInfo()
+	if err != nil {
+		b.logger.Named("dropWebhookIfSet").Error("Error while getting webhook info", zap.Error(err))
+		return
+	}
+	if !info.IsSet() {
+		return
+	}
+	b.logger.Named("dropWebhookIfSet").Info("Webhook is set, deleting it to use polling", zap.String("url", info.URL))
+	err = b.deleteWebhook()
+	if err != nil {
+		b.logger.Named("dropWebhookIfSet").Error("Error while deleting webhook", zap.Error(err))
+	}
+}
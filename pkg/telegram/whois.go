@@ -0,0 +1,211 @@
+package telegram
+
+import (
+	"beneburg/pkg/database/model"
+	"errors"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+)
+
+const whoisSearchLimit = 10
+
+// whoisStatuses can be set from the user card. Banning has its own command.
+var whoisStatuses = []string{
+	model.UserStatusNew,
+	model.UserStatusAccepted,
+	model.UserStatusRejected,
+	model.UserStatusActive,
+	model.UserStatusNotActive,
+}
+
+func (b *botManager) processWhoisCommand(message *tgbotapi.Message, args commandArgs) {
+	b.logger.Named("processWhoisCommand").Debug("Processing whois command")
+	ref := args["@username|id|имя"]
+	user, err := b.findUser(ref)
+	if err == nil {
+		b.sendUserCard(message.Chat.ID, user)
+		return
+	}
+	if !errors.Is(err, noRecordError) {
+		b.logger.Named("processWhoisCommand").Error("Error while finding user", zap.Error(err))
+		return
+	}
+	users, err := b.db.SearchUsers(b.ctx, strings.TrimPrefix(ref, "@"), whoisSearchLimit)
+	if err != nil {
+		b.logger.Named("processWhoisCommand").Error("Error while searching users", zap.Error(err))
+		return
+	}
+	switch len(users) {
+	case 0:
+		b.send(tgbotapi.NewMessage(message.Chat.ID, b.templator.UserNotFound()))
+	case 1:
+		b.sendUserCard(message.Chat.ID, users[0])
+	default:
+		var rows [][]tgbotapi.InlineKeyboardButton
+		for _, user := range users {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(userButtonText(user), b.callbacks.MustEncode(actionWhois, user.TelegramID))))
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.templator.UsersFound(len(users)))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		b.send(msg)
+	}
+}
+
+// userButtonText is the user's name without HTML, buttons show text as is.
+func userButtonText(user *model.User) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString(user.FirstName)
+	if user.LastName != nil {
+		stringBuilder.WriteString(" ")
+		stringBuilder.WriteString(*user.LastName)
+	}
+	if user.Username != nil {
+		stringBuilder.WriteString(fmt.Sprintf(" (@%s)", *user.Username))
+	}
+	if stringBuilder.Len() == 0 {
+		stringBuilder.WriteString(strconv.FormatInt(user.TelegramID, 10))
+	}
+	return stringBuilder.String()
+}
+
+// sendUserCard sends the user's latest form with the history of their forms and polls.
+func (b *botManager) sendUserCard(chatID int64, user *model.User) {
+	forms, err := b.db.GetAllUserForms(b.ctx, user.TelegramID)
+	if err != nil {
+		b.logger.Named("sendUserCard").Error("Error while getting forms", zap.Error(err))
+		return
+	}
+	polls, err := b.db.GetMembershipPollsByUser(b.ctx, user.TelegramID)
+	if err != nil {
+		b.logger.Named("sendUserCard").Error("Error while getting polls", zap.Error(err))
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, b.templator.UserCard(user, b.userRole(user.TelegramID), forms, polls))
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.DisableWebPagePreview = true
+	msg.ReplyMarkup = b.userCardKeyboard(user, forms)
+	b.send(msg)
+}
+
+func (b *botManager) userCardKeyboard(user *model.User, forms []*model.Form) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonURL("Профиль на сайте", b.templator.UserPageLink(user))),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Изменить статус", b.callbacks.MustEncode(actionStatusMenu, user.TelegramID))),
+	}
+	if len(forms) > 0 && forms[0].Status == model.FormStatusNew {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Прислать анкету на проверку", b.callbacks.MustEncode(actionFormResend, forms[0].ID))))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func (b *botManager) statusKeyboard(telegramID int64) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, status := range whoisStatuses {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(model.RuUserStatus(status), b.callbacks.MustEncode(actionStatusSet, telegramID, status))))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Назад", b.callbacks.MustEncode(actionStatusBack, telegramID))))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// processWhoisCallbackQuery opens the card of a user picked from the search results.
+func (b *botManager) processWhoisCallbackQuery(chatID int64, telegramID int64) callbackAnswer {
+	user, err := b.db.GetUserByTelegramID(b.ctx, telegramID)
+	if err != nil {
+		b.logger.Named("processWhoisCallbackQuery").Error("Error while getting user", zap.Error(err))
+		return b.callbackFailed()
+	}
+	b.sendUserCard(chatID, user)
+	return callbackAnswer{}
+}
+
+// processStatusCallbackQuery handles the status menu of the user card. status is set only by the "set" action.
+func (b *botManager) processStatusCallbackQuery(chatID int64, messageID int, telegramID int64, action string, status string) callbackAnswer {
+	b.logger.Named("processStatusCallbackQuery").Debug("Processing status callback query", zap.Int64("telegram_id", telegramID), zap.String("action", action), zap.String("status", status))
+	user, err := b.db.GetUserByTelegramID(b.ctx, telegramID)
+	if err != nil {
+		b.logger.Named("processStatusCallbackQuery").Error("Error while getting user", zap.Error(err))
+		return b.callbackFailed()
+	}
+
+	switch action {
+	case "menu":
+		b.send(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, b.statusKeyboard(telegramID)))
+	case "back":
+		forms, err := b.db.GetAllUserForms(b.ctx, telegramID)
+		if err != nil {
+			b.logger.Named("processStatusCallbackQuery").Error("Error while getting forms", zap.Error(err))
+			return b.callbackFailed()
+		}
+		b.send(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, b.userCardKeyboard(user, forms)))
+	case "set":
+		if !isWhoisStatus(status) {
+			b.logger.Named("processStatusCallbackQuery").Error("Unknown status", zap.String("status", status))
+			return b.callbackFailed()
+		}
+		if user.Status == model.UserStatusBanned {
+			msg := tgbotapi.NewMessage(chatID, b.templator.UserAlreadyBanned(user))
+			msg.ParseMode = tgbotapi.ModeHTML
+			b.send(msg)
+			return callbackAnswer{}
+		}
+		// The status is replaced only if it's still the one shown, so a ban meanwhile is kept
+		result, err := b.db.ReplaceUserStatus(b.ctx, user.ID, user.Status, status)
+		if err != nil {
+			b.logger.Named("processStatusCallbackQuery").Error("Error while setting status", zap.Error(err))
+			return b.callbackFailed()
+		}
+		if result.RowsAffected == 0 {
+			b.logger.Named("processStatusCallbackQuery").Info("User status changed meanwhile", zap.Int64("telegram_id", telegramID), zap.String("from", user.Status), zap.String("to", status))
+			return callbackAnswer{text: b.templator.UserStatusChangedMeanwhile(), alert: true}
+		}
+		b.logger.Named("processStatusCallbackQuery").Info("User status changed", zap.Int64("telegram_id", telegramID), zap.String("from", user.Status), zap.String("to", status))
+		if status == model.UserStatusRejected {
+			b.revokeUserInviteLinks(telegramID)
+		}
+		forms, err := b.db.GetAllUserForms(b.ctx, telegramID)
+		if err != nil {
+			b.logger.Named("processStatusCallbackQuery").Error("Error while getting forms", zap.Error(err))
+			return b.callbackFailed()
+		}
+		b.send(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, b.userCardKeyboard(user, forms)))
+		msg := tgbotapi.NewMessage(chatID, b.templator.UserStatusChanged(user, status))
+		msg.ParseMode = tgbotapi.ModeHTML
+		b.send(msg)
+		return b.callbackDone()
+	}
+	return callbackAnswer{}
+}
+
+func isWhoisStatus(status string) bool {
+	for _, s := range whoisStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// resendFormReview sends the review message of the form to the reviewer's chat again.
+func (b *botManager) resendFormReview(chatID int64, formID uint) callbackAnswer {
+	form, err := b.db.GetFormByID(b.ctx, formID)
+	if err != nil {
+		b.logger.Named("resendFormReview").Error("Error while getting form", zap.Error(err))
+		return b.callbackFailed()
+	}
+	if form.Status != model.FormStatusNew {
+		return callbackAnswer{text: b.templator.FormAlreadyReviewed(), alert: true}
+	}
+	user, err := b.db.GetUserByTelegramID(b.ctx, form.UserTelegramId)
+	if err != nil {
+		b.logger.Named("resendFormReview").Error("Error while getting user", zap.Error(err))
+		return b.callbackFailed()
+	}
+	reviewMessage := tgbotapi.NewMessage(chatID, b.templator.NewFormReview(user, form))
+	reviewMessage.ParseMode = tgbotapi.ModeHTML
+	reviewMessage.ReplyMarkup = FormReviewKeyboard(b.callbacks, form.ID)
+	b.send(reviewMessage)
+	return callbackAnswer{}
+}
@@ -0,0 +1,99 @@
+package telegram
+
+import (
+	mock_database "beneburg/pkg/database/mocks"
+	"beneburg/pkg/database/model"
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gen"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func Test_processWhoisCommand(t *testing.T) {
+	found := &model.User{TelegramID: 10, FirstName: "Иван"}
+	other := &model.User{TelegramID: 20, FirstName: "Иван"}
+	whois := func(b *botManager, ref string) {
+		b.processWhoisCommand(&tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 1}}, commandArgs{"@username|id|имя": ref})
+	}
+	expectCard := func(dbMock *mock_database.MockDatabase, user *model.User) {
+		dbMock.EXPECT().GetAllUserForms(gomock.Any(), user.TelegramID).Return(nil, nil)
+		dbMock.EXPECT().GetMembershipPollsByUser(gomock.Any(), user.TelegramID).Return(nil, nil)
+		dbMock.EXPECT().GetUserRole(gomock.Any(), user.TelegramID).Return(model.RoleMember, nil)
+	}
+	sentMessage := func(t *testing.T, dbMock *mock_database.MockDatabase) *tgbotapi.MessageConfig {
+		msg := &tgbotapi.MessageConfig{}
+		dbMock.EXPECT().CreateOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, message *model.OutboxMessage) (*model.OutboxMessage, error) {
+			decoded, err := decodeOutboxMessage(message)
+			assert.NoError(t, err)
+			*msg = decoded.(tgbotapi.MessageConfig)
+			return message, nil
+		})
+		return msg
+	}
+
+	t.Run("Exact username doesn't search", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetUserByUsername(gomock.Any(), "ivan").Return(found, nil)
+		expectCard(dbMock, found)
+		msg := sentMessage(t, dbMock)
+		whois(b, "@ivan")
+		assert.Contains(t, msg.Text, "Иван")
+	})
+	t.Run("Unknown username is searched without @", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetUserByUsername(gomock.Any(), "ivan").Return(nil, gorm.ErrRecordNotFound)
+		dbMock.EXPECT().SearchUsers(gomock.Any(), "ivan", whoisSearchLimit).Return([]*model.User{found}, nil)
+		expectCard(dbMock, found)
+		msg := sentMessage(t, dbMock)
+		whois(b, "@ivan")
+		assert.Contains(t, msg.Text, "Иван")
+	})
+	t.Run("Name is searched", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().SearchUsers(gomock.Any(), "Иван", whoisSearchLimit).Return([]*model.User{found, other}, nil)
+		msg := sentMessage(t, dbMock)
+		whois(b, "Иван")
+		assert.Equal(t, b.templator.UsersFound(2), msg.Text)
+	})
+	t.Run("Nobody found", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetUserByTelegramID(gomock.Any(), int64(30)).Return(nil, gorm.ErrRecordNotFound)
+		dbMock.EXPECT().SearchUsers(gomock.Any(), "30", whoisSearchLimit).Return(nil, nil)
+		msg := sentMessage(t, dbMock)
+		whois(b, "30")
+		assert.Equal(t, b.templator.UserNotFound(), msg.Text)
+	})
+}
+
+func Test_processStatusCallbackQuery(t *testing.T) {
+	user := &model.User{Model: gorm.Model{ID: 5}, TelegramID: 10, Status: model.UserStatusActive}
+
+	t.Run("Status changed meanwhile is kept", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		dbMock.EXPECT().GetUserByTelegramID(gomock.Any(), int64(10)).Return(user, nil)
+		dbMock.EXPECT().ReplaceUserStatus(gomock.Any(), uint(5), model.UserStatusActive, model.UserStatusRejected).Return(&gen.ResultInfo{}, nil)
+		got := b.processStatusCallbackQuery(1, 2, 10, "set", model.UserStatusRejected)
+		assert.Equal(t, b.templator.UserStatusChangedMeanwhile(), got.text)
+		assert.True(t, got.alert)
+	})
+	t.Run("Rejected user loses invite links", func(t *testing.T) {
+		b, dbMock := newTestBot(t)
+		link := &model.InviteLink{Model: gorm.Model{ID: 7}, UserTelegramId: 10, Link: "https://t.me/+link"}
+		dbMock.EXPECT().GetUserByTelegramID(gomock.Any(), int64(10)).Return(user, nil)
+		dbMock.EXPECT().ReplaceUserStatus(gomock.Any(), uint(5), model.UserStatusActive, model.UserStatusRejected).Return(&gen.ResultInfo{RowsAffected: 1}, nil)
+		dbMock.EXPECT().GetActiveInviteLinksByUser(gomock.Any(), int64(10)).Return([]*model.InviteLink{link}, nil)
+		dbMock.EXPECT().RevokeInviteLink(gomock.Any(), uint(7)).Return(&gen.ResultInfo{RowsAffected: 1}, nil)
+		dbMock.EXPECT().GetAllUserForms(gomock.Any(), int64(10)).Return(nil, nil)
+		var types []string
+		dbMock.EXPECT().CreateOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, message *model.OutboxMessage) (*model.OutboxMessage, error) {
+			types = append(types, message.Type)
+			return message, nil
+		}).Times(3)
+		got := b.processStatusCallbackQuery(1, 2, 10, "set", model.UserStatusRejected)
+		assert.Equal(t, b.callbackDone(), got)
+		assert.Equal(t, "RevokeChatInviteLinkConfig", types[0])
+	})
+}
@@ -0,0 +1,142 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	updateWorkersCount   = 8
+	updateWorkerQueueLen = 20
+	updateHandlerTimeout = 30 * time.Second
+)
+
+var (
+	publishMetricsOnce sync.Once
+	updateMetrics      = new(expvar.Map).Init()
+)
+
+// updateDispatcher processes updates concurrently. Updates with the same key always go to
+// the same worker, so updates from one chat or user are processed in the order they came.
+type updateDispatcher struct {
+	queues  []chan tgbotapi.Update
+	handle  func(ctx context.Context, update tgbotapi.Update)
+	timeout time.Duration
+	wg      sync.WaitGroup
+	logger  *zap.Logger
+}
+
+func newUpdateDispatcher(workers int, queueLen int, timeout time.Duration, handle func(ctx context.Context, update tgbotapi.Update)) *updateDispatcher {
+	d := &updateDispatcher{
+		queues:  make([]chan tgbotapi.Update, workers),
+		handle:  handle,
+		timeout: timeout,
+	}
+	for i := range d.queues {
+		d.queues[i] = make(chan tgbotapi.Update, queueLen)
+	}
+	publishMetricsOnce.Do(func() {
+		expvar.Publish("telegram_updates", updateMetrics)
+	})
+	updateMetrics.Set("queue_depth", expvar.Func(func() any {
+		depth := map[string]int{}
+		for i, queue := range d.queues {
+			depth[strconv.Itoa(i)] = len(queue)
+		}
+		return depth
+	}))
+	return d
+}
+
+// start runs the workers. Handler contexts are derived from ctx.
+func (d *updateDispatcher) start(ctx context.Context, logger *zap.Logger) {
+	d.logger = logger
+	for i, queue := range d.queues {
+		d.wg.Add(1)
+		go d.work(ctx, i, queue)
+	}
+}
+
+// stop waits for the workers to process the queued updates. Nothing may be dispatched after it.
+func (d *updateDispatcher) stop() {
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.wg.Wait()
+}
+
+// dispatch blocks while the queue of the update's worker is full.
+func (d *updateDispatcher) dispatch(ctx context.Context, update tgbotapi.Update) {
+	queue := d.queues[d.workerIndex(update)]
+	select {
+	case queue <- update:
+	case <-ctx.Done():
+	}
+}
+
+func (d *updateDispatcher) workerIndex(update tgbotapi.Update) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(strconv.FormatInt(updateOrderingKey(update), 10)))
+	return int(hash.Sum32() % uint32(len(d.queues)))
+}
+
+func (d *updateDispatcher) work(ctx context.Context, index int, queue chan tgbotapi.Update) {
+	defer d.wg.Done()
+	for update := range queue {
+		d.process(ctx, index, update)
+	}
+}
+
+func (d *updateDispatcher) process(ctx context.Context, index int, update tgbotapi.Update) {
+	handlerCtx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			updateMetrics.Add("panics", 1)
+			d.logger.Named("updateDispatcher").Error("Panic while processing update", zap.Any("panic", r), zap.Int("update_id", update.UpdateID))
+		}
+	}()
+	started := time.Now()
+	d.handle(handlerCtx, update)
+	updateMetrics.Add("processed", 1)
+	if err := handlerCtx.Err(); errors.Is(err, context.DeadlineExceeded) {
+		updateMetrics.Add("timeouts", 1)
+		d.logger.Named("updateDispatcher").Warn("Update processing timed out", zap.Int("update_id", update.UpdateID), zap.Int("worker", index), zap.Duration("duration", time.Since(started)))
+	}
+}
+
+// updateOrderingKey returns the chat of the update, or its sender if the update has no chat.
+// Join requests and member changes are keyed by the member instead of the group, the ID of
+// a private chat is the user's ID, so they are ordered with the member's messages to the bot.
+// Other updates of one user in different chats, like a group message and a private one, may
+// be processed concurrently, so the status is written only from join requests and member changes.
+func updateOrderingKey(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil && update.Message.Chat != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil && update.CallbackQuery.Message.Chat != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.From != nil:
+		return update.CallbackQuery.From.ID
+	case update.ChatJoinRequest != nil:
+		return update.ChatJoinRequest.From.ID
+	case update.ChatMember != nil && update.ChatMember.NewChatMember.User != nil:
+		return update.ChatMember.NewChatMember.User.ID
+	case update.ChatMember != nil:
+		return update.ChatMember.Chat.ID
+	case update.MyChatMember != nil:
+		return update.MyChatMember.Chat.ID
+	case update.PollAnswer != nil:
+		return update.PollAnswer.User.ID
+	case update.InlineQuery != nil && update.InlineQuery.From != nil:
+		return update.InlineQuery.From.ID
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+package telegram
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_updateDispatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	processed := map[int64][]int{}
+	var wg sync.WaitGroup
+	d := newUpdateDispatcher(4, 2, time.Second, func(ctx context.Context, update tgbotapi.Update) {
+		defer wg.Done()
+		mu.Lock()
+		defer mu.Unlock()
+		chatID := update.Message.Chat.ID
+		processed[chatID] = append(processed[chatID], update.UpdateID)
+	})
+	d.start(ctx, zap.NewNop())
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		d.dispatch(ctx, tgbotapi.Update{
+			UpdateID: i,
+			Message:  &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: int64(i % 5)}},
+		})
+	}
+	wg.Wait()
+	d.stop()
+
+	for chatID, updateIDs := range processed {
+		assert.Len(t, updateIDs, 20, "chat %d", chatID)
+		assert.IsIncreasing(t, updateIDs, "chat %d", chatID)
+	}
+}
+
+func Test_updateOrderingKey(t *testing.T) {
+	member := &tgbotapi.User{ID: 10}
+	group := tgbotapi.Chat{ID: -100}
+	private := tgbotapi.Update{Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 10}, From: member}}
+
+	t.Run("Member updates are ordered with the private chat", func(t *testing.T) {
+		joinRequest := tgbotapi.Update{ChatJoinRequest: &tgbotapi.ChatJoinRequest{Chat: group, From: *member}}
+		chatMember := tgbotapi.Update{ChatMember: &tgbotapi.ChatMemberUpdated{Chat: group, NewChatMember: tgbotapi.ChatMember{User: member}}}
+		assert.Equal(t, updateOrderingKey(private), updateOrderingKey(joinRequest))
+		assert.Equal(t, updateOrderingKey(private), updateOrderingKey(chatMember))
+	})
+	t.Run("Group messages are ordered by the group", func(t *testing.T) {
+		message := tgbotapi.Update{Message: &tgbotapi.Message{Chat: &group, From: member}}
+		assert.Equal(t, group.ID, updateOrderingKey(message))
+	})
+}
@@ -1,13 +1,11 @@
 package views
 
 import (
+	"beneburg/pkg/callback"
 	"beneburg/pkg/database"
 	"beneburg/pkg/database/model"
 	"beneburg/pkg/telegram"
-	"beneburg/pkg/utils"
-	"fmt"
 	"github.com/gin-gonic/gin"
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"go.uber.org/zap"
 	"net/http"
 	"strconv"
@@ -18,6 +16,9 @@ type Views interface {
 	RegisterRoutes(router gin.IRouter)
 	RegisterLogin(router gin.IRouter)
 	RegisterProfile(router gin.IRouter)
+	RegisterAdmin(router gin.IRouter)
+	// RegisterBan registers the page banned users are redirected to, it must be outside TokenAuth.
+	RegisterBan(router gin.IRouter)
 }
 
 var _ Views = &views{}
@@ -27,7 +28,7 @@ type views struct {
 	logger          *zap.Logger
 	sendToBot       telegram.TelegramBotSendFunc
 	templator       telegram.Templator
-	adminTelegramID int64
+	callbacks       *callback.Codec
 	groupTelegramID int64
 }
 
@@ -41,18 +42,26 @@ func (v views) RegisterProfile(router gin.IRouter) {
 	router.POST("/form", v.profileForm)
 }
 
+func (v views) RegisterAdmin(router gin.IRouter) {
+	router.GET("/", v.admin)
+}
+
 func (v views) RegisterLogin(router gin.IRouter) {
 	router.GET("/", v.login)
 	router.GET("/:token", v.login)
 }
 
-func NewViews(db database.Database, logger *zap.Logger, sendFunc telegram.TelegramBotSendFunc, adminTelegramID int64, groupTelegramID int64, domain string) Views {
+func (v views) RegisterBan(router gin.IRouter) {
+	router.GET("/", v.ban)
+}
+
+func NewViews(db database.Database, logger *zap.Logger, sendFunc telegram.TelegramBotSendFunc, callbacks *callback.Codec, groupTelegramID int64, domain string) Views {
 	return &views{
 		db:              db,
 		logger:          logger,
 		sendToBot:       sendFunc,
 		templator:       telegram.NewTemplator(domain),
-		adminTelegramID: adminTelegramID,
+		callbacks:       callbacks,
 		groupTelegramID: groupTelegramID,
 	}
 }
@@ -79,6 +88,15 @@ func (v views) login(g *gin.Context) {
 	g.Redirect(302, "/")
 }
 
+func (v views) ban(g *gin.Context) {
+	// The token is deleted on ban, so the page doesn't know who the user is
+	g.SetCookie("token", "", -1, "/", "", false, true)
+	g.HTML(http.StatusForbidden, "ban.gohtml", gin.H{
+		"title": "Доступ закрыт",
+		"page":  "ban",
+	})
+}
+
 func (v views) profile(g *gin.Context) {
 	var no_forms = false
 	user := g.MustGet("currentUser").(*model.User)
@@ -106,15 +124,14 @@ func (v views) profileForm(g *gin.Context) {
 	if ok {
 		form.Name = nameFormValue
 	}
-	ageFormValue, ok := g.GetPostForm("age")
+	birthdateFormValue, ok := g.GetPostForm("birthdate")
 	if ok {
-		convertedAge, err := strconv.Atoi(ageFormValue)
+		err := telegram.SetFormBirthdate(form, birthdateFormValue)
 		if err != nil {
-			v.logger.Named("profileForm").Error("Error converting age", zap.Error(err))
+			v.logger.Named("profileForm").Info("Invalid birthdate", zap.Error(err))
 			g.Redirect(http.StatusFound, "/profile")
 			return
 		}
-		form.Age = utils.GetAddress(int32(convertedAge))
 	}
 	genderFormValue, ok := g.GetPostForm("gender")
 	if ok {
@@ -144,20 +161,10 @@ func (v views) profileForm(g *gin.Context) {
 	if ok && len(strings.TrimSpace(contactsFormValue)) > 0 {
 		form.Contacts = &contactsFormValue
 	}
-	_, err := v.db.CreateForm(g, form)
+	err := telegram.SubmitForm(g, v.db, v.sendToBot, v.templator, v.callbacks, user, form)
 	if err != nil {
-		v.logger.Named("profileForm").Error("Error creating form", zap.Error(err))
+		v.logger.Named("profileForm").Error("Error submitting form", zap.Error(err))
 	}
-	message := tgbotapi.NewMessage(user.TelegramID, v.templator.FormReceived())
-	v.sendToBot(message)
-
-	adminMessageText := fmt.Sprintf("Новая анкета:\n\n%s\n\n%s", v.templator.FormInfo(form), v.templator.UserIdWithHref(user))
-	adminMessage := tgbotapi.NewMessage(v.adminTelegramID, adminMessageText)
-	adminMessage.ParseMode = "HTML"
-	acceptionButton := tgbotapi.NewInlineKeyboardButtonData("Принять", fmt.Sprintf("admin:form:accept:%d", form.ID))
-	rejectionButton := tgbotapi.NewInlineKeyboardButtonData("Отклонить", fmt.Sprintf("admin:form:reject:%d", form.ID))
-	adminMessage.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(acceptionButton, rejectionButton))
-	v.sendToBot(adminMessage)
 
 	g.Redirect(http.StatusFound, "/profile")
 }
@@ -177,3 +184,19 @@ func (v views) user(g *gin.Context) {
 		"userTelegramId": userTelegramIdStr,
 	})
 }
+
+func (v views) admin(g *gin.Context) {
+	forms, err := v.db.GetFormsByStatus(g, model.FormStatusNew)
+	if err != nil {
+		v.logger.Named("admin").Error("Error getting forms", zap.Error(err))
+	}
+	if forms == nil {
+		forms = []*model.Form{}
+	}
+	g.HTML(200, "admin.gohtml", gin.H{
+		"title": "Анкеты на проверке",
+		"page":  "admin",
+		"role":  g.GetString("currentRole"),
+		"forms": forms,
+	})
+}